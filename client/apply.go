@@ -0,0 +1,403 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"app-pointment/client/sdk"
+)
+
+/** Difference between a due time of the file and of the backend which is still considered equal */
+const applyTolerance = time.Minute
+
+/** Declarative reminders file, a plain list of reminders is accepted too */
+type remindersFile struct {
+	Mode      string         `json:"mode"`
+	Reminders []reminderSpec `json:"reminders"`
+}
+
+/**
+ * Desired state of a reminder. Reminders without id are matched by owner and title,
+ * or by template and variables when they have no title. The relative in is only used
+ * when the reminder is created so applying the file again keeps its due time.
+ */
+type reminderSpec struct {
+	ID               int                 `json:"id"`
+	Owner            yamlText            `json:"owner"`
+	Title            yamlText            `json:"title"`
+	Message          yamlText            `json:"message"`
+	Template         yamlText            `json:"template"`
+	Variables        map[string]yamlText `json:"variables"`
+	Channels         []yamlText          `json:"channels"`
+	EscalationPolicy yamlText            `json:"escalation_policy"`
+	IgnoreQuietHours *bool               `json:"ignore_quiet_hours"`
+	At               yamlText            `json:"at"`
+	In               yamlText            `json:"in"`
+}
+
+/** Change of an apply run, Status is planned, ok, failed or skipped */
+type applyChange struct {
+	Op      string   `json:"op"`
+	ID      int      `json:"id,omitempty"`
+	Title   string   `json:"title"`
+	Changes []string `json:"changes,omitempty"`
+	Status  string   `json:"status"`
+	Error   string   `json:"error,omitempty"`
+}
+
+/** Reconcile the reminders of a declarative file with the backend */
+func (s Switch) apply(f *flag.FlagSet) func() error {
+	path := f.String("file", "", "Reminders file in YAML or JSON, - reads stdin.")
+	f.StringVar(path, "f", "", "Shorthand for --file.")
+	mode := f.String("mode", "", "atomic applies every change or none, best_effort applies the valid ones (default: the file mode or atomic).")
+	prune := f.Bool("prune", false, "Delete the reminders visible to the API key which the file does not list.")
+	dryRun := f.Bool("dry-run", false, "Print the changes without applying them.")
+	return func() error {
+		if *path == "" {
+			return usageErrorf("missing reminders file, use -f")
+		}
+		file, err := readRemindersFile(*path)
+		if err != nil {
+			return err
+		}
+		batchMode := *mode
+		if batchMode == "" {
+			batchMode = file.Mode
+		}
+		if batchMode == "" {
+			batchMode = sdk.BatchAtomic
+		}
+		if !contains(sdk.BatchModes, batchMode) {
+			return usageErrorf("invalid mode %q, expected one of: %s", batchMode, strings.Join(sdk.BatchModes, ", "))
+		}
+
+		ctx := context.Background()
+		current, err := s.client.ListAll(ctx)
+		if err != nil {
+			return wrapError("Could not fetch reminders.", err)
+		}
+		ops, changes, err := s.plan(file.Reminders, current, *prune, time.Now())
+		if err != nil {
+			return err
+		}
+		if len(ops) == 0 {
+			s.output.Message("Nothing to change, %d reminder(s) are up to date.", len(file.Reminders))
+			return nil
+		}
+		if *dryRun {
+			for i := range changes {
+				changes[i].Status = "planned"
+			}
+			s.output.Message("Dry run, %d change(s) planned.", len(ops))
+			return s.output.Print(changes)
+		}
+		if len(ops) > sdk.MaxBatchOperations && batchMode == sdk.BatchAtomic {
+			return fmt.Errorf("the file needs %d changes, an atomic apply is limited to %d, use --mode=%s", len(ops), sdk.MaxBatchOperations, sdk.BatchBestEffort)
+		}
+
+		var succeeded, failed int
+		for start := 0; start < len(ops); start += sdk.MaxBatchOperations {
+			end := start + sdk.MaxBatchOperations
+			if end > len(ops) {
+				end = len(ops)
+			}
+			res, err := s.client.Batch(ctx, sdk.BatchRequest{Mode: batchMode, Operations: ops[start:end]})
+			if err != nil {
+				return wrapError("Could not apply reminders.", err)
+			}
+			for _, result := range res.Results {
+				change := &changes[start+result.Index]
+				change.Status = result.Status
+				if result.ID != 0 {
+					change.ID = result.ID
+				}
+				if result.Error != nil {
+					change.Error = result.Error.Message
+				}
+			}
+			succeeded += res.Succeeded
+			failed += res.Failed
+		}
+		if err := s.output.Print(changes); err != nil {
+			return err
+		}
+		if failed > 0 && batchMode == sdk.BatchAtomic {
+			return fmt.Errorf("%d of %d change(s) are invalid, nothing was applied", failed, len(ops))
+		}
+		if failed > 0 {
+			return fmt.Errorf("%d of %d change(s) failed", failed, len(ops))
+		}
+		s.output.Message("Applied %d change(s).", succeeded)
+		return nil
+	}
+}
+
+/** Reads a reminders file, the path - reads stdin */
+func readRemindersFile(path string) (remindersFile, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		data, err = ioutil.ReadFile(path)
+	}
+	if err != nil {
+		return remindersFile{}, wrapError("Could not read reminders file.", err)
+	}
+	var raw json.RawMessage
+	if err := decodeYAML(data, &raw); err != nil {
+		return remindersFile{}, fmt.Errorf("invalid reminders file %s: %v", path, err)
+	}
+	if raw = bytes.TrimSpace(raw); len(raw) > 0 && raw[0] == '[' {
+		raw = append(append([]byte(`{"reminders":`), raw...), '}')
+	}
+	var file remindersFile
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&file); err != nil {
+		return remindersFile{}, fmt.Errorf("invalid reminders file %s: %v", path, strings.TrimPrefix(err.Error(), "json: "))
+	}
+	return file, nil
+}
+
+/** Operations turning the current reminders into the ones of the file */
+func (s Switch) plan(specs []reminderSpec, current []sdk.Reminder, prune bool, now time.Time) ([]sdk.BatchOperation, []applyChange, error) {
+	byID := make(map[int]sdk.Reminder, len(current))
+	for _, r := range current {
+		byID[r.ID] = r
+	}
+	claimed := map[int]bool{}
+	matches := make([]*sdk.Reminder, len(specs))
+	// Explicit ids go first so matching by title cannot take them.
+	for i, spec := range specs {
+		if spec.ID == 0 {
+			continue
+		}
+		r, ok := byID[spec.ID]
+		if !ok {
+			return nil, nil, fmt.Errorf("reminder %d (item %d) does not exist", spec.ID, i+1)
+		}
+		if claimed[spec.ID] {
+			return nil, nil, fmt.Errorf("reminder %d (item %d) is listed more than once", spec.ID, i+1)
+		}
+		claimed[spec.ID] = true
+		matches[i] = &r
+	}
+	for i, spec := range specs {
+		if spec.ID == 0 {
+			matches[i] = spec.match(current, claimed)
+		}
+	}
+
+	var ops []sdk.BatchOperation
+	var changes []applyChange
+	for i, spec := range specs {
+		var op sdk.BatchOperation
+		var fields []string
+		var err error
+		if matches[i] == nil {
+			op, err = spec.create(now, s.output.location)
+		} else {
+			op, fields, err = spec.edit(*matches[i], now, s.output.location)
+			if err == nil && len(fields) == 0 {
+				continue
+			}
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("item %d (%s): %v", i+1, spec.label(), err)
+		}
+		ops = append(ops, op)
+		changes = append(changes, applyChange{Op: op.Op, ID: op.ID, Title: spec.label(), Changes: fields})
+	}
+	if prune {
+		for _, r := range current {
+			if claimed[r.ID] {
+				continue
+			}
+			ops = append(ops, sdk.BatchOperation{Op: sdk.BatchDelete, ID: r.ID})
+			changes = append(changes, applyChange{Op: sdk.BatchDelete, ID: r.ID, Title: reminderLabel(r)})
+		}
+	}
+	return ops, changes, nil
+}
+
+/** Best unclaimed reminder of the spec, pending ones first then the most recent */
+func (spec reminderSpec) match(current []sdk.Reminder, claimed map[int]bool) *sdk.Reminder {
+	var best *sdk.Reminder
+	for i := range current {
+		r := &current[i]
+		if claimed[r.ID] || (spec.Owner != "" && r.Owner != string(spec.Owner)) {
+			continue
+		}
+		switch {
+		case spec.Title != "" && r.Title != string(spec.Title):
+			continue
+		case spec.Title == "" && (spec.Template == "" || r.Title != "" || r.Template != string(spec.Template) || !sameVars(spec.Variables, r.Variables)):
+			continue
+		}
+		if best == nil || finished(*best) && !finished(*r) || finished(*best) == finished(*r) && r.ID > best.ID {
+			best = r
+		}
+	}
+	if best != nil {
+		claimed[best.ID] = true
+	}
+	return best
+}
+
+func (spec reminderSpec) create(now time.Time, loc *time.Location) (sdk.BatchOperation, error) {
+	if spec.Template == "" && (spec.Title == "" || spec.Message == "") {
+		return sdk.BatchOperation{}, fmt.Errorf("a reminder needs a title and a message or a template")
+	}
+	op := sdk.BatchOperation{
+		Op:               sdk.BatchCreate,
+		Owner:            string(spec.Owner),
+		Title:            string(spec.Title),
+		Message:          string(spec.Message),
+		Template:         string(spec.Template),
+		Variables:        spec.vars(),
+		Channels:         spec.channels(),
+		EscalationPolicy: string(spec.EscalationPolicy),
+		IgnoreQuietHours: spec.IgnoreQuietHours,
+	}
+	var t time.Time
+	var err error
+	switch {
+	case spec.At != "" && spec.In != "":
+		return sdk.BatchOperation{}, fmt.Errorf("at and in cannot be used together")
+	case spec.At != "":
+		t, err = ParseTime(string(spec.At), now, loc)
+	case spec.In != "":
+		t, err = ParseTime("in "+strings.TrimPrefix(strings.TrimSpace(string(spec.In)), "in "), now, loc)
+	default:
+		return sdk.BatchOperation{}, fmt.Errorf("a new reminder needs at or in")
+	}
+	if err != nil {
+		return sdk.BatchOperation{}, err
+	}
+	op.Duration = t.Sub(now)
+	return op, nil
+}
+
+/** Edit of the fields of the reminder differing from the spec, only the fields set in the spec are compared */
+func (spec reminderSpec) edit(r sdk.Reminder, now time.Time, loc *time.Location) (sdk.BatchOperation, []string, error) {
+	op := sdk.BatchOperation{Op: sdk.BatchEdit, ID: r.ID}
+	var fields []string
+	if spec.Owner != "" && string(spec.Owner) != r.Owner {
+		return op, nil, fmt.Errorf("the owner of reminder %d cannot be changed", r.ID)
+	}
+	if spec.Title != "" && string(spec.Title) != r.Title {
+		op.Title = string(spec.Title)
+		fields = append(fields, "title")
+	}
+	if spec.Message != "" && string(spec.Message) != r.Message {
+		op.Message = string(spec.Message)
+		fields = append(fields, "message")
+	}
+	if spec.Template != "" && string(spec.Template) != r.Template {
+		op.Template = string(spec.Template)
+		fields = append(fields, "template")
+	}
+	if spec.Variables != nil && !sameVars(spec.Variables, r.Variables) {
+		op.Variables = spec.vars()
+		fields = append(fields, "variables")
+	}
+	if channels := spec.channels(); len(channels) > 0 && !sameSet(channels, r.Channels) {
+		op.Channels = channels
+		fields = append(fields, "channels")
+	}
+	if spec.EscalationPolicy != "" && string(spec.EscalationPolicy) != r.EscalationPolicy {
+		op.EscalationPolicy = string(spec.EscalationPolicy)
+		fields = append(fields, "escalation_policy")
+	}
+	if spec.IgnoreQuietHours != nil && *spec.IgnoreQuietHours != r.IgnoreQuietHours {
+		op.IgnoreQuietHours = spec.IgnoreQuietHours
+		fields = append(fields, "ignore_quiet_hours")
+	}
+	due := r.ModifiedAt.Add(r.Duration)
+	if spec.At != "" {
+		t, err := parseTime(strings.TrimSpace(string(spec.At)), now.In(loc), loc)
+		if err != nil {
+			return op, nil, fmt.Errorf("invalid time %q: %v", spec.At, err)
+		}
+		if diff := t.Sub(due); diff > applyTolerance || diff < -applyTolerance {
+			if !t.After(now) {
+				return op, nil, fmt.Errorf("time %q resolves to %s which is in the past", spec.At, t.Format("Mon Jan 02 15:04 MST"))
+			}
+			op.Duration = t.Sub(now)
+			fields = append(fields, "at")
+		}
+	}
+	// An edit restarts the reminder from now, keep its due time.
+	if len(fields) > 0 && op.Duration == 0 && !finished(r) && due.After(now) {
+		op.Duration = due.Sub(now)
+	}
+	return op, fields, nil
+}
+
+/** Title of the spec or its template */
+func (spec reminderSpec) label() string {
+	return reminderLabel(sdk.Reminder{Title: string(spec.Title), Template: string(spec.Template)})
+}
+
+func reminderLabel(r sdk.Reminder) string {
+	if r.Title == "" && r.Template != "" {
+		return "(template " + r.Template + ")"
+	}
+	return r.Title
+}
+
+func (spec reminderSpec) vars() map[string]string {
+	if spec.Variables == nil {
+		return nil
+	}
+	res := make(map[string]string, len(spec.Variables))
+	for k, v := range spec.Variables {
+		res[k] = string(v)
+	}
+	return res
+}
+
+func (spec reminderSpec) channels() []string {
+	var res []string
+	for _, c := range spec.Channels {
+		res = append(res, string(c))
+	}
+	return res
+}
+
+func sameVars(spec map[string]yamlText, vars map[string]string) bool {
+	if len(spec) != len(vars) {
+		return false
+	}
+	for k, v := range spec {
+		if current, ok := vars[k]; !ok || current != string(v) {
+			return false
+		}
+	}
+	return true
+}
+
+func sameSet(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	a = append([]string(nil), a...)
+	b = append([]string(nil), b...)
+	sort.Strings(a)
+	sort.Strings(b)
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,123 @@
+package client
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"app-pointment/client/sdk"
+)
+
+func TestApplyPlan(t *testing.T) {
+	// a Monday afternoon
+	now := time.Date(2026, 10, 19, 15, 0, 0, 0, time.UTC)
+	current := []sdk.Reminder{
+		{ID: 1, Title: "Standup", Message: "m", Status: sdk.StatusPending, ModifiedAt: now, Duration: time.Hour},
+		{ID: 2, Title: "Dentist", Message: "old", Status: sdk.StatusPending, ModifiedAt: now, Duration: 18 * time.Hour},
+		{ID: 3, Title: "Gym", Message: "lift", Status: sdk.StatusCompleted, ModifiedAt: now.Add(-time.Hour), Duration: -time.Hour},
+		{ID: 4, Title: "Gym", Message: "lift", Status: sdk.StatusPending, ModifiedAt: now, Duration: 2 * time.Hour},
+		{ID: 5, Owner: "bob", Title: "Standup", Message: "m", Status: sdk.StatusPending, ModifiedAt: now, Duration: time.Hour},
+	}
+	tests := []struct {
+		name  string
+		specs []reminderSpec
+		prune bool
+		want  []string
+	}{
+		{"unchanged", []reminderSpec{{Title: "Standup", Message: "m", In: "1h"}}, false, nil},
+		{"create", []reminderSpec{{Title: "Review", Message: "m", At: "tomorrow 18:00"}}, false, []string{"create 0  27h0m0s"}},
+		{"create in", []reminderSpec{{Title: "Review", Message: "m", In: "2 hours"}}, false, []string{"create 0  2h0m0s"}},
+		{"edit keeps the due time", []reminderSpec{{Title: "Dentist", Message: "new"}}, false, []string{"edit 2 message 18h0m0s"}},
+		{"edit the due time of the most recent match", []reminderSpec{{Title: "Standup", At: "17:00"}}, false, []string{"edit 5 at 2h0m0s"}},
+		{"due time within tolerance", []reminderSpec{{Title: "Standup", At: "16:00"}}, false, nil},
+		{"explicit id", []reminderSpec{{ID: 2, Title: "Dentist appointment"}}, false, []string{"edit 2 title 18h0m0s"}},
+		{"pending match first", []reminderSpec{{Title: "Gym", Message: "cardio"}}, false, []string{"edit 4 message 2h0m0s"}},
+		{"owner match", []reminderSpec{{Owner: "bob", Title: "Standup", Message: "daily"}}, false, []string{"edit 5 message 1h0m0s"}},
+		{"prune", []reminderSpec{{Title: "Standup"}, {Title: "Gym"}}, true, []string{"delete 1  0s", "delete 2  0s", "delete 3  0s"}},
+	}
+	s := Switch{output: Printer{location: time.UTC}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ops, changes, err := s.plan(tt.specs, current, tt.prune, now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for i, op := range ops {
+				got = append(got, fmt.Sprintf("%s %d %s %v", op.Op, op.ID, strings.Join(changes[i].Changes, ","), op.Duration))
+			}
+			if strings.Join(got, "; ") != strings.Join(tt.want, "; ") {
+				t.Errorf("planned %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyPlanErrors(t *testing.T) {
+	now := time.Date(2026, 10, 19, 15, 0, 0, 0, time.UTC)
+	current := []sdk.Reminder{{ID: 1, Owner: "alice", Title: "Standup", Message: "m", ModifiedAt: now, Duration: time.Hour}}
+	tests := []struct {
+		name  string
+		specs []reminderSpec
+		err   string
+	}{
+		{"unknown id", []reminderSpec{{ID: 9, Title: "x"}}, "does not exist"},
+		{"listed twice", []reminderSpec{{ID: 1}, {ID: 1}}, "more than once"},
+		{"create without time", []reminderSpec{{Title: "Review", Message: "m"}}, "needs at or in"},
+		{"create without message", []reminderSpec{{Title: "Review", In: "1h"}}, "title and a message"},
+		{"at and in", []reminderSpec{{Title: "Review", Message: "m", At: "17:00", In: "1h"}}, "cannot be used together"},
+		{"in the past", []reminderSpec{{Title: "Standup", At: "today 9:00"}}, "in the past"},
+		{"owner change", []reminderSpec{{ID: 1, Owner: "bob"}}, "cannot be changed"},
+	}
+	s := Switch{output: Printer{location: time.UTC}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := s.plan(tt.specs, current, false, now)
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("got error %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestReadRemindersFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "apply")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	tests := []struct {
+		name    string
+		data    string
+		mode    string
+		titles  string
+		wantErr bool
+	}{
+		{"document", "mode: atomic\nreminders:\n  - title: Standup\n    message: daily\n    in: 1h\n", "atomic", "Standup", false},
+		{"plain list", "- title: Standup\n  at: tomorrow 9am\n- title: Gym\n", "", "Standup,Gym", false},
+		{"unknown field", "- title: Standup\n  when: 9am\n", "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, "reminders.yaml")
+			if err := ioutil.WriteFile(path, []byte(tt.data), 0600); err != nil {
+				t.Fatal(err)
+			}
+			file, err := readRemindersFile(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+			var titles []string
+			for _, spec := range file.Reminders {
+				titles = append(titles, string(spec.Title))
+			}
+			if file.Mode != tt.mode || strings.Join(titles, ",") != tt.titles {
+				t.Errorf("read mode %q and %v, want %q and %s", file.Mode, titles, tt.mode, tt.titles)
+			}
+		})
+	}
+}
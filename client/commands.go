@@ -0,0 +1,324 @@
+package client
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+/** Exit codes of the CLI */
+const (
+	ExitOK    = 0
+	ExitError = 1
+	ExitUsage = 2
+)
+
+/** Invalid command line, the command was not run */
+type UsageError struct {
+	Message string
+}
+
+func (e UsageError) Error() string {
+	return e.Message
+}
+
+func usageErrorf(format string, args ...interface{}) error {
+	return UsageError{Message: fmt.Sprintf(format, args...)}
+}
+
+/** Exit code of an error returned by the Switch */
+func ExitCode(err error) int {
+	var usage UsageError
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.As(err, &usage):
+		return ExitUsage
+	}
+	return ExitError
+}
+
+/**
+ * CLI command, setup registers the command flags and returns the function running it.
+ * A command with subcommands only dispatches to them, exec receives the raw arguments
+ * of commands parsing them on their own.
+ */
+type command struct {
+	name        string
+	summary     string
+	usage       string
+	examples    []string
+	args        bool
+	hidden      bool
+	setup       func(f *flag.FlagSet) func() error
+	exec        func(args []string) error
+	complete    func() []candidate
+	subcommands []*command
+}
+
+/** Subcommand by name */
+func (c *command) find(name string) *command {
+	for _, sub := range c.subcommands {
+		if sub.name == name {
+			return sub
+		}
+	}
+	return nil
+}
+
+/** Subcommands shown in the help, sorted by name */
+func (c *command) visible() []*command {
+	var res []*command
+	for _, sub := range c.subcommands {
+		if !sub.hidden {
+			res = append(res, sub)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].name < res[j].name })
+	return res
+}
+
+/** Flag set of the command, nil when it has no flags */
+func (c *command) flags(name string) *flag.FlagSet {
+	if c.setup == nil {
+		return nil
+	}
+	f := flag.NewFlagSet(name, flag.ContinueOnError)
+	f.SetOutput(ioutil.Discard)
+	c.setup(f)
+	return f
+}
+
+/** Name of the program as invoked */
+func progName() string {
+	if len(os.Args) == 0 || os.Args[0] == "" {
+		return "client"
+	}
+	return filepath.Base(os.Args[0])
+}
+
+/** Full name of a command, e.g. "client keys create" */
+func commandName(path []*command) string {
+	names := make([]string, len(path))
+	for i, c := range path {
+		names[i] = c.name
+	}
+	return strings.Join(names, " ")
+}
+
+func isHelp(arg string) bool {
+	return arg == "-h" || arg == "-help" || arg == "--help"
+}
+
+/** Command tree of the CLI */
+func (s Switch) commands() *command {
+	root := &command{
+		name:    progName(),
+		summary: "Manage reminders of an app-pointment backend.",
+		subcommands: []*command{
+			{
+				name:     "config",
+				summary:  "Manage the client config file and its contexts",
+				examples: []string{"config set --context=work --backend=https://reminders.example.com", "config use work"},
+				subcommands: []*command{
+					{name: "set", summary: "Create or update a context, only the given flags are changed", usage: "[--context=<name>] [--backend=<url>] [--api-key=<key>] [--output=<format>] [--time-zone=<zone>]", setup: s.setContext,
+						examples: []string{"config set --context=staging --backend=https://staging.example.com --output=yaml"}},
+					{name: "use", summary: "Switch the current context", usage: "<context>", args: true, setup: s.useContext, complete: contextCandidates,
+						examples: []string{"config use staging"}},
+					{name: "view", summary: "Show the config file, API keys are masked", usage: "[--raw]", setup: s.viewConfig,
+						examples: []string{"config view", "--output=json config view --raw"}},
+				},
+			},
+			{name: "apply", summary: "Create, edit and delete reminders to match a declarative file in a single batch", setup: s.apply,
+				usage: "-f <file> [--mode=atomic|best_effort] [--prune] [--dry-run]",
+				examples: []string{
+					"apply -f reminders.yaml --dry-run",
+					"apply -f reminders.yaml --prune --mode=best_effort",
+					"--output=json apply -f - < reminders.json",
+				}},
+			{name: "create", summary: "Create a reminder", setup: s.create,
+				usage: "(--title=<title> --message=<message> | --template=<name> [--var=<name=value>...]) [--duration=<duration> | --at=<time>] [--channel=<name>...]",
+				examples: []string{
+					`create -t=Standup -m="Join the call" -d=10m`,
+					`create -t=Review -m="Weekly review" --at="next friday at 2:30pm" --channel=email`,
+					`create --template=standup --var=room=B12 --at="tomorrow 9am"`,
+				}},
+			{name: "dead-letter", summary: "List reminders which exhausted their retries", setup: s.deadLetter,
+				examples: []string{"dead-letter", "--output=ids dead-letter"}},
+			{name: "delete", summary: "Delete reminders", usage: "--id=<id>[,<id>...]", setup: s.delete,
+				examples: []string{"delete --id=1,2"}},
+			{name: "dismiss", summary: "Stop reminders without completing them", usage: "--id=<id>[,<id>...]", setup: s.dismiss,
+				examples: []string{"dismiss --id=3"}},
+			{name: "done", summary: "Mark reminders as completed", usage: "--id=<id>[,<id>...]", setup: s.done,
+				examples: []string{"done --id=3"}},
+			{name: "edit", summary: "Edit a reminder, only the given flags are changed", usage: "--id=<id> [--title=<title>] [--message=<message>] [--duration=<duration> | --at=<time>] [--channel=<name>...]", setup: s.edit,
+				examples: []string{`edit --id=3 --title="Standup moved" --at="14:30"`}},
+			{name: "health", summary: "Check the health of the backend", usage: "[--host=<url>]", setup: s.health,
+				examples: []string{"health", "health --host=http://staging:8008"}},
+			{name: "history", summary: "Show every delivery attempt of a reminder", usage: "--id=<id>", setup: s.history,
+				examples: []string{"history --id=3"}},
+			{
+				name:    "keys",
+				summary: "Manage API keys stored in the keys file",
+				subcommands: []*command{
+					{name: "create", summary: "Create an API key and print it once", usage: "--name=<name> --scope=<scope>... [--user=<user>] [--file=<path>]", setup: s.createKey,
+						examples: []string{"keys create --name=ci --scope=reminders:read --scope=reminders:write"}},
+					{name: "list", summary: "List API keys without their secrets", usage: "[--file=<path>]", setup: s.listKeys,
+						examples: []string{"keys list"}},
+					{name: "revoke", summary: "Revoke an API key", usage: "--id=<key id> [--file=<path>]", setup: s.revokeKey,
+						examples: []string{"keys revoke --id=k_1a2b3c"}},
+				},
+			},
+			{name: "list", summary: "Show reminders", usage: "--id=<id>[,<id>...]", setup: s.list,
+				examples: []string{"list --id=1,2", "--output=yaml list --id=1"}},
+			{name: "reopen", summary: "Schedule completed, dismissed or failed reminders again", usage: "--id=<id>[,<id>...] [--in=<duration> | --at=<time>]", setup: s.reopen,
+				examples: []string{"reopen --id=3 --in=1h", `reopen --id=3 --at="+3d 14:00"`}},
+			{name: "requeue", summary: "Retry reminders from the dead-letter list", usage: "--id=<id>[,<id>...]", setup: s.requeue,
+				examples: []string{"requeue --id=4"}},
+			{name: "snooze", summary: "Postpone pending reminders", usage: "--id=<id>[,<id>...] [--for=<duration> | --until=<time>]", setup: s.snooze,
+				examples: []string{"snooze --id=3 --for=30m", `snooze --id=3 --until="in 2 hours"`}},
+			{name: "tui", summary: "Browse and manage reminders in a full-screen terminal interface", usage: "[--all]", setup: s.tui,
+				examples: []string{"tui", "--time-zone=Europe/Berlin tui --all"}},
+			{
+				name:    "users",
+				summary: "Manage users and their notifier endpoints",
+				subcommands: []*command{
+					{name: "list", summary: "List users", usage: "[--file=<path>]", setup: s.listUsers,
+						examples: []string{"users list"}},
+					{name: "set", summary: "Create or update a user, only the given flags are changed", usage: "--name=<name> [--notifier=<url>] [--quiet-hours=<windows>] [--quiet-tz=<zone>] [--quiet-mode=defer|silent] [--quiet-channel=<name>] [--file=<path>]", setup: s.setUser,
+						examples: []string{`users set --name=bob --notifier=http://bob-laptop:9000`, `users set --name=bob --quiet-hours="mon-fri 22:00-07:00" --quiet-tz=Europe/Berlin`}},
+				},
+			},
+			{name: "watch", summary: "Print reminder events as they happen", usage: "[--type=<event type>...] [--since=<event id>]", setup: s.watch,
+				examples: []string{"watch", "watch --type=reminder.fired --type=reminder.failed"}},
+		},
+	}
+	root.subcommands = append(root.subcommands,
+		s.completionCommand(),
+		&command{name: "help", summary: "Show the help of a command", usage: "[<command>...]", args: true,
+			examples: []string{"help", "help keys create"},
+			exec: func(args []string) error {
+				path := []*command{root}
+				for _, arg := range args {
+					sub := path[len(path)-1].find(arg)
+					if sub == nil {
+						return usageErrorf("unknown command %q, see '%s --help'", arg, commandName(path))
+					}
+					path = append(path, sub)
+				}
+				s.usage(os.Stdout, path, nil)
+				return nil
+			}},
+		&command{name: completeCommand, hidden: true, exec: func(args []string) error {
+			return s.completeArgs(root, args)
+		}},
+	)
+	return root
+}
+
+/** Runs the last command of the path with the remaining arguments */
+func (s Switch) run(path []*command, args []string) error {
+	cmd := path[len(path)-1]
+	name := commandName(path)
+	if cmd.exec != nil {
+		if len(args) > 0 && isHelp(args[0]) {
+			s.usage(os.Stdout, path, nil)
+			return nil
+		}
+		return cmd.exec(args)
+	}
+	if len(cmd.subcommands) > 0 {
+		if len(args) == 0 {
+			s.usage(os.Stderr, path, nil)
+			return usageErrorf("%s expects a command", name)
+		}
+		if isHelp(args[0]) {
+			s.usage(os.Stdout, path, nil)
+			return nil
+		}
+		sub := cmd.find(args[0])
+		if sub == nil {
+			return usageErrorf("unknown command %q, see '%s --help'", args[0], name)
+		}
+		return s.run(append(path, sub), args[1:])
+	}
+
+	f := flag.NewFlagSet(name, flag.ContinueOnError)
+	f.SetOutput(ioutil.Discard)
+	run := cmd.setup(f)
+	if err := f.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			s.usage(os.Stdout, path, f)
+			return nil
+		}
+		return usageErrorf("%v, see '%s --help'", err, name)
+	}
+	if f.NArg() > 0 && !cmd.args {
+		return usageErrorf("unexpected argument %q, see '%s --help'", f.Arg(0), name)
+	}
+	return run()
+}
+
+/** Prints the usage, subcommands, flags and examples of the last command of the path */
+func (s Switch) usage(w io.Writer, path []*command, f *flag.FlagSet) {
+	cmd := path[len(path)-1]
+	name := commandName(path)
+	line := name
+	switch {
+	case len(path) == 1:
+		line += " [global flags] <command> [<args>]"
+	case len(cmd.subcommands) > 0:
+		line += " <command> [<args>]"
+	case cmd.usage != "":
+		line += " " + cmd.usage
+	}
+	fmt.Fprintf(w, "Usage: %s\n", line)
+	if cmd.summary != "" {
+		fmt.Fprintf(w, "\n%s.\n", strings.TrimSuffix(cmd.summary, "."))
+	}
+
+	subs := cmd.visible()
+	if len(subs) > 0 {
+		fmt.Fprintln(w, "\nCommands:")
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		for _, sub := range subs {
+			fmt.Fprintf(tw, "  %s\t%s\n", sub.name, sub.summary)
+		}
+		tw.Flush()
+	}
+	if f == nil {
+		f = cmd.flags(name)
+	}
+	if f != nil && hasFlags(f) {
+		fmt.Fprintln(w, "\nFlags:")
+		f.SetOutput(w)
+		f.PrintDefaults()
+	}
+	if len(path) == 1 && hasFlags(s.globals) {
+		fmt.Fprintln(w, "\nGlobal flags:")
+		s.globals.SetOutput(w)
+		s.globals.PrintDefaults()
+	}
+	if len(cmd.examples) > 0 {
+		fmt.Fprintln(w, "\nExamples:")
+		for _, ex := range cmd.examples {
+			fmt.Fprintf(w, "  %s %s\n", progName(), ex)
+		}
+	}
+	if len(subs) > 0 {
+		fmt.Fprintf(w, "\nRun '%s <command> --help' for more information on a command.\n", name)
+	}
+}
+
+func hasFlags(f *flag.FlagSet) bool {
+	has := false
+	f.VisitAll(func(*flag.Flag) { has = true })
+	return has
+}
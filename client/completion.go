@@ -0,0 +1,292 @@
+package client
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"app-pointment/client/sdk"
+	"app-pointment/server/keyfile"
+	"app-pointment/server/models"
+)
+
+/** Hidden command called by the completion scripts with the shell and the words typed so far */
+const completeCommand = "__complete"
+
+/** How long completion waits for the backend when completing reminder ids */
+const completionTimeout = 3 * time.Second
+
+/** Completion value with an optional description */
+type candidate struct {
+	value       string
+	description string
+}
+
+/** Scripts loading the completion, %[1]s is the program and %[2]s a shell function name */
+var completionScripts = map[string]string{
+	"bash": `# bash completion for %[1]s, load it with: source <(%[1]s completion bash)
+_%[2]s_complete() {
+    local IFS=$'\n'
+    COMPREPLY=($(%[1]s __complete bash "${COMP_WORDS[@]:1:COMP_CWORD}" 2>/dev/null))
+}
+complete -o default -F _%[2]s_complete %[1]s
+`,
+	"zsh": `#compdef %[1]s
+# zsh completion for %[1]s, load it with: source <(%[1]s completion zsh)
+_%[2]s_complete() {
+    local -a candidates
+    candidates=("${(@f)$(%[1]s __complete zsh "${(@)words[2,CURRENT]}" 2>/dev/null)}")
+    [[ -n ${candidates[1]} ]] && _describe -t values '%[1]s' candidates
+}
+compdef _%[2]s_complete %[1]s
+`,
+	"fish": `# fish completion for %[1]s, load it with: %[1]s completion fish | source
+function __%[2]s_complete
+    set -l tokens (commandline -opc)
+    %[1]s __complete fish $tokens[2..-1] (commandline -ct) 2>/dev/null
+end
+complete -c %[1]s -f -a '(__%[2]s_complete)'
+`,
+}
+
+/** The completion command printing a script per shell */
+func (s Switch) completionCommand() *command {
+	cmd := &command{
+		name:    "completion",
+		summary: "Print a shell completion script, reminder ids are completed from the backend",
+		examples: []string{
+			"completion bash > /etc/bash_completion.d/" + progName(),
+			"completion zsh > \"${fpath[1]}/_" + progName() + "\"",
+			"completion fish > ~/.config/fish/completions/" + progName() + ".fish",
+		},
+	}
+	fn := regexp.MustCompile(`[^A-Za-z0-9_]`).ReplaceAllString(progName(), "_")
+	for _, shell := range []string{"bash", "fish", "zsh"} {
+		script := completionScripts[shell]
+		cmd.subcommands = append(cmd.subcommands, &command{
+			name:    shell,
+			summary: "Print the " + shell + " completion script",
+			setup: func(*flag.FlagSet) func() error {
+				return func() error {
+					fmt.Printf(script, progName(), fn)
+					return nil
+				}
+			},
+		})
+	}
+	return cmd
+}
+
+/** Prints the completion of the last word, the arguments are the shell and the words typed so far */
+func (s Switch) completeArgs(root *command, args []string) error {
+	if len(args) == 0 {
+		return usageErrorf("%s expects a shell", completeCommand)
+	}
+	shell, words := args[0], args[1:]
+	if len(words) == 0 {
+		words = []string{""}
+	}
+	for _, c := range s.complete(root, shell, words) {
+		switch {
+		case shell == "bash" || c.description == "":
+			fmt.Println(c.value)
+		case shell == "zsh":
+			fmt.Printf("%s:%s\n", strings.Replace(c.value, ":", `\:`, -1), c.description)
+		default:
+			fmt.Printf("%s\t%s\n", c.value, c.description)
+		}
+	}
+	return nil
+}
+
+/** Candidates for the last word of a command line of the root command */
+func (s Switch) complete(root *command, shell string, words []string) []candidate {
+	// bash splits --id=3 into "--id", "=" and "3" and completes the last part only
+	prefix := ""
+	if shell == "bash" {
+		var merged []string
+		for i, w := range words {
+			if len(merged) > 0 && (w == "=" || words[i-1] == "=") {
+				merged[len(merged)-1] += w
+				continue
+			}
+			merged = append(merged, w)
+		}
+		if words[len(words)-1] == "=" {
+			prefix = "="
+		}
+		words = merged
+	}
+	current, typed := words[len(words)-1], words[:len(words)-1]
+
+	path := []*command{root}
+	flags := s.globals
+	value := ""
+	for i := 0; i < len(typed); i++ {
+		w := typed[i]
+		if strings.HasPrefix(w, "-") {
+			name := strings.TrimLeft(w, "-")
+			if strings.Contains(name, "=") {
+				continue
+			}
+			if fl := lookupFlag(flags, name); fl != nil && !isBoolFlag(fl) {
+				if i == len(typed)-1 {
+					value = name
+				}
+				i++
+			}
+			continue
+		}
+		cmd := path[len(path)-1]
+		if sub := cmd.find(w); sub != nil && !sub.hidden {
+			path = append(path, sub)
+			flags = sub.flags(commandName(path))
+		}
+	}
+	cmd := path[len(path)-1]
+
+	var res []candidate
+	switch {
+	case value != "":
+		res = s.flagValues(path, value)
+	case strings.HasPrefix(current, "-") && strings.Contains(current, "="):
+		parts := strings.SplitN(current, "=", 2)
+		if shell != "bash" {
+			prefix = parts[0] + "="
+		}
+		current = parts[1]
+		res = s.flagValues(path, strings.TrimLeft(parts[0], "-"))
+	case strings.HasPrefix(current, "-"):
+		if flags != nil {
+			flags.VisitAll(func(f *flag.Flag) {
+				res = append(res, candidate{value: "--" + f.Name, description: f.Usage})
+			})
+		}
+	case len(cmd.subcommands) > 0:
+		for _, sub := range cmd.visible() {
+			res = append(res, candidate{value: sub.name, description: sub.summary})
+		}
+	case cmd.complete != nil:
+		res = cmd.complete()
+	}
+
+	var matching []candidate
+	for _, c := range res {
+		if strings.HasPrefix(c.value, current) {
+			c.value = prefix + c.value
+			matching = append(matching, c)
+		}
+	}
+	return matching
+}
+
+/** Values of a flag of the command path */
+func (s Switch) flagValues(path []*command, name string) []candidate {
+	group := ""
+	if len(path) > 1 {
+		group = path[1].name
+	}
+	switch name {
+	case "id":
+		if group == "keys" {
+			return keyCandidates()
+		}
+		return s.reminderCandidates()
+	case "name":
+		if group == "users" {
+			return userCandidates()
+		}
+	case "context":
+		return contextCandidates()
+	case "output":
+		return values(OutputFormats...)
+	case "type":
+		return values(sdk.EventTypes...)
+	case "scope":
+		return values(models.Scopes...)
+	case "mode":
+		return values(sdk.BatchModes...)
+	case "quiet-mode":
+		return values(models.QuietModeDefer, models.QuietModeSilent)
+	}
+	return nil
+}
+
+func values(list ...string) []candidate {
+	res := make([]candidate, len(list))
+	for i, v := range list {
+		res[i] = candidate{value: v}
+	}
+	return res
+}
+
+/** Reminder ids fetched from the backend, pending reminders first */
+func (s Switch) reminderCandidates() []candidate {
+	ctx, cancel := context.WithTimeout(context.Background(), completionTimeout)
+	defer cancel()
+	reminders, err := s.client.ListAll(ctx)
+	if err != nil {
+		return nil
+	}
+	sort.SliceStable(reminders, func(i, j int) bool {
+		return finished(reminders[j]) && !finished(reminders[i])
+	})
+	res := make([]candidate, len(reminders))
+	for i, r := range reminders {
+		res[i] = candidate{value: strconv.Itoa(r.ID), description: fmt.Sprintf("%s (%s)", r.Title, r.Status)}
+	}
+	return res
+}
+
+func keyCandidates() []candidate {
+	keys, err := keyfile.NewKeys(keysFilePath()).List()
+	if err != nil {
+		return nil
+	}
+	var res []candidate
+	for _, k := range keys {
+		res = append(res, candidate{value: k.ID, description: k.Name})
+	}
+	return res
+}
+
+func userCandidates() []candidate {
+	users, err := keyfile.NewKeys(keysFilePath()).Users()
+	if err != nil {
+		return nil
+	}
+	var res []candidate
+	for _, u := range users {
+		res = append(res, candidate{value: u.Name, description: u.Notifier})
+	}
+	return res
+}
+
+func contextCandidates() []candidate {
+	file, err := LoadConfigFile(ConfigPath())
+	if err != nil {
+		return nil
+	}
+	var res []candidate
+	for _, name := range file.names() {
+		res = append(res, candidate{value: name, description: file.Contexts[name].Backend})
+	}
+	return res
+}
+
+func lookupFlag(f *flag.FlagSet, name string) *flag.Flag {
+	if f == nil {
+		return nil
+	}
+	return f.Lookup(name)
+}
+
+func isBoolFlag(f *flag.Flag) bool {
+	b, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return ok && b.IsBoolFlag()
+}
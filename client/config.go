@@ -0,0 +1,228 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+/** Environment variables overriding the config file */
+const (
+	ConfigFileEnv = "APP_POINTMENT_CONFIG"
+	ContextEnv    = "APP_POINTMENT_CONTEXT"
+	BackendEnv    = "APP_POINTMENT_BACKEND"
+	APIKeyEnv     = "APP_POINTMENT_API_KEY"
+	OutputEnv     = "APP_POINTMENT_OUTPUT"
+	TimeZoneEnv   = "APP_POINTMENT_TZ"
+
+	defaultContext = "default"
+)
+
+/** Named set of client settings, e.g. a local or a staging backend */
+type Context struct {
+	Backend  string `json:"backend,omitempty"`
+	APIKey   string `json:"api_key,omitempty"`
+	Output   string `json:"output,omitempty"`
+	TimeZone string `json:"time_zone,omitempty"`
+}
+
+/** Client configuration file */
+type ConfigFile struct {
+	CurrentContext string             `json:"current_context,omitempty"`
+	Contexts       map[string]Context `json:"contexts,omitempty"`
+}
+
+/** Resolves the config file path: $APP_POINTMENT_CONFIG, then the XDG config directory */
+func ConfigPath() string {
+	if path := os.Getenv(ConfigFileEnv); path != "" {
+		return path
+	}
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "app-pointment.json"
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "app-pointment", "config.json")
+}
+
+/** Loads the config file, a missing file is an empty config */
+func LoadConfigFile(path string) (ConfigFile, error) {
+	bs, err := ioutil.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return ConfigFile{}, nil
+	}
+	if err != nil {
+		return ConfigFile{}, wrapError("could not read config file", err)
+	}
+	var f ConfigFile
+	if len(bs) > 0 {
+		if err := json.Unmarshal(bs, &f); err != nil {
+			return ConfigFile{}, wrapError("could not parse config file "+path, err)
+		}
+	}
+	return f, nil
+}
+
+/** Writes the config file, it is only readable by the user as it holds API keys */
+func (f ConfigFile) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return wrapError("could not create config directory", err)
+	}
+	bs, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return wrapError("could not marshal config file", err)
+	}
+	if err := ioutil.WriteFile(path, append(bs, '\n'), 0600); err != nil {
+		return wrapError("could not write config file", err)
+	}
+	return nil
+}
+
+/** Returns the named context, or the current one when name is empty */
+func (f ConfigFile) Context(name string) (Context, error) {
+	if name == "" {
+		name = f.CurrentContext
+	}
+	if name == "" {
+		return Context{}, nil
+	}
+	ctx, ok := f.Contexts[name]
+	if !ok {
+		return Context{}, fmt.Errorf("unknown context %q, available: %v", name, f.names())
+	}
+	return ctx, nil
+}
+
+func (f ConfigFile) names() []string {
+	names := make([]string, 0, len(f.Contexts))
+	for name := range f.Contexts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+/** Switch the current context */
+func (s Switch) useContext(f *flag.FlagSet) func() error {
+	path := configFileFlag(f)
+	return func() error {
+		name := f.Arg(0)
+		if name == "" || f.NArg() > 1 {
+			return usageErrorf("use expects a single context name")
+		}
+		file, err := LoadConfigFile(*path)
+		if err != nil {
+			return err
+		}
+		if _, err := file.Context(name); err != nil {
+			return err
+		}
+		file.CurrentContext = name
+		if err := file.Save(*path); err != nil {
+			return err
+		}
+		s.output.Message("Switched to context %s.", name)
+		return nil
+	}
+}
+
+/** Create or update a context, only the given flags are changed */
+func (s Switch) setContext(f *flag.FlagSet) func() error {
+	path := configFileFlag(f)
+	name := f.String("context", "", "The context to change, defaults to the current one.")
+	backend := f.String("backend", "", "Backend API URL.")
+	apiKey := f.String("api-key", "", "Backend API key.")
+	output := f.String("output", "", "Default output format: table, json, yaml, ids.")
+	tz := f.String("time-zone", "", "Time zone used to show due times, e.g. Europe/Berlin.")
+	return func() error {
+		set := map[string]bool{}
+		f.Visit(func(fl *flag.Flag) { set[fl.Name] = true })
+
+		file, err := LoadConfigFile(*path)
+		if err != nil {
+			return err
+		}
+		if *name == "" {
+			*name = file.CurrentContext
+		}
+		if *name == "" {
+			*name = defaultContext
+		}
+		if set["output"] && *output != "" && (*output == OutputTemplate || !contains(OutputFormats, *output)) {
+			return usageErrorf("invalid output %q, expected one of: table, json, yaml, ids", *output)
+		}
+		if set["time-zone"] && *tz != "" {
+			if _, err := time.LoadLocation(*tz); err != nil {
+				return usageErrorf("invalid time zone %q: %v", *tz, err)
+			}
+		}
+		ctx := file.Contexts[*name]
+		if set["backend"] {
+			ctx.Backend = *backend
+		}
+		if set["api-key"] {
+			ctx.APIKey = *apiKey
+		}
+		if set["output"] {
+			ctx.Output = *output
+		}
+		if set["time-zone"] {
+			ctx.TimeZone = *tz
+		}
+		if file.Contexts == nil {
+			file.Contexts = map[string]Context{}
+		}
+		file.Contexts[*name] = ctx
+		if file.CurrentContext == "" {
+			file.CurrentContext = *name
+		}
+		if err := file.Save(*path); err != nil {
+			return err
+		}
+		s.output.Message("Context %s saved to %s.", *name, *path)
+		return nil
+	}
+}
+
+/** Show the config file, API keys are masked unless --raw is given */
+func (s Switch) viewConfig(f *flag.FlagSet) func() error {
+	path := configFileFlag(f)
+	raw := f.Bool("raw", false, "Show the API keys.")
+	return func() error {
+		file, err := LoadConfigFile(*path)
+		if err != nil {
+			return err
+		}
+		if !*raw {
+			for name, ctx := range file.Contexts {
+				ctx.APIKey = maskKey(ctx.APIKey)
+				file.Contexts[name] = ctx
+			}
+		}
+		return s.output.Print(file)
+	}
+}
+
+/** Registers the --file flag of the config commands */
+func configFileFlag(f *flag.FlagSet) *string {
+	return f.String("file", ConfigPath(), "Path to the client config file.")
+}
+
+func maskKey(key string) string {
+	if key == "" {
+		return ""
+	}
+	if len(key) <= 8 {
+		return "********"
+	}
+	return key[:4] + "********"
+}
@@ -0,0 +1,26 @@
+package client
+
+import (
+	"context"
+	"flag"
+)
+
+/** Show every delivery attempt of a reminder */
+func (s Switch) history(f *flag.FlagSet) func() error {
+	var id int
+	f.IntVar(&id, "id", 0, "The ID of the reminder.")
+	return func() error {
+		if id == 0 {
+			return usageErrorf("missing reminder id, use --id")
+		}
+
+		attempts, err := s.client.Deliveries(context.Background(), id)
+		if err != nil {
+			return wrapError("Could not get reminder history.", err)
+		}
+		if len(attempts) == 0 {
+			s.output.Message("Reminder %d was never notified.", id)
+		}
+		return s.output.Print(attempts)
+	}
+}
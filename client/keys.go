@@ -0,0 +1,87 @@
+package client
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"app-pointment/server/keyfile"
+	"app-pointment/server/models"
+)
+
+/** Environment variable holding the path to the API keys file */
+const KeysFileEnv = "APP_POINTMENT_KEYS_FILE"
+
+/** Manages the API keys file used by the backend */
+type KeyManager interface {
+	Create(name, user string, scopes []string) (string, models.APIKey, error)
+	List() ([]models.APIKey, error)
+	Revoke(id string) (models.APIKey, error)
+}
+
+/** Create a new API key and print it once */
+func (s Switch) createKey(f *flag.FlagSet) func() error {
+	path := keysFileFlag(f)
+	scopes := idsFlag{}
+	name := f.String("name", "", "Human readable name of the key.")
+	user := f.String("user", "", "User owning the key, defaults to the key name.")
+	f.Var(&scopes, "scope", "Scope granted to the key, repeatable: "+strings.Join(models.Scopes, ", ")+".")
+	return func() error {
+		plain, key, err := s.keyManager(*path).Create(*name, *user, scopes)
+		if err != nil {
+			return wrapError("Could not create api key.", err)
+		}
+		s.output.Message("API key %s created with scopes %s.", key.ID, strings.Join(key.Scopes, ","))
+		s.output.Message("Store it now, it cannot be shown again:")
+		fmt.Println(plain)
+		return nil
+	}
+}
+
+/** List all API keys without their secrets */
+func (s Switch) listKeys(f *flag.FlagSet) func() error {
+	path := keysFileFlag(f)
+	return func() error {
+		keys, err := s.keyManager(*path).List()
+		if err != nil {
+			return wrapError("Could not list api keys.", err)
+		}
+		return s.output.Print(keys)
+	}
+}
+
+/** Revoke an API key by its ID */
+func (s Switch) revokeKey(f *flag.FlagSet) func() error {
+	path := keysFileFlag(f)
+	id := f.String("id", "", "The ID of the key to revoke.")
+	return func() error {
+		if *id == "" {
+			return usageErrorf("missing key id, use --id")
+		}
+		key, err := s.keyManager(*path).Revoke(*id)
+		if err != nil {
+			return wrapError("Could not revoke api key.", err)
+		}
+		s.output.Message("API key %s (%s) revoked.", key.ID, key.Name)
+		return nil
+	}
+}
+
+/** Opens the keys file at the given path */
+func (s Switch) keyManager(path string) KeyManager {
+	return keyfile.NewKeys(path)
+}
+
+/** Registers the --file flag of the keys commands */
+func keysFileFlag(f *flag.FlagSet) *string {
+	return f.String("file", keysFilePath(), "Path to the API keys file.")
+}
+
+/** Resolves the keys file path from the environment */
+func keysFilePath() string {
+	if path := os.Getenv(KeysFileEnv); path != "" {
+		return path
+	}
+	return "keys.json"
+}
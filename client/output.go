@@ -0,0 +1,307 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+	"time"
+
+	"app-pointment/client/sdk"
+	"app-pointment/server/models"
+)
+
+/** Output formats of the CLI */
+const (
+	OutputTable    = "table"
+	OutputJSON     = "json"
+	OutputYAML     = "yaml"
+	OutputIDs      = "ids"
+	OutputTemplate = "template"
+)
+
+/** Supported output formats */
+var OutputFormats = []string{OutputTable, OutputJSON, OutputYAML, OutputIDs, OutputTemplate}
+
+/** Renders command results to stdout and messages to stderr */
+type Printer struct {
+	format   string
+	template *template.Template
+	out      io.Writer
+	messages io.Writer
+	location *time.Location
+}
+
+/** Creates a Printer showing times in loc, a non empty Go template selects the template format */
+func NewPrinter(format, tmpl string, loc *time.Location) (Printer, error) {
+	p := Printer{
+		format:   format,
+		out:      os.Stdout,
+		messages: os.Stderr,
+		location: loc,
+	}
+	if tmpl != "" {
+		p.format = OutputTemplate
+		t, err := template.New("output").Funcs(template.FuncMap{
+			"json": func(v interface{}) (string, error) {
+				bs, err := json.Marshal(v)
+				return string(bs), err
+			},
+			"due": func(r sdk.Reminder) string {
+				return p.due(r, time.Now())
+			},
+		}).Parse(tmpl)
+		if err != nil {
+			return Printer{}, wrapError("Invalid output template.", err)
+		}
+		p.template = t
+	}
+	if p.format == OutputTemplate && p.template == nil {
+		return Printer{}, fmt.Errorf("the template output requires --template")
+	}
+	if !contains(OutputFormats, p.format) {
+		return Printer{}, fmt.Errorf("invalid output %q, expected one of: %s", format, strings.Join(OutputFormats, ", "))
+	}
+	return p, nil
+}
+
+/** Prints a plain message to stderr */
+func (p Printer) Message(format string, args ...interface{}) {
+	fmt.Fprintf(p.messages, strings.TrimSuffix(format, "\n")+"\n", args...)
+}
+
+/** Prints a command result in the selected format */
+func (p Printer) Print(v interface{}) error {
+	switch p.format {
+	case OutputJSON:
+		bs, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return wrapError("could not marshal output", err)
+		}
+		_, err = fmt.Fprintln(p.out, string(bs))
+		return err
+	case OutputYAML:
+		return encodeYAML(p.out, v)
+	case OutputIDs:
+		return p.ids(v)
+	case OutputTemplate:
+		var buf bytes.Buffer
+		if err := p.template.Execute(&buf, v); err != nil {
+			return wrapError("could not execute output template", err)
+		}
+		if !bytes.HasSuffix(buf.Bytes(), []byte("\n")) {
+			buf.WriteByte('\n')
+		}
+		_, err := p.out.Write(buf.Bytes())
+		return err
+	}
+	return p.table(v)
+}
+
+/** Prints a single event of a stream, one line per event in the table and JSON formats */
+func (p Printer) PrintEvent(e sdk.Event) error {
+	switch p.format {
+	case OutputTable:
+		_, err := fmt.Fprintf(p.out, "%s  %-20s #%-4d %s (%s)\n",
+			e.At.In(p.location).Format("15:04:05"),
+			e.Type,
+			e.Reminder.ID,
+			e.Reminder.Title,
+			e.Reminder.Status,
+		)
+		return err
+	case OutputJSON:
+		bs, err := json.Marshal(e)
+		if err != nil {
+			return wrapError("could not marshal event", err)
+		}
+		_, err = fmt.Fprintln(p.out, string(bs))
+		return err
+	case OutputYAML:
+		fmt.Fprintln(p.out, "---")
+	}
+	return p.Print(e)
+}
+
+func (p Printer) ids(v interface{}) error {
+	var ids []string
+	switch v := v.(type) {
+	case sdk.Reminder:
+		ids = append(ids, fmt.Sprint(v.ID))
+	case []sdk.Reminder:
+		for _, r := range v {
+			ids = append(ids, fmt.Sprint(r.ID))
+		}
+	case sdk.Event:
+		ids = append(ids, fmt.Sprint(v.Reminder.ID))
+	case []int:
+		for _, id := range v {
+			ids = append(ids, fmt.Sprint(id))
+		}
+	case []models.APIKey:
+		for _, key := range v {
+			ids = append(ids, key.ID)
+		}
+	case []models.User:
+		for _, u := range v {
+			ids = append(ids, u.Name)
+		}
+	case []applyChange:
+		for _, c := range v {
+			if c.ID != 0 {
+				ids = append(ids, fmt.Sprint(c.ID))
+			}
+		}
+	case ConfigFile:
+		ids = v.names()
+	default:
+		return fmt.Errorf("the ids output is not supported by this command")
+	}
+	for _, id := range ids {
+		if _, err := fmt.Fprintln(p.out, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p Printer) table(v interface{}) error {
+	w := tabwriter.NewWriter(p.out, 0, 0, 2, ' ', 0)
+	now := time.Now()
+	switch v := v.(type) {
+	case sdk.Reminder:
+		return p.table([]sdk.Reminder{v})
+	case []sdk.Reminder:
+		fmt.Fprintln(w, "ID\tTITLE\tSTATUS\tDUE\tCHANNELS\tATTEMPTS")
+		for _, r := range v {
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%d\n",
+				r.ID,
+				reminderLabel(r),
+				r.Status,
+				p.due(r, now),
+				strings.Join(r.Channels, ","),
+				r.Attempts,
+			)
+		}
+	case []sdk.DeliveryAttempt:
+		fmt.Fprintln(w, "TIME\tATTEMPT\tCHANNEL\tOUTCOME\tACTIVATION\tLATENCY\tERROR")
+		for _, a := range v {
+			activation := a.ActivationType
+			if a.ActivationValue != "" {
+				activation += ":" + a.ActivationValue
+			}
+			if a.DeferredUntil != nil {
+				activation = "until " + a.DeferredUntil.In(p.location).Format("2006-01-02 15:04")
+			}
+			fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\t%v\t%s\n",
+				a.At.In(p.location).Format("2006-01-02 15:04:05"),
+				a.Attempt,
+				a.Channel,
+				a.Outcome,
+				activation,
+				a.Latency.Round(time.Millisecond),
+				a.Error,
+			)
+		}
+	case sdk.HealthReport:
+		fmt.Fprintln(w, "COMPONENT\tSTATUS\tMESSAGE")
+		for _, c := range v.Components {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", c.Name, c.Status, c.Message)
+		}
+	case []int:
+		fmt.Fprintln(w, "ID")
+		for _, id := range v {
+			fmt.Fprintf(w, "%d\n", id)
+		}
+	case []models.APIKey:
+		fmt.Fprintln(w, "ID\tNAME\tUSER\tSCOPES\tCREATED\tSTATUS")
+		for _, key := range v {
+			status := "active"
+			if key.Revoked() {
+				status = "revoked"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+				key.ID,
+				key.Name,
+				key.Principal().User,
+				strings.Join(key.Scopes, ","),
+				key.CreatedAt.In(p.location).Format("2006-01-02 15:04"),
+				status,
+			)
+		}
+	case []models.User:
+		fmt.Fprintln(w, "NAME\tNOTIFIER\tQUIET HOURS")
+		for _, u := range v {
+			var quiet string
+			if u.QuietHours != nil {
+				quiet = u.QuietHours.String()
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\n", u.Name, u.Notifier, quiet)
+		}
+	case []applyChange:
+		fmt.Fprintln(w, "OP\tID\tTITLE\tCHANGES\tSTATUS\tERROR")
+		for _, c := range v {
+			id := "-"
+			if c.ID != 0 {
+				id = fmt.Sprint(c.ID)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", c.Op, id, c.Title, strings.Join(c.Changes, ","), c.Status, c.Error)
+		}
+	case ConfigFile:
+		fmt.Fprintln(w, "CURRENT\tNAME\tBACKEND\tAPI KEY\tOUTPUT\tTIME ZONE")
+		for _, name := range v.names() {
+			ctx := v.Contexts[name]
+			current := ""
+			if name == v.CurrentContext {
+				current = "*"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", current, name, ctx.Backend, ctx.APIKey, ctx.Output, ctx.TimeZone)
+		}
+	default:
+		p.format = OutputYAML
+		return p.Print(v)
+	}
+	return w.Flush()
+}
+
+/** Human friendly due time of a pending reminder, e.g. "Mon 15:04 (in 2h5m)" */
+func (p Printer) due(r sdk.Reminder, now time.Time) string {
+	switch r.Status {
+	case sdk.StatusCompleted, sdk.StatusDismissed, sdk.StatusFailed:
+		return "-"
+	}
+	at := r.ModifiedAt.Add(r.Duration).In(p.location)
+	layout := "15:04"
+	if at.YearDay() != now.In(p.location).YearDay() || at.Year() != now.Year() {
+		layout = "Jan 02 15:04"
+	}
+	return fmt.Sprintf("%s (%s)", at.Format(layout), relative(at.Sub(now)))
+}
+
+/** Renders a duration as "in 5m" or "3h ago" at a readable precision */
+func relative(d time.Duration) string {
+	past := d < 0
+	if past {
+		d = -d
+	}
+	var s string
+	switch {
+	case d < time.Minute:
+		s = d.Round(time.Second).String()
+	case d < 48*time.Hour:
+		s = strings.TrimSuffix(d.Round(time.Minute).String(), "0s")
+		if strings.HasSuffix(s, "h0m") {
+			s = strings.TrimSuffix(s, "0m")
+		}
+	default:
+		s = fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+	if past {
+		return s + " ago"
+	}
+	return "in " + s
+}
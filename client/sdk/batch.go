@@ -0,0 +1,81 @@
+package sdk
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+/** Batch operations, modes and result statuses */
+const (
+	BatchCreate = "create"
+	BatchEdit   = "edit"
+	BatchDelete = "delete"
+
+	BatchAtomic     = "atomic"
+	BatchBestEffort = "best_effort"
+
+	BatchStatusOK      = "ok"
+	BatchStatusFailed  = "failed"
+	BatchStatusSkipped = "skipped"
+
+	/** Most operations the backend accepts in a single batch */
+	MaxBatchOperations = 500
+)
+
+/** Every batch mode */
+var BatchModes = []string{BatchAtomic, BatchBestEffort}
+
+/** Result of a single batch operation, Status is ok, failed or skipped */
+type BatchResult struct {
+	Index    int       `json:"index"`
+	Op       string    `json:"op"`
+	ID       int       `json:"id,omitempty"`
+	Status   string    `json:"status"`
+	Reminder *Reminder `json:"reminder,omitempty"`
+	Error    *APIError `json:"error,omitempty"`
+}
+
+/** Results of a batch in the order of its operations */
+type BatchResponse struct {
+	Mode      string        `json:"mode"`
+	Succeeded int           `json:"succeeded"`
+	Failed    int           `json:"failed"`
+	Results   []BatchResult `json:"results"`
+}
+
+/** Operation of a batch, edits and deletes require the ID and edits only change the set fields */
+type BatchOperation struct {
+	Op               string            `json:"op"`
+	ID               int               `json:"id,omitempty"`
+	Owner            string            `json:"owner,omitempty"`
+	Title            string            `json:"title,omitempty"`
+	Message          string            `json:"message,omitempty"`
+	Template         string            `json:"template,omitempty"`
+	Variables        map[string]string `json:"variables,omitempty"`
+	Duration         time.Duration     `json:"duration,omitempty"`
+	Channels         []string          `json:"channels,omitempty"`
+	RetryPolicy      *RetryPolicy      `json:"retry_policy,omitempty"`
+	IgnoreQuietHours *bool             `json:"ignore_quiet_hours,omitempty"`
+	EscalationPolicy string            `json:"escalation_policy,omitempty"`
+}
+
+/** Batch request, an atomic batch is applied only when every operation is valid */
+type BatchRequest struct {
+	Mode       string           `json:"mode,omitempty"`
+	Operations []BatchOperation `json:"operations"`
+
+	/** Sent as the Idempotency-Key header, generated when empty */
+	IdempotencyKey string `json:"-"`
+}
+
+/** Applies create, edit and delete operations in a single request */
+func (c *Client) Batch(ctx context.Context, r BatchRequest) (BatchResponse, error) {
+	key := r.IdempotencyKey
+	if key == "" {
+		key = newIdempotencyKey()
+	}
+	var res BatchResponse
+	err := c.call(ctx, http.MethodPost, "/reminders:batch", key, r, &res, http.StatusOK)
+	return res, err
+}
@@ -0,0 +1,239 @@
+/** Package sdk is a typed Go client for the app-pointment backend API */
+package sdk
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	mathrand "math/rand"
+	"net/http"
+	"strings"
+	"time"
+)
+
+/** Defaults of a new Client */
+const (
+	DefaultTimeout    = 30 * time.Second
+	DefaultRetries    = 3
+	DefaultMinBackoff = 250 * time.Millisecond
+	DefaultMaxBackoff = 5 * time.Second
+
+	idempotencyKeyHeader = "Idempotency-Key"
+)
+
+/** Backend API client, safe for concurrent use */
+type Client struct {
+	baseURL string
+	apiKey  string
+	http    *http.Client
+
+	timeout    time.Duration
+	retries    int
+	minBackoff time.Duration
+	maxBackoff time.Duration
+}
+
+/** Configures a Client */
+type Option func(*Client)
+
+/** Uses the given http.Client for every request */
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		c.http = hc
+	}
+}
+
+/** Authenticates every request with the given API key */
+func WithAPIKey(key string) Option {
+	return func(c *Client) {
+		c.apiKey = key
+	}
+}
+
+/** Limits how long a single request attempt may take, 0 disables the limit. The event stream is not limited */
+func WithTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = d
+	}
+}
+
+/** Sets how many times transient failures of safe or idempotent requests are retried */
+func WithRetries(n int) Option {
+	return func(c *Client) {
+		c.retries = n
+	}
+}
+
+/** Sets the bounds of the exponential backoff between retries */
+func WithBackoff(min, max time.Duration) Option {
+	return func(c *Client) {
+		c.minBackoff = min
+		c.maxBackoff = max
+	}
+}
+
+/** Creates a new Client for the backend at baseURL, e.g. http://localhost:8008 */
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		http:       &http.Client{},
+		timeout:    DefaultTimeout,
+		retries:    DefaultRetries,
+		minBackoff: DefaultMinBackoff,
+		maxBackoff: DefaultMaxBackoff,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+/** Base URL of the backend */
+func (c *Client) BaseURL() string {
+	return c.baseURL
+}
+
+/** Makes a new backend api call, decoding the response into out unless it is nil */
+func (c *Client) do(ctx context.Context, method, path string, body, out interface{}, resCode int) error {
+	return c.call(ctx, method, path, "", body, out, resCode)
+}
+
+/** Makes a backend api call, retrying transient failures of GET requests and of requests with an idempotency key */
+func (c *Client) call(ctx context.Context, method, path, key string, body, out interface{}, resCode int) error {
+	var payload []byte
+	if body != nil {
+		bs, err := json.Marshal(body)
+		if err != nil {
+			return wrapError("could not marshal request body", err)
+		}
+		payload = bs
+	}
+	retryable := method == http.MethodGet || key != ""
+	for attempt := 0; ; attempt++ {
+		err := c.attempt(ctx, method, path, key, payload, out, resCode)
+		if err == nil || !retryable || attempt >= c.retries || !c.transient(ctx, err) {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(c.backoff(attempt)):
+		}
+	}
+}
+
+func (c *Client) attempt(ctx context.Context, method, path, key string, payload []byte, out interface{}, resCode int) error {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+	var reader io.Reader
+	if payload != nil {
+		reader = bytes.NewReader(payload)
+	}
+	req, err := c.newRequest(ctx, method, path, reader)
+	if err != nil {
+		return err
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if key != "" {
+		req.Header.Set(idempotencyKeyHeader, key)
+	}
+	res, err := c.http.Do(req)
+	if err != nil {
+		return wrapError("could not make http call", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != resCode {
+		return decodeError(res)
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(res.Body).Decode(out); err != nil {
+		return wrapError("could not decode response body", err)
+	}
+	return nil
+}
+
+/** Applies the per attempt timeout */
+func (c *Client) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, c.timeout)
+}
+
+/** Reports whether a failed attempt may succeed when retried */
+func (c *Client) transient(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		// Network errors and attempts cut by the timeout.
+		return true
+	}
+	switch apiErr.StatusCode {
+	case http.StatusConflict, http.StatusTooManyRequests, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+/** Exponential backoff with jitter */
+func (c *Client) backoff(attempt int) time.Duration {
+	d := c.minBackoff << uint(attempt)
+	if d <= 0 || d > c.maxBackoff {
+		d = c.maxBackoff
+	}
+	return d/2 + time.Duration(mathrand.Int63n(int64(d/2)+1))
+}
+
+/** Generates a random idempotency key */
+func newIdempotencyKey() string {
+	bs := make([]byte, 16)
+	if _, err := rand.Read(bs); err != nil {
+		return fmt.Sprintf("%d", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(bs)
+}
+
+/** Creates a request carrying the API key when one is configured */
+func (c *Client) newRequest(ctx context.Context, method, path string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return nil, wrapError("could not create request", err)
+	}
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+	return req, nil
+}
+
+/** Builds an APIError from an unexpected response */
+func decodeError(res *http.Response) error {
+	e := &APIError{StatusCode: res.StatusCode}
+	bs, err := ioutil.ReadAll(res.Body)
+	if err == nil && len(bs) > 0 {
+		if json.Unmarshal(bs, e) != nil {
+			e.Message = strings.TrimSpace(string(bs))
+		}
+	}
+	if e.Message == "" {
+		e.Message = http.StatusText(res.StatusCode)
+	}
+	return e
+}
+
+/** Custom error wrapper */
+func wrapError(customMsg string, originalErr error) error {
+	return fmt.Errorf("%s: %w", customMsg, originalErr)
+}
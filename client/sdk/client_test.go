@@ -0,0 +1,118 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+/** A backend answering with the given status codes in turn, the last one repeats */
+type scriptedBackend struct {
+	mu    sync.Mutex
+	codes []int
+	delay time.Duration
+	keys  []string
+}
+
+func (b *scriptedBackend) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	b.mu.Lock()
+	b.keys = append(b.keys, r.Header.Get(idempotencyKeyHeader))
+	code := b.codes[0]
+	if len(b.codes) > 1 {
+		b.codes = b.codes[1:]
+	}
+	b.mu.Unlock()
+	time.Sleep(b.delay)
+	w.WriteHeader(code)
+	switch {
+	case code >= 400:
+		fmt.Fprintf(w, `{"type":"error","message":"status %d"}`, code)
+	case r.Method == http.MethodGet:
+		fmt.Fprint(w, `[{"id":1}]`)
+	default:
+		fmt.Fprint(w, `{"id":1}`)
+	}
+}
+
+func (b *scriptedBackend) calls() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]string(nil), b.keys...)
+}
+
+func TestRetries(t *testing.T) {
+	create := func(c *Client) error {
+		_, err := c.Create(context.Background(), CreateRequest{Title: "t", Message: "m", Duration: time.Minute})
+		return err
+	}
+	edit := func(c *Client) error {
+		_, err := c.Edit(context.Background(), 1, EditRequest{Title: "t"})
+		return err
+	}
+	list := func(c *Client) error {
+		_, err := c.List(context.Background(), 1)
+		return err
+	}
+	tests := []struct {
+		name    string
+		codes   []int
+		delay   time.Duration
+		opts    []Option
+		call    func(c *Client) error
+		calls   int
+		wantErr bool
+	}{
+		{"create after transient failures", []int{503, 502, 201}, 0, nil, create, 3, false},
+		{"create in progress elsewhere", []int{409, 201}, 0, nil, create, 2, false},
+		{"create gives up", []int{503}, 0, []Option{WithRetries(2)}, create, 3, true},
+		{"create rejected", []int{400}, 0, nil, create, 1, true},
+		{"edit is not retried", []int{503, 200}, 0, nil, edit, 1, true},
+		{"list after a transient failure", []int{504, 200}, 0, nil, list, 2, false},
+		{"attempt timeout", []int{200}, 50 * time.Millisecond, []Option{WithTimeout(10 * time.Millisecond), WithRetries(1)}, list, 2, true},
+		{"retries disabled", []int{503, 201}, 0, []Option{WithRetries(0)}, create, 1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := &scriptedBackend{codes: tt.codes, delay: tt.delay}
+			srv := httptest.NewServer(backend)
+			defer srv.Close()
+			opts := append([]Option{WithBackoff(time.Millisecond, time.Millisecond)}, tt.opts...)
+			err := tt.call(New(srv.URL, opts...))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("got error %v, want error %v", err, tt.wantErr)
+			}
+			keys := backend.calls()
+			if len(keys) != tt.calls {
+				t.Fatalf("backend called %d time(s), want %d", len(keys), tt.calls)
+			}
+			for _, key := range keys[1:] {
+				if key != keys[0] {
+					t.Errorf("retried with idempotency keys %v, want the same key", keys)
+				}
+			}
+		})
+	}
+}
+
+func TestCreateIdempotencyKey(t *testing.T) {
+	backend := &scriptedBackend{codes: []int{201}}
+	srv := httptest.NewServer(backend)
+	defer srv.Close()
+	c := New(srv.URL)
+	for _, key := range []string{"", "", "mine"} {
+		if _, err := c.Create(context.Background(), CreateRequest{IdempotencyKey: key}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	keys := backend.calls()
+	if keys[0] == "" || keys[0] == keys[1] {
+		t.Errorf("generated keys %q and %q, want distinct keys", keys[0], keys[1])
+	}
+	if keys[2] != "mine" {
+		t.Errorf("sent key %q, want the given key", keys[2])
+	}
+}
@@ -0,0 +1,77 @@
+package sdk
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+/** Error types sent by the backend in the "type" field of error responses */
+const (
+	TypeNotFound         = "resource_not_found_error"
+	TypeDataValidation   = "data_validation_error"
+	TypeFormatValidation = "format_validation_error"
+	TypeInvalidJSON      = "invalid_json_error"
+	TypeTimeout          = "timeout_error"
+	TypeUnauthorized     = "unauthorized_error"
+	TypeForbidden        = "forbidden_error"
+	TypeConflict         = "conflict_error"
+	TypeService          = "service_error"
+)
+
+/** Error kinds to match API errors with errors.Is */
+var (
+	ErrNotFound     = errors.New("resource not found")
+	ErrValidation   = errors.New("invalid request")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+	ErrConflict     = errors.New("conflicting request")
+	ErrTimeout      = errors.New("request timed out")
+	ErrService      = errors.New("service error")
+)
+
+var errorKinds = map[string]error{
+	TypeNotFound:         ErrNotFound,
+	TypeDataValidation:   ErrValidation,
+	TypeFormatValidation: ErrValidation,
+	TypeInvalidJSON:      ErrValidation,
+	TypeTimeout:          ErrTimeout,
+	TypeUnauthorized:     ErrUnauthorized,
+	TypeForbidden:        ErrForbidden,
+	TypeConflict:         ErrConflict,
+	TypeService:          ErrService,
+}
+
+var statusKinds = map[int]error{
+	http.StatusNotFound:           ErrNotFound,
+	http.StatusBadRequest:         ErrValidation,
+	http.StatusUnauthorized:       ErrUnauthorized,
+	http.StatusForbidden:          ErrForbidden,
+	http.StatusConflict:           ErrConflict,
+	http.StatusServiceUnavailable: ErrTimeout,
+}
+
+/** Error response of the backend API */
+type APIError struct {
+	StatusCode int    `json:"-"`
+	Type       string `json:"type"`
+	Message    string `json:"message"`
+}
+
+func (e *APIError) Error() string {
+	if e.Type == "" {
+		return fmt.Sprintf("unexpected response code %d: %s", e.StatusCode, e.Message)
+	}
+	return fmt.Sprintf("%s: %s", e.Type, e.Message)
+}
+
+/** Matches the error kind of the server type, or of the status code when the type is unknown */
+func (e *APIError) Is(target error) bool {
+	if kind, ok := errorKinds[e.Type]; ok {
+		return kind == target
+	}
+	if kind, ok := statusKinds[e.StatusCode]; ok {
+		return kind == target
+	}
+	return e.StatusCode >= http.StatusInternalServerError && target == ErrService
+}
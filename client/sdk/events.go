@@ -0,0 +1,94 @@
+package sdk
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/** Event types of the event stream */
+const (
+	EventReminderCreated   = "reminder.created"
+	EventReminderUpdated   = "reminder.updated"
+	EventReminderDeleted   = "reminder.deleted"
+	EventReminderFired     = "reminder.fired"
+	EventReminderSnoozed   = "reminder.snoozed"
+	EventReminderCompleted = "reminder.completed"
+	EventReminderDismissed = "reminder.dismissed"
+	EventReminderReopened  = "reminder.reopened"
+	EventReminderFailed    = "reminder.failed"
+	EventReminderDeferred  = "reminder.deferred"
+	EventReminderEscalated = "reminder.escalated"
+)
+
+/** Every event type, used to filter the event stream */
+var EventTypes = []string{
+	EventReminderCreated,
+	EventReminderUpdated,
+	EventReminderDeleted,
+	EventReminderFired,
+	EventReminderSnoozed,
+	EventReminderCompleted,
+	EventReminderDismissed,
+	EventReminderReopened,
+	EventReminderFailed,
+	EventReminderDeferred,
+	EventReminderEscalated,
+}
+
+/** Reminder lifecycle event received from the event stream */
+type Event struct {
+	ID       int64     `json:"id"`
+	Type     string    `json:"type"`
+	Reminder Reminder  `json:"reminder"`
+	At       time.Time `json:"at"`
+}
+
+/** Reads the event stream until it ends or ctx is done, returns the id of the last received event */
+func (c *Client) Watch(ctx context.Context, lastID int64, handle func(Event)) (int64, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, "/events", nil)
+	if err != nil {
+		return lastID, err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	if lastID > 0 {
+		req.Header.Set("Last-Event-ID", strconv.FormatInt(lastID, 10))
+	}
+	res, err := c.http.Do(req)
+	if err != nil {
+		return lastID, wrapError("could not make http call", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return lastID, decodeError(res)
+	}
+
+	var data strings.Builder
+	scanner := bufio.NewScanner(res.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if data.Len() == 0 {
+				continue
+			}
+			var e Event
+			if err := json.Unmarshal([]byte(data.String()), &e); err != nil {
+				return lastID, wrapError("could not decode event", err)
+			}
+			data.Reset()
+			lastID = e.ID
+			handle(e)
+		case strings.HasPrefix(line, "data:"):
+			data.WriteString(strings.TrimSpace(strings.TrimPrefix(line, "data:")))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return lastID, wrapError("event stream interrupted", err)
+	}
+	return lastID, nil
+}
@@ -0,0 +1,69 @@
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+)
+
+/** Health of a single server component */
+type ComponentHealth struct {
+	Name    string                 `json:"name"`
+	Status  string                 `json:"status"`
+	Message string                 `json:"message,omitempty"`
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+/** Readiness report returned by the health endpoint */
+type HealthReport struct {
+	Status     string            `json:"status"`
+	Components []ComponentHealth `json:"components"`
+}
+
+/** Reports whether the whole host is healthy */
+func (r HealthReport) Healthy() bool {
+	return r.Status == "up"
+}
+
+/** Fetches the readiness report, falling back to the plain health check */
+func (c *Client) Health(ctx context.Context) (HealthReport, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+	req, err := c.newRequest(ctx, http.MethodGet, "/health/ready", nil)
+	if err != nil {
+		return HealthReport{Status: "down"}, err
+	}
+	res, err := c.http.Do(req)
+	if err != nil {
+		return HealthReport{Status: "down"}, wrapError("could not reach host", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode == http.StatusNotFound {
+		return c.liveness(ctx)
+	}
+	var report HealthReport
+	if err := json.NewDecoder(res.Body).Decode(&report); err != nil {
+		return HealthReport{Status: "down"}, wrapError("could not decode health report", err)
+	}
+	if report.Status == "" {
+		report.Status = "down"
+	}
+	return report, nil
+}
+
+/** Checks whether a host without a readiness endpoint is up and running */
+func (c *Client) liveness(ctx context.Context) (HealthReport, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, "/health", nil)
+	if err != nil {
+		return HealthReport{Status: "down"}, err
+	}
+	res, err := c.http.Do(req)
+	if err != nil {
+		return HealthReport{Status: "down"}, wrapError("could not reach host", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return HealthReport{Status: "down"}, nil
+	}
+	return HealthReport{Status: "up"}, nil
+}
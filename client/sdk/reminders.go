@@ -0,0 +1,252 @@
+package sdk
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/** Reminder statuses */
+const (
+	StatusPending   = "pending"
+	StatusDeferred  = "deferred"
+	StatusCompleted = "completed"
+	StatusDismissed = "dismissed"
+	StatusFailed    = "failed"
+)
+
+/** Every reminder status, in lifecycle order */
+var Statuses = []string{StatusPending, StatusDeferred, StatusCompleted, StatusDismissed, StatusFailed}
+
+/** Delivery outcomes of a channel */
+const (
+	DeliveryDelivered   = "delivered"
+	DeliveryAwaiting    = "awaiting_ack"
+	DeliverySnoozed     = "snoozed"
+	DeliveryFailed      = "failed"
+	DeliveryDeferred    = "deferred"
+	DeliveryUnanswered  = "unanswered"
+	DeliveryCircuitOpen = "circuit_open"
+)
+
+/** Reminder as returned by the backend */
+type Reminder struct {
+	ID               int                        `json:"id"`
+	Owner            string                     `json:"owner,omitempty"`
+	Title            string                     `json:"title"`
+	Message          string                     `json:"message"`
+	Template         string                     `json:"template,omitempty"`
+	Variables        map[string]string          `json:"variables,omitempty"`
+	Duration         time.Duration              `json:"duration"`
+	Status           string                     `json:"status,omitempty"`
+	DeferredUntil    *time.Time                 `json:"deferred_until,omitempty"`
+	IgnoreQuietHours bool                       `json:"ignore_quiet_hours,omitempty"`
+	Attempts         int                        `json:"attempts,omitempty"`
+	LastError        string                     `json:"last_error,omitempty"`
+	RetryPolicy      *RetryPolicy               `json:"retry_policy,omitempty"`
+	Channels         []string                   `json:"channels,omitempty"`
+	Deliveries       map[string]ChannelDelivery `json:"deliveries,omitempty"`
+	EscalationPolicy string                     `json:"escalation_policy,omitempty"`
+	EscalationLevel  int                        `json:"escalation_level,omitempty"`
+	Unacknowledged   int                        `json:"unacknowledged,omitempty"`
+	FirstNotifiedAt  *time.Time                 `json:"first_notified_at,omitempty"`
+	History          []HistoryEntry             `json:"history,omitempty"`
+	CreatedAt        time.Time                  `json:"created_at"`
+	ModifiedAt       time.Time                  `json:"modified_at"`
+}
+
+/** Retry policy of failed notifications */
+type RetryPolicy struct {
+	MaxAttempts  int           `json:"max_attempts,omitempty"`
+	InitialDelay time.Duration `json:"initial_delay,omitempty"`
+	MaxDelay     time.Duration `json:"max_delay,omitempty"`
+	Multiplier   float64       `json:"multiplier,omitempty"`
+	Jitter       float64       `json:"jitter,omitempty"`
+}
+
+/** Latest delivery of a reminder through a channel */
+type ChannelDelivery struct {
+	Status     string        `json:"status"`
+	DeliveryID string        `json:"delivery_id,omitempty"`
+	Error      string        `json:"error,omitempty"`
+	Snooze     time.Duration `json:"snooze,omitempty"`
+	At         time.Time     `json:"at"`
+}
+
+/** Entry of the reminder history, such as an escalation step */
+type HistoryEntry struct {
+	At       time.Time `json:"at"`
+	Type     string    `json:"type"`
+	Level    int       `json:"level,omitempty"`
+	Channels []string  `json:"channels,omitempty"`
+	Reason   string    `json:"reason,omitempty"`
+}
+
+/** Single notification attempt of a reminder through a channel */
+type DeliveryAttempt struct {
+	ReminderID      int           `json:"reminder_id"`
+	Channel         string        `json:"channel"`
+	Attempt         int           `json:"attempt"`
+	Outcome         string        `json:"outcome"`
+	ActivationType  string        `json:"activation_type,omitempty"`
+	ActivationValue string        `json:"activation_value,omitempty"`
+	DeliveryID      string        `json:"delivery_id,omitempty"`
+	Error           string        `json:"error,omitempty"`
+	Latency         time.Duration `json:"latency"`
+	DeferredUntil   *time.Time    `json:"deferred_until,omitempty"`
+	At              time.Time     `json:"at"`
+}
+
+/** Reminder creation request, either Title and Message or Template are required */
+type CreateRequest struct {
+	Owner            string            `json:"owner,omitempty"`
+	Title            string            `json:"title,omitempty"`
+	Message          string            `json:"message,omitempty"`
+	Template         string            `json:"template,omitempty"`
+	Variables        map[string]string `json:"variables,omitempty"`
+	Duration         time.Duration     `json:"duration"`
+	Channels         []string          `json:"channels,omitempty"`
+	RetryPolicy      *RetryPolicy      `json:"retry_policy,omitempty"`
+	IgnoreQuietHours bool              `json:"ignore_quiet_hours,omitempty"`
+	EscalationPolicy string            `json:"escalation_policy,omitempty"`
+
+	/** Sent as the Idempotency-Key header, generated when empty */
+	IdempotencyKey string `json:"-"`
+}
+
+/** Reminder edit request, only the set fields are changed */
+type EditRequest struct {
+	Title            string            `json:"title,omitempty"`
+	Message          string            `json:"message,omitempty"`
+	Template         string            `json:"template,omitempty"`
+	Variables        map[string]string `json:"variables,omitempty"`
+	Duration         time.Duration     `json:"duration,omitempty"`
+	Channels         []string          `json:"channels,omitempty"`
+	RetryPolicy      *RetryPolicy      `json:"retry_policy,omitempty"`
+	IgnoreQuietHours *bool             `json:"ignore_quiet_hours,omitempty"`
+	EscalationPolicy string            `json:"escalation_policy,omitempty"`
+}
+
+/** Lifecycle request body, the duration is when the reminder fires again */
+type transitionBody struct {
+	DeliveryID string        `json:"delivery_id,omitempty"`
+	Duration   time.Duration `json:"duration,omitempty"`
+}
+
+/** Creates a new reminder, retried requests reuse the idempotency key so the reminder is created once */
+func (c *Client) Create(ctx context.Context, r CreateRequest) (Reminder, error) {
+	key := r.IdempotencyKey
+	if key == "" {
+		key = newIdempotencyKey()
+	}
+	var res Reminder
+	err := c.call(ctx, http.MethodPost, "/reminders", key, r, &res, http.StatusCreated)
+	return res, err
+}
+
+/** Edits an existing reminder */
+func (c *Client) Edit(ctx context.Context, id int, r EditRequest) (Reminder, error) {
+	var res Reminder
+	err := c.do(ctx, http.MethodPatch, reminderPath(id), r, &res, http.StatusOK)
+	return res, err
+}
+
+/** Fetches a single reminder */
+func (c *Client) Get(ctx context.Context, id int) (Reminder, error) {
+	res, err := c.List(ctx, id)
+	if err != nil {
+		return Reminder{}, err
+	}
+	if len(res) == 0 {
+		return Reminder{}, &APIError{StatusCode: http.StatusNotFound, Type: TypeNotFound, Message: "could not find reminder with id: " + strconv.Itoa(id)}
+	}
+	return res[0], nil
+}
+
+/** Fetches the reminders with the given ids */
+func (c *Client) List(ctx context.Context, ids ...int) ([]Reminder, error) {
+	var res []Reminder
+	err := c.do(ctx, http.MethodGet, "/reminders/"+joinIDs(ids), nil, &res, http.StatusOK)
+	return res, err
+}
+
+/** Lists every accessible reminder, only the ones in the given statuses when any is given */
+func (c *Client) ListAll(ctx context.Context, statuses ...string) ([]Reminder, error) {
+	path := "/reminders"
+	if len(statuses) > 0 {
+		path += "?" + url.Values{"status": {strings.Join(statuses, ",")}}.Encode()
+	}
+	var res []Reminder
+	err := c.do(ctx, http.MethodGet, path, nil, &res, http.StatusOK)
+	return res, err
+}
+
+/** Deletes the reminders with the given ids */
+func (c *Client) Delete(ctx context.Context, ids ...int) error {
+	return c.do(ctx, http.MethodDelete, "/reminders/"+joinIDs(ids), nil, nil, http.StatusNoContent)
+}
+
+/** Lists reminders which exhausted their retries */
+func (c *Client) DeadLetter(ctx context.Context) ([]Reminder, error) {
+	var res []Reminder
+	err := c.do(ctx, http.MethodGet, "/reminders/dead-letter", nil, &res, http.StatusOK)
+	return res, err
+}
+
+/** Retries a reminder from the dead-letter list */
+func (c *Client) Requeue(ctx context.Context, id int) (Reminder, error) {
+	return c.transition(ctx, id, "requeue", transitionBody{})
+}
+
+/** Acknowledges a notification, deliveryID may be empty when a single one is awaited */
+func (c *Client) Ack(ctx context.Context, id int, deliveryID string) (Reminder, error) {
+	return c.transition(ctx, id, "ack", transitionBody{DeliveryID: deliveryID})
+}
+
+/** Postpones a pending reminder */
+func (c *Client) Snooze(ctx context.Context, id int, d time.Duration) (Reminder, error) {
+	return c.transition(ctx, id, "snooze", transitionBody{Duration: d})
+}
+
+/** Marks a reminder as completed */
+func (c *Client) Complete(ctx context.Context, id int) (Reminder, error) {
+	return c.transition(ctx, id, "complete", transitionBody{})
+}
+
+/** Stops a reminder without completing it */
+func (c *Client) Dismiss(ctx context.Context, id int) (Reminder, error) {
+	return c.transition(ctx, id, "dismiss", transitionBody{})
+}
+
+/** Schedules a completed, dismissed or failed reminder again after d */
+func (c *Client) Reopen(ctx context.Context, id int, d time.Duration) (Reminder, error) {
+	return c.transition(ctx, id, "reopen", transitionBody{Duration: d})
+}
+
+/** Lists every notification attempt of a reminder */
+func (c *Client) Deliveries(ctx context.Context, id int) ([]DeliveryAttempt, error) {
+	var res []DeliveryAttempt
+	err := c.do(ctx, http.MethodGet, reminderPath(id)+"/deliveries", nil, &res, http.StatusOK)
+	return res, err
+}
+
+func (c *Client) transition(ctx context.Context, id int, action string, body transitionBody) (Reminder, error) {
+	var res Reminder
+	err := c.do(ctx, http.MethodPost, reminderPath(id)+"/"+action, body, &res, http.StatusOK)
+	return res, err
+}
+
+func reminderPath(id int) string {
+	return "/reminders/" + strconv.Itoa(id)
+}
+
+func joinIDs(ids []int) string {
+	res := make([]string, len(ids))
+	for i, id := range ids {
+		res[i] = strconv.Itoa(id)
+	}
+	return strings.Join(res, ",")
+}
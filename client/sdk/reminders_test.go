@@ -0,0 +1,104 @@
+package sdk
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"app-pointment/server/models"
+)
+
+/** A reminder with every field set, so a field missing on either side shows up */
+func fullReminder() models.Reminder {
+	at := time.Date(2026, 10, 19, 15, 4, 5, 0, time.UTC)
+	return models.Reminder{
+		ID:               7,
+		Owner:            "alice",
+		Title:            "Standup",
+		Message:          "Join the call",
+		Template:         "meeting",
+		Variables:        map[string]string{"Room": "B"},
+		Duration:         10 * time.Minute,
+		Status:           models.StatusDeferred,
+		DeferredUntil:    &at,
+		IgnoreQuietHours: true,
+		Attempts:         2,
+		LastError:        "notifier is not available",
+		RetryPolicy:      &models.RetryPolicy{MaxAttempts: 3, InitialDelay: time.Second, MaxDelay: time.Minute, Multiplier: 2, Jitter: 0.1},
+		Channels:         []string{"desktop", "email"},
+		Deliveries: map[string]models.ChannelDelivery{
+			"desktop": {Status: models.DeliveryAwaiting, DeliveryID: "d1", Error: "e", Snooze: time.Minute, At: at},
+		},
+		EscalationPolicy: "oncall",
+		EscalationLevel:  1,
+		Unacknowledged:   3,
+		FirstNotifiedAt:  &at,
+		History:          []models.HistoryEntry{{At: at, Type: models.HistoryEscalation, Level: 1, Channels: []string{"email"}, Reason: "3 attempts"}},
+		CreatedAt:        at,
+		ModifiedAt:       at,
+	}
+}
+
+func TestTypesMatchTheServer(t *testing.T) {
+	at := time.Date(2026, 10, 19, 15, 4, 5, 0, time.UTC)
+	r := fullReminder()
+	tests := []struct {
+		name   string
+		server interface{}
+		sdk    interface{}
+	}{
+		{"reminder", r, &Reminder{}},
+		{"event", models.Event{ID: 3, Type: models.EventReminderFired, Reminder: r, At: at}, &Event{}},
+		{"delivery attempt", models.DeliveryAttempt{
+			ReminderID: 7, Channel: "desktop", Attempt: 2, Outcome: models.DeliveryDeferred,
+			ActivationType: "replied", ActivationValue: "5m", DeliveryID: "d1", Error: "e",
+			Latency: time.Second, DeferredUntil: &at, At: at,
+		}, &DeliveryAttempt{}},
+		{"batch response", models.BatchResponse{Mode: models.BatchAtomic, Succeeded: 1, Failed: 1, Results: []models.BatchResult{
+			{Index: 0, Op: models.BatchCreate, ID: 7, Status: models.BatchStatusOK, Reminder: &r},
+			{Index: 1, Op: models.BatchDelete, ID: 8, Status: models.BatchStatusFailed, Error: &models.HTTPError{Type: "resource_not_found_error", Message: "not found"}},
+		}}, &BatchResponse{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want, err := json.Marshal(tt.server)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := json.Unmarshal(want, tt.sdk); err != nil {
+				t.Fatal(err)
+			}
+			got, err := json.Marshal(tt.sdk)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != string(want) {
+				t.Errorf("sdk encodes\n%s\nserver encodes\n%s", got, want)
+			}
+		})
+	}
+}
+
+func TestConstantsMatchTheServer(t *testing.T) {
+	tests := []struct {
+		name        string
+		sdk, server interface{}
+	}{
+		{"statuses", Statuses, models.Statuses},
+		{"event types", EventTypes, models.EventTypes},
+		{"batch modes", BatchModes, models.BatchModes},
+		{"batch limit", MaxBatchOperations, models.MaxBatchOperations},
+		{"delivery statuses",
+			[]string{DeliveryDelivered, DeliveryAwaiting, DeliverySnoozed, DeliveryFailed, DeliveryDeferred, DeliveryUnanswered, DeliveryCircuitOpen},
+			[]string{models.DeliveryDelivered, models.DeliveryAwaiting, models.DeliverySnoozed, models.DeliveryFailed, models.DeliveryDeferred, models.DeliveryUnanswered, models.DeliveryCircuitOpen},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !reflect.DeepEqual(tt.sdk, tt.server) {
+				t.Errorf("sdk has %v, server has %v", tt.sdk, tt.server)
+			}
+		})
+	}
+}
@@ -1,11 +1,15 @@
 package client
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 	"time"
+
+	"app-pointment/client/sdk"
 )
 
 /** Values passed from CLI */
@@ -15,210 +19,421 @@ func (list *idsFlag) String() string {
 	return strings.Join(*list, ",")
 }
 
+/** Converts the values to reminder ids */
+func (list idsFlag) ints() ([]int, error) {
+	res := make([]int, 0, len(list))
+	for _, v := range list {
+		for _, part := range strings.Split(v, ",") {
+			id, err := strconv.Atoi(strings.TrimSpace(part))
+			if err != nil {
+				return nil, usageErrorf("invalid reminder id %q", part)
+			}
+			res = append(res, id)
+		}
+	}
+	return res, nil
+}
+
+/** Reminder ids of a command which needs at least one */
+func (list idsFlag) required() ([]int, error) {
+	ids, err := list.ints()
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, usageErrorf("missing reminder id, use --id")
+	}
+	return ids, nil
+}
+
 /** Override the value ot the list */
 func (list *idsFlag) Set(v string) error {
 	*list = append(*list, v)
 	return nil
 }
 
-/** HTTP client for communicating with the Backend API */
-type BackendHTTPClient interface {
-	Create(title, message string, duration time.Duration) ([]byte, error)
-	Edit(id string, title string, message string, duration time.Duration) ([]byte, error)
-	List(ids []string) ([]byte, error)
-	Delete(ids []string) error
-	Healthy(host string) bool
+/** Settings of the CLI resolved from the global flags */
+type Config struct {
+	BackendURI string
+	APIKey     string
+	Output     string
+	Template   string
+	TimeZone   string
+	Options    []sdk.Option
+	/** Global flags, shown in the help and completed by the shells */
+	Globals *flag.FlagSet
+	/** Command line arguments following the global flags */
+	Args []string
 }
 
 /** CLI command switch */
 type Switch struct {
-	client        BackendHTTPClient
+	client        *sdk.Client
+	options       []sdk.Option
 	backendAPIURI string
-	commands      map[string]func() func(string) error
+	args          []string
+	output        Printer
+	globals       *flag.FlagSet
+	root          *command
 }
 
 /** Creates a new instance of command Switch */
-func NewSwitch(uri string) Switch { //constructor
-	httpClient := NewHTTPClient(uri)
-	s := Switch{client: httpClient, backendAPIURI: uri}
-	s.commands = map[string]func() func(string) error{
-		"create": s.create,
-		"edit":   s.edit,
-		"list":   s.list,
-		"delete": s.delete,
-		"health": s.health,
+func NewSwitch(cfg Config) (Switch, error) { //constructor
+	loc := time.Local
+	if cfg.TimeZone != "" {
+		l, err := time.LoadLocation(cfg.TimeZone)
+		if err != nil {
+			return Switch{}, fmt.Errorf("invalid time zone %q: %v", cfg.TimeZone, err)
+		}
+		loc = l
 	}
-	return s
+	output, err := NewPrinter(cfg.Output, cfg.Template, loc)
+	if err != nil {
+		return Switch{}, err
+	}
+	globals := cfg.Globals
+	if globals == nil {
+		globals = flag.NewFlagSet(progName(), flag.ContinueOnError)
+	}
+	opts := append([]sdk.Option{sdk.WithAPIKey(cfg.APIKey)}, cfg.Options...)
+	s := Switch{
+		client:        sdk.New(cfg.BackendURI, opts...),
+		options:       opts,
+		backendAPIURI: cfg.BackendURI,
+		args:          cfg.Args,
+		output:        output,
+		globals:       globals,
+	}
+	s.root = s.commands()
+	return s, nil
 }
 
-/** Executes the given command by args */
+/** Executes the command selected by the args */
 func (s Switch) Switch() error {
-	cmdName := os.Args[1]
-	cmd, ok := s.commands[cmdName]
-	if !ok {
-		return fmt.Errorf("Invalid command: '%s\n' ", cmdName)
+	if len(s.args) == 0 {
+		s.usage(os.Stderr, []*command{s.root}, nil)
+		return usageErrorf("missing command")
 	}
-	return cmd()(cmdName)
+	return s.run([]*command{s.root}, s.args)
 }
 
+/** Prints the commands and the global flags */
 func (s Switch) Help() {
-	var help string
-	for name := range s.commands {
-		help += name + "\t --help\n"
-	}
-	fmt.Printf("Usage of: %s:\n <command> [<args>\n%s]", os.Args[0], help)
-
-}
-
-/** Parse command flags */
-func (s Switch) parseCmd(cmd *flag.FlagSet) error {
-	err := cmd.Parse(os.Args[2:])
-	if err != nil {
-		return wrapError("Could not parse '"+cmd.Name()+"' command flags.", err)
-	}
-	return nil
+	s.usage(os.Stdout, []*command{s.root}, nil)
 }
 
 /** Create new reminder */
-func (s Switch) create() func(string) error {
-	return func(cmd string) error {
-		createCmd := flag.NewFlagSet(cmd, flag.ExitOnError)
-		t, m, d := s.reminderFlags(createCmd)
-		if err := s.checkArgs(3); err != nil {
-			return err
+func (s Switch) create(f *flag.FlagSet) func() error {
+	channels := idsFlag{}
+	vars := idsFlag{}
+	f.Var(&channels, "channel", "Notification channel, repeatable (server defaults when omitted).")
+	template := f.String("template", "", "Server template rendering the title and message.")
+	f.Var(&vars, "var", "Template variable as name=value, repeatable.")
+	t, m, d, at := s.reminderFlags(f)
+	return func() error {
+		if *template == "" && (*t == "" || *m == "") {
+			return usageErrorf("create expects a --title and a --message or a --template")
 		}
-		if err := s.parseCmd(createCmd); err != nil {
+		if err := s.schedule(d, *at); err != nil {
 			return err
 		}
 
-		res, err := s.client.Create(*t, *m, *d)
+		req := sdk.CreateRequest{
+			Title:    *t,
+			Message:  *m,
+			Duration: *d,
+			Channels: channels,
+		}
+		if *template != "" {
+			variables, err := parseVars(vars)
+			if err != nil {
+				return err
+			}
+			req = sdk.CreateRequest{
+				Template:  *template,
+				Variables: variables,
+				Duration:  *d,
+				Channels:  channels,
+			}
+		}
+		res, err := s.client.Create(context.Background(), req)
 		if err != nil {
 			return wrapError("Could not create reminder.", err)
 		}
 
-		fmt.Printf("Reminder created successfuly:\n%s.", string(res))
-		return nil
+		s.output.Message("Reminder created successfuly.")
+		return s.output.Print(res)
 	}
 }
 
 /** Edit a reminder */
-func (s Switch) edit() func(string) error {
-	return func(cmd string) error {
-		ids := idsFlag{}
-		editCmd := flag.NewFlagSet(cmd, flag.ExitOnError)
-		editCmd.Var(&ids, "id", "The ID of the reminder to edit.")
-		t, m, d := s.reminderFlags(editCmd)
-		if err := s.checkArgs(2); err != nil {
+func (s Switch) edit(f *flag.FlagSet) func() error {
+	ids := idsFlag{}
+	channels := idsFlag{}
+	f.Var(&ids, "id", "The ID of the reminder to edit.")
+	f.Var(&channels, "channel", "Notification channel, repeatable.")
+	t, m, d, at := s.reminderFlags(f)
+	return func() error {
+		reminderIDs, err := ids.required()
+		if err != nil {
 			return err
 		}
-		if err := s.parseCmd(editCmd); err != nil {
+		if err := s.schedule(d, *at); err != nil {
 			return err
 		}
-
-		lastID := ids[len(ids)-1]
-		res, err := s.client.Edit(lastID, *t, *m, *d)
+		lastID := reminderIDs[len(reminderIDs)-1]
+		res, err := s.client.Edit(context.Background(), lastID, sdk.EditRequest{
+			Title:    *t,
+			Message:  *m,
+			Duration: *d,
+			Channels: channels,
+		})
 		if err != nil {
 			return wrapError("Could not edit reminder.", err)
 		}
 
-		fmt.Printf("Reminder edited successfuly:\n%s.", string(res))
-		return nil
+		s.output.Message("Reminder edited successfuly.")
+		return s.output.Print(res)
 	}
 }
 
 /** Get aall reminders */
-func (s Switch) list() func(string) error {
-	return func(cmd string) error {
-		ids := idsFlag{}
-		listCmd := flag.NewFlagSet(cmd, flag.ExitOnError)
-		listCmd.Var(&ids, "id", "The ID of the reminder to list.")
-
-		if err := s.checkArgs(1); err != nil {
+func (s Switch) list(f *flag.FlagSet) func() error {
+	ids := idsFlag{}
+	f.Var(&ids, "id", "The ID of the reminder to list, repeatable or comma separated.")
+	return func() error {
+		reminderIDs, err := ids.required()
+		if err != nil {
 			return err
 		}
-		if err := s.parseCmd(listCmd); err != nil {
+		res, err := s.client.List(context.Background(), reminderIDs...)
+		if err != nil {
+			return wrapError("Could not list reminder.", err)
+		}
+
+		return s.output.Print(res)
+	}
+}
+
+/** Delete a reminder */
+func (s Switch) delete(f *flag.FlagSet) func() error {
+	ids := idsFlag{}
+	f.Var(&ids, "id", "The ID of the reminder to delete, repeatable or comma separated.")
+	return func() error {
+		reminderIDs, err := ids.required()
+		if err != nil {
 			return err
 		}
+		err = s.client.Delete(context.Background(), reminderIDs...)
+		if err != nil {
+			return wrapError("Could not delete reminder.", err)
+		}
 
-		res, err := s.client.List(ids)
+		s.output.Message("Reminder deleted successfuly.")
+		return s.output.Print(reminderIDs)
+	}
+}
+
+/** List reminders which exhausted their retries */
+func (s Switch) deadLetter(f *flag.FlagSet) func() error {
+	return func() error {
+		res, err := s.client.DeadLetter(context.Background())
 		if err != nil {
-			return wrapError("Could not list reminder.", err)
+			return wrapError("Could not list dead-letter reminders.", err)
 		}
 
-		fmt.Printf("Appoitments listed successfuly:\n%s.", string(res))
-		return nil
+		return s.output.Print(res)
 	}
 }
 
-/** Delete a reminder */
-func (s Switch) delete() func(string) error {
-	return func(cmd string) error {
-		ids := idsFlag{}
-		deleteCmd := flag.NewFlagSet(cmd, flag.ExitOnError)
-		deleteCmd.Var(&ids, "id", "The ID of the reminder to delete.")
+/** Retry a reminder from the dead-letter list */
+func (s Switch) requeue(f *flag.FlagSet) func() error {
+	ids := idsFlag{}
+	f.Var(&ids, "id", "The ID of the reminder to requeue.")
+	return func() error {
+		reminderIDs, err := ids.required()
+		if err != nil {
+			return err
+		}
+		var res []sdk.Reminder
+		for _, id := range reminderIDs {
+			r, err := s.client.Requeue(context.Background(), id)
+			if err != nil {
+				return wrapError("Could not requeue reminder.", err)
+			}
+			res = append(res, r)
+		}
+		s.output.Message("Reminder requeued successfuly.")
+		return s.output.Print(res)
+	}
+}
 
-		if err := s.checkArgs(1); err != nil {
+/** Postpone a pending reminder */
+func (s Switch) snooze(f *flag.FlagSet) func() error {
+	ids := idsFlag{}
+	var d time.Duration
+	f.Var(&ids, "id", "The ID of the reminder to snooze.")
+	f.DurationVar(&d, "for", 10*time.Minute, "How long to snooze the reminder.")
+	until := f.String("until", "", "When to notify the reminder again, e.g. \"tomorrow 9am\" (overrides --for).")
+	return func() error {
+		reminderIDs, err := ids.required()
+		if err != nil {
 			return err
 		}
-		if err := s.parseCmd(deleteCmd); err != nil {
+		if err := s.schedule(&d, *until); err != nil {
 			return err
 		}
+		var res []sdk.Reminder
+		for _, id := range reminderIDs {
+			r, err := s.client.Snooze(context.Background(), id, d)
+			if err != nil {
+				return wrapError("Could not snooze reminder.", err)
+			}
+			res = append(res, r)
+		}
+		s.output.Message("Reminder snoozed successfuly.")
+		return s.output.Print(res)
+	}
+}
 
-		err := s.client.Delete(ids)
+/** Mark a reminder as completed */
+func (s Switch) done(f *flag.FlagSet) func() error {
+	ids := idsFlag{}
+	f.Var(&ids, "id", "The ID of the reminder to complete.")
+	return func() error {
+		reminderIDs, err := ids.required()
 		if err != nil {
-			return wrapError("Could not delete reminder.", err)
+			return err
 		}
+		var res []sdk.Reminder
+		for _, id := range reminderIDs {
+			r, err := s.client.Complete(context.Background(), id)
+			if err != nil {
+				return wrapError("Could not complete reminder.", err)
+			}
+			res = append(res, r)
+		}
+		s.output.Message("Reminder completed successfuly.")
+		return s.output.Print(res)
+	}
+}
 
-		fmt.Printf("Reminder deleted successfuly:\n%v\n.", ids)
-		return nil
+/** Stop a reminder without completing it */
+func (s Switch) dismiss(f *flag.FlagSet) func() error {
+	ids := idsFlag{}
+	f.Var(&ids, "id", "The ID of the reminder to dismiss.")
+	return func() error {
+		reminderIDs, err := ids.required()
+		if err != nil {
+			return err
+		}
+		var res []sdk.Reminder
+		for _, id := range reminderIDs {
+			r, err := s.client.Dismiss(context.Background(), id)
+			if err != nil {
+				return wrapError("Could not dismiss reminder.", err)
+			}
+			res = append(res, r)
+		}
+		s.output.Message("Reminder dismissed successfuly.")
+		return s.output.Print(res)
+	}
+}
+
+/** Schedule a completed, dismissed or failed reminder again */
+func (s Switch) reopen(f *flag.FlagSet) func() error {
+	ids := idsFlag{}
+	var d time.Duration
+	f.Var(&ids, "id", "The ID of the reminder to reopen.")
+	f.DurationVar(&d, "in", 0, "When to notify the reminder again (right away when omitted).")
+	at := f.String("at", "", "When to notify the reminder again, e.g. \"friday 14:00\" (overrides --in).")
+	return func() error {
+		reminderIDs, err := ids.required()
+		if err != nil {
+			return err
+		}
+		if err := s.schedule(&d, *at); err != nil {
+			return err
+		}
+		var res []sdk.Reminder
+		for _, id := range reminderIDs {
+			r, err := s.client.Reopen(context.Background(), id, d)
+			if err != nil {
+				return wrapError("Could not reopen reminder.", err)
+			}
+			res = append(res, r)
+		}
+		s.output.Message("Reminder reopened successfuly.")
+		return s.output.Print(res)
 	}
 }
 
 /** Ping the host */
-func (s Switch) health() func(string) error {
-	return func(cmd string) error {
-		var host string
-		healthCmd := flag.NewFlagSet(cmd, flag.ExitOnError)
-		healthCmd.StringVar(&host, "host", s.backendAPIURI, "Host to ping for helath.")
-		if err := s.parseCmd(healthCmd); err != nil {
+func (s Switch) health(f *flag.FlagSet) func() error {
+	var host string
+	f.StringVar(&host, "host", s.backendAPIURI, "Host to ping for helath.")
+	return func() error {
+		client := s.client
+		if host != s.backendAPIURI {
+			client = sdk.New(host, s.options...)
+		}
+		report, err := client.Health(context.Background())
+		if err != nil {
+			s.output.Message("Host %s is down.", host)
 			return err
 		}
-		if !s.client.Healthy(host) {
-			fmt.Printf("Host %s is down.\n", host)
+		if report.Healthy() {
+			s.output.Message("Host %s is up and running.", host)
 		} else {
-			fmt.Printf("Host %s is up and running.\n", host)
-
+			s.output.Message("Host %s is unhealthy.", host)
+		}
+		if err := s.output.Print(report); err != nil {
+			return wrapError("could not print health report", err)
+		}
+		if !report.Healthy() {
+			return fmt.Errorf("host %s is unhealthy", host)
 		}
 		return nil
 	}
 }
 
 /** A specific flags */
-func (s Switch) reminderFlags(f *flag.FlagSet) (*string, *string, *time.Duration) {
-	t, m, d := "", "", time.Duration(0)
+func (s Switch) reminderFlags(f *flag.FlagSet) (*string, *string, *time.Duration, *string) {
+	t, m, d, at := "", "", time.Duration(0), ""
 	f.StringVar(&t, "title", "", "Reminder title.")
 	f.StringVar(&t, "t", "", "Reminder title.")
 	f.StringVar(&m, "message", "", "Reminder message.")
 	f.StringVar(&m, "m", "", "Reminder message.")
 	f.DurationVar(&d, "duration", 0, "Reminder duration.")
 	f.DurationVar(&d, "d", 0, "Reminder duration.")
-	return &t, &m, &d
+	f.StringVar(&at, "at", "", "When the reminder fires, e.g. \"tomorrow 9am\", \"in 2 hours\" or an RFC 3339 time (overrides the duration).")
+	return &t, &m, &d, &at
 }
 
-/** Checks if the number of passed in args is greater or equal to min args */
-func (s Switch) checkArgs(minArgs int) error {
-	if len(os.Args) == 3 && os.Args[2] == "--help" {
+/** Resolves a time given by the user to the duration from now and shows when it fires */
+func (s Switch) schedule(d *time.Duration, at string) error {
+	if at == "" {
 		return nil
 	}
-	if len(os.Args)-2 < minArgs {
-		fmt.Printf("Incorrect use of %s\n%s %s --help\n",
-			os.Args[1],
-			os.Args[0],
-			os.Args[1])
-		return fmt.Errorf("%s expects atleast %d args, %d provided",
-			os.Args[1],
-			minArgs,
-			len(os.Args)-2)
+	now := time.Now()
+	t, err := ParseTime(at, now, s.output.location)
+	if err != nil {
+		return err
 	}
+	*d = t.Sub(now)
+	s.output.Message("Scheduled for %s (%s).", t.In(s.output.location).Format("Mon Jan 02 15:04 MST"), relative(*d))
 	return nil
 }
+
+/** Parses name=value template variables */
+func parseVars(vars []string) (map[string]string, error) {
+	variables := make(map[string]string, len(vars))
+	for _, v := range vars {
+		parts := strings.SplitN(v, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("invalid variable %q, expected name=value", v)
+		}
+		variables[parts[0]] = parts[1]
+	}
+	return variables, nil
+}
@@ -0,0 +1,142 @@
+package client
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+/** Keys decoded from terminal input, printable characters are passed as they are */
+const (
+	keyUp        = "up"
+	keyDown      = "down"
+	keyPageUp    = "pgup"
+	keyPageDown  = "pgdn"
+	keyHome      = "home"
+	keyEnd       = "end"
+	keyEnter     = "enter"
+	keyEscape    = "esc"
+	keyBackspace = "backspace"
+	keyClearLine = "ctrl-u"
+	keyInterrupt = "ctrl-c"
+)
+
+/** Escape sequences of the special keys */
+var escapeKeys = map[string]string{
+	"\x1b[A":  keyUp,
+	"\x1b[B":  keyDown,
+	"\x1bOA":  keyUp,
+	"\x1bOB":  keyDown,
+	"\x1b[5~": keyPageUp,
+	"\x1b[6~": keyPageDown,
+	"\x1b[H":  keyHome,
+	"\x1b[F":  keyEnd,
+	"\x1b[1~": keyHome,
+	"\x1b[4~": keyEnd,
+}
+
+/** Terminal switched to raw mode drawing on the alternate screen, restored by Close */
+type terminal struct {
+	out   *bufio.Writer
+	state string
+}
+
+/** Switches the controlling terminal to raw mode using stty */
+func openTerminal() (*terminal, error) {
+	state, err := stty("-g")
+	if err != nil {
+		return nil, fmt.Errorf("an interactive terminal is required: %v", err)
+	}
+	if _, err := stty("raw", "-echo"); err != nil {
+		return nil, wrapError("Could not switch the terminal to raw mode.", err)
+	}
+	t := &terminal{out: bufio.NewWriter(os.Stdout), state: strings.TrimSpace(state)}
+	t.out.WriteString("\x1b[?1049h\x1b[?25l")
+	return t, t.out.Flush()
+}
+
+/** Leaves the alternate screen and restores the terminal settings */
+func (t *terminal) Close() error {
+	t.out.WriteString("\x1b[?25h\x1b[?1049l")
+	t.out.Flush()
+	_, err := stty(t.state)
+	return err
+}
+
+/** Rows and columns of the terminal, 24x80 when unknown */
+func (t *terminal) size() (int, int) {
+	out, err := stty("size")
+	if err != nil {
+		return 24, 80
+	}
+	parts := strings.Fields(out)
+	if len(parts) != 2 {
+		return 24, 80
+	}
+	rows, err1 := strconv.Atoi(parts[0])
+	cols, err2 := strconv.Atoi(parts[1])
+	if err1 != nil || err2 != nil || rows == 0 || cols == 0 {
+		return 24, 80
+	}
+	return rows, cols
+}
+
+/** Reads keys from stdin until it is closed */
+func readKeys(keys chan<- string) {
+	buf := make([]byte, 64)
+	for {
+		n, err := os.Stdin.Read(buf)
+		if err != nil {
+			close(keys)
+			return
+		}
+		in := string(buf[:n])
+		for in != "" {
+			var k string
+			k, in = decodeKey(in)
+			keys <- k
+		}
+	}
+}
+
+/** Decodes the first key of the input and returns the remaining input */
+func decodeKey(in string) (string, string) {
+	if in[0] == 0x1b {
+		for seq, k := range escapeKeys {
+			if strings.HasPrefix(in, seq) {
+				return k, in[len(seq):]
+			}
+		}
+		// an unknown sequence is dropped, a lone escape cancels
+		if len(in) > 1 && (in[1] == '[' || in[1] == 'O') {
+			return "", ""
+		}
+		return keyEscape, in[1:]
+	}
+	switch in[0] {
+	case '\r', '\n':
+		return keyEnter, in[1:]
+	case 0x7f, 0x08:
+		return keyBackspace, in[1:]
+	case 0x15:
+		return keyClearLine, in[1:]
+	case 0x03, 0x04:
+		return keyInterrupt, in[1:]
+	}
+	r, size := utf8.DecodeRuneInString(in)
+	if r < ' ' {
+		return "", in[size:]
+	}
+	return string(r), in[size:]
+}
+
+func stty(args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = os.Stdin
+	out, err := cmd.Output()
+	return string(out), err
+}
@@ -0,0 +1,480 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"app-pointment/client/sdk"
+)
+
+/** Help line listing the key bindings */
+const tuiKeys = "↑/↓ move  n new  e edit  s snooze  c complete  d delete  a all  r refresh  q quit"
+
+/** Line editor shown at the bottom of the screen */
+type prompt struct {
+	label   string
+	value   []rune
+	confirm bool
+	done    func(string)
+}
+
+/** State of the interactive terminal UI */
+type tui struct {
+	s         Switch
+	term      *terminal
+	reminders map[int]sdk.Reminder
+	rows      []sdk.Reminder
+	selected  int
+	cursor    int
+	offset    int
+	all       bool
+	live      bool
+	status    string
+	failed    bool
+	prompt    *prompt
+}
+
+/** Browse and manage reminders in a full-screen terminal interface */
+func (s Switch) tui(f *flag.FlagSet) func() error {
+	all := f.Bool("all", false, "Also show completed, dismissed and failed reminders.")
+	return func() error {
+		t := &tui{s: s, reminders: map[int]sdk.Reminder{}, all: *all, live: true}
+		if err := t.load(); err != nil {
+			return wrapError("Could not load reminders.", err)
+		}
+		term, err := openTerminal()
+		if err != nil {
+			return err
+		}
+		defer term.Close()
+		t.term = term
+		return t.run()
+	}
+}
+
+/** Redraws on every key, event and second until the user quits */
+func (t *tui) run() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	keys := make(chan string)
+	events := make(chan sdk.Event, 64)
+	streams := make(chan error)
+	go readKeys(keys)
+	go t.watch(ctx, events, streams)
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	t.setStatus(fmt.Sprintf("Loaded %d reminders.", len(t.reminders)), nil)
+	for {
+		t.draw()
+		select {
+		case k, ok := <-keys:
+			if !ok || t.handleKey(k) {
+				return nil
+			}
+		case e := <-events:
+			t.live = true
+			t.apply(e)
+		case err := <-streams:
+			t.live = err == nil
+			if err != nil {
+				t.setStatus("", wrapError("Event stream closed, reconnecting.", err))
+			}
+		case <-ticker.C:
+		}
+	}
+}
+
+/** Keeps the reminders up to date from the event stream, reconnecting when it closes */
+func (t *tui) watch(ctx context.Context, events chan<- sdk.Event, streams chan<- error) {
+	var lastID int64
+	for {
+		id, err := t.s.client.Watch(ctx, lastID, func(e sdk.Event) {
+			select {
+			case events <- e:
+			case <-ctx.Done():
+			}
+		})
+		if ctx.Err() != nil {
+			return
+		}
+		if err == nil {
+			err = errors.New("stream ended")
+		}
+		lastID = id
+		select {
+		case streams <- err:
+		case <-ctx.Done():
+			return
+		}
+		time.Sleep(time.Second)
+	}
+}
+
+/** Fetches every accessible reminder */
+func (t *tui) load() error {
+	reminders, err := t.s.client.ListAll(context.Background())
+	if err != nil {
+		return err
+	}
+	t.reminders = make(map[int]sdk.Reminder, len(reminders))
+	for _, r := range reminders {
+		t.reminders[r.ID] = r
+	}
+	t.sort()
+	return nil
+}
+
+/** Applies a reminder event to the list */
+func (t *tui) apply(e sdk.Event) {
+	if e.Type == sdk.EventReminderDeleted {
+		delete(t.reminders, e.Reminder.ID)
+	} else {
+		t.reminders[e.Reminder.ID] = e.Reminder
+	}
+	t.sort()
+}
+
+/** Orders the shown reminders, pending ones by due time first, then the finished ones latest first */
+func (t *tui) sort() {
+	t.rows = t.rows[:0]
+	for _, r := range t.reminders {
+		if t.all || !finished(r) {
+			t.rows = append(t.rows, r)
+		}
+	}
+	sort.Slice(t.rows, func(i, j int) bool {
+		a, b := t.rows[i], t.rows[j]
+		if finished(a) != finished(b) {
+			return !finished(a)
+		}
+		if finished(a) {
+			return a.ModifiedAt.After(b.ModifiedAt)
+		}
+		return a.ModifiedAt.Add(a.Duration).Before(b.ModifiedAt.Add(b.Duration))
+	})
+	// the cursor stays on its row when the selected reminder is gone
+	for i, r := range t.rows {
+		if r.ID == t.selected {
+			t.cursor = i
+		}
+	}
+	t.move(0)
+}
+
+func finished(r sdk.Reminder) bool {
+	switch r.Status {
+	case sdk.StatusCompleted, sdk.StatusDismissed, sdk.StatusFailed:
+		return true
+	}
+	return false
+}
+
+/** Moves the cursor by delta rows */
+func (t *tui) move(delta int) {
+	t.cursor += delta
+	if t.cursor >= len(t.rows) {
+		t.cursor = len(t.rows) - 1
+	}
+	if t.cursor < 0 {
+		t.cursor = 0
+	}
+	t.selected = 0
+	if len(t.rows) > 0 {
+		t.selected = t.rows[t.cursor].ID
+	}
+}
+
+/** Handles a key press, returns true when the user quits */
+func (t *tui) handleKey(k string) bool {
+	if k == keyInterrupt {
+		return true
+	}
+	if t.prompt != nil {
+		t.edit(k)
+		return false
+	}
+	rows, _ := t.term.size()
+	page := rows - 5
+	switch k {
+	case "q":
+		return true
+	case keyUp, "k":
+		t.move(-1)
+	case keyDown, "j":
+		t.move(1)
+	case keyPageUp:
+		t.move(-page)
+	case keyPageDown:
+		t.move(page)
+	case keyHome, "g":
+		t.move(-len(t.rows))
+	case keyEnd, "G":
+		t.move(len(t.rows))
+	case "a":
+		t.all = !t.all
+		t.sort()
+	case "r":
+		err := t.load()
+		t.setStatus(fmt.Sprintf("Loaded %d reminders.", len(t.reminders)), err)
+	case "n":
+		t.create()
+	case "e", "s", "c", "d":
+		r, ok := t.current()
+		if !ok {
+			t.setStatus("", errors.New("no reminder selected"))
+			return false
+		}
+		switch k {
+		case "e":
+			t.editReminder(r)
+		case "s":
+			t.snooze(r)
+		case "c":
+			res, err := t.s.client.Complete(context.Background(), r.ID)
+			t.update(res, fmt.Sprintf("Reminder %d completed.", r.ID), err)
+		case "d":
+			t.ask(fmt.Sprintf("Delete reminder %d? (y/n)", r.ID), "", true, func(v string) {
+				if v != "y" && v != "Y" {
+					t.setStatus("Cancelled.", nil)
+					return
+				}
+				err := t.s.client.Delete(context.Background(), r.ID)
+				if err == nil {
+					delete(t.reminders, r.ID)
+					t.sort()
+				}
+				t.setStatus(fmt.Sprintf("Reminder %d deleted.", r.ID), err)
+			})
+		}
+	}
+	return false
+}
+
+func (t *tui) current() (sdk.Reminder, bool) {
+	if len(t.rows) == 0 {
+		return sdk.Reminder{}, false
+	}
+	return t.rows[t.cursor], true
+}
+
+/** Asks for the title, message and time of a new reminder */
+func (t *tui) create() {
+	t.ask("Title", "", false, func(title string) {
+		t.ask("Message", "", false, func(message string) {
+			t.ask("When", "in 10 minutes", false, func(when string) {
+				d, at, err := t.when(when)
+				if err != nil {
+					t.setStatus("", err)
+					return
+				}
+				res, err := t.s.client.Create(context.Background(), sdk.CreateRequest{
+					Title:    title,
+					Message:  message,
+					Duration: d,
+				})
+				t.update(res, fmt.Sprintf("Reminder %d scheduled for %s.", res.ID, at), err)
+			})
+		})
+	})
+}
+
+/** Asks for the new title, message and time of a reminder, an empty time keeps the schedule */
+func (t *tui) editReminder(r sdk.Reminder) {
+	t.ask("Title", r.Title, false, func(title string) {
+		t.ask("Message", r.Message, false, func(message string) {
+			t.ask("When (empty keeps it)", "", false, func(when string) {
+				req := sdk.EditRequest{Title: title, Message: message}
+				msg := fmt.Sprintf("Reminder %d edited.", r.ID)
+				if when != "" {
+					d, at, err := t.when(when)
+					if err != nil {
+						t.setStatus("", err)
+						return
+					}
+					req.Duration = d
+					msg = fmt.Sprintf("Reminder %d rescheduled for %s.", r.ID, at)
+				}
+				res, err := t.s.client.Edit(context.Background(), r.ID, req)
+				t.update(res, msg, err)
+			})
+		})
+	})
+}
+
+func (t *tui) snooze(r sdk.Reminder) {
+	t.ask("Snooze until", "in 10 minutes", false, func(when string) {
+		d, at, err := t.when(when)
+		if err != nil {
+			t.setStatus("", err)
+			return
+		}
+		res, err := t.s.client.Snooze(context.Background(), r.ID, d)
+		t.update(res, fmt.Sprintf("Reminder %d snoozed until %s.", r.ID, at), err)
+	})
+}
+
+/** Resolves a time typed by the user to the duration from now and its readable form */
+func (t *tui) when(v string) (time.Duration, string, error) {
+	now := time.Now()
+	at, err := ParseTime(v, now, t.s.output.location)
+	if err != nil {
+		return 0, "", err
+	}
+	return at.Sub(now), at.In(t.s.output.location).Format("Mon Jan 02 15:04"), nil
+}
+
+/** Shows the result of an action, the event stream usually brings the same change */
+func (t *tui) update(r sdk.Reminder, msg string, err error) {
+	if err == nil {
+		t.reminders[r.ID] = r
+		t.selected = r.ID
+		t.sort()
+	}
+	t.setStatus(msg, err)
+}
+
+func (t *tui) setStatus(msg string, err error) {
+	t.status, t.failed = msg, err != nil
+	if err != nil {
+		t.status = err.Error()
+	}
+}
+
+/** Opens the prompt, a confirmation resolves on the first key */
+func (t *tui) ask(label, value string, confirm bool, done func(string)) {
+	t.prompt = &prompt{label: label, value: []rune(value), confirm: confirm, done: done}
+}
+
+/** Edits the prompt value */
+func (t *tui) edit(k string) {
+	p := t.prompt
+	switch {
+	case k == keyEscape:
+		t.prompt = nil
+		t.setStatus("Cancelled.", nil)
+	case p.confirm:
+		t.prompt = nil
+		p.done(k)
+	case k == keyEnter:
+		t.prompt = nil
+		p.done(strings.TrimSpace(string(p.value)))
+	case k == keyBackspace:
+		if len(p.value) > 0 {
+			p.value = p.value[:len(p.value)-1]
+		}
+	case k == keyClearLine:
+		p.value = nil
+	case utf8.RuneCountInString(k) == 1:
+		p.value = append(p.value, []rune(k)...)
+	}
+}
+
+/** Redraws the whole screen */
+func (t *tui) draw() {
+	rows, cols := t.term.size()
+	height := rows - 5
+	if height < 1 {
+		height = 1
+	}
+	if t.cursor < t.offset {
+		t.offset = t.cursor
+	}
+	if t.cursor >= t.offset+height {
+		t.offset = t.cursor - height + 1
+	}
+	if t.offset > 0 && t.offset > len(t.rows)-height {
+		t.offset = len(t.rows) - height
+	}
+	if t.offset < 0 {
+		t.offset = 0
+	}
+
+	out := t.term.out
+	out.WriteString("\x1b[H")
+	state := "live"
+	if !t.live {
+		state = "offline"
+	}
+	header := fmt.Sprintf(" app-pointment  %s  %d reminders  %s", t.s.backendAPIURI, len(t.rows), state)
+	line(out, "\x1b[7m", fit(header, cols))
+	line(out, "\x1b[1m", fit(fmt.Sprintf(" %-5s %-10s %-26s %s", "ID", "STATUS", "DUE", "TITLE"), cols))
+
+	now := time.Now()
+	for i := t.offset; i < t.offset+height; i++ {
+		if i >= len(t.rows) {
+			line(out, "", "")
+			continue
+		}
+		r := t.rows[i]
+		title := r.Title
+		if title == "" && r.Template != "" {
+			title = "(template " + r.Template + ")"
+		}
+		text := fit(fmt.Sprintf(" %-5d %-10s %-26s %s", r.ID, r.Status, t.s.output.due(r, now), title), cols)
+		style := statusStyle(r.Status)
+		if i == t.cursor {
+			style = "\x1b[7m"
+		}
+		line(out, style, text)
+	}
+
+	var detail string
+	if r, ok := t.current(); ok {
+		detail = " " + r.Message
+		if r.LastError != "" {
+			detail += "  (last error: " + r.LastError + ")"
+		}
+	}
+	line(out, "\x1b[2m", fit(detail, cols))
+	if t.prompt != nil {
+		line(out, "", fit(" "+t.prompt.label+": "+string(t.prompt.value), cols))
+	} else if t.failed {
+		line(out, "\x1b[31m", fit(" "+t.status, cols))
+	} else {
+		line(out, "", fit(" "+t.status, cols))
+	}
+	out.WriteString("\x1b[2m" + fit(" "+tuiKeys, cols) + "\x1b[0m")
+	if t.prompt != nil {
+		col := utf8.RuneCountInString(t.prompt.label) + len(t.prompt.value) + 4
+		if col > cols {
+			col = cols
+		}
+		fmt.Fprintf(out, "\x1b[%d;%dH\x1b[?25h", rows-1, col)
+	} else {
+		out.WriteString("\x1b[?25l")
+	}
+	out.Flush()
+}
+
+func statusStyle(status string) string {
+	switch status {
+	case sdk.StatusFailed:
+		return "\x1b[31m"
+	case sdk.StatusDeferred:
+		return "\x1b[33m"
+	case sdk.StatusCompleted, sdk.StatusDismissed:
+		return "\x1b[2m"
+	}
+	return ""
+}
+
+/** Writes a styled line padded so a reversed style spans the whole row */
+func line(out *bufio.Writer, style, text string) {
+	out.WriteString(style + text + "\x1b[0m\r\n")
+}
+
+/** Cuts text to the terminal width */
+func fit(text string, cols int) string {
+	if utf8.RuneCountInString(text) <= cols {
+		return text + strings.Repeat(" ", cols-utf8.RuneCountInString(text))
+	}
+	return string([]rune(text)[:cols])
+}
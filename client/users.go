@@ -0,0 +1,94 @@
+package client
+
+import (
+	"flag"
+
+	"app-pointment/server/keyfile"
+	"app-pointment/server/models"
+)
+
+/** Manages the users stored next to the API keys */
+type UserManager interface {
+	Users() ([]models.User, error)
+	SetUser(user models.User) error
+}
+
+/** Create or update a user, only the given flags are changed */
+func (s Switch) setUser(f *flag.FlagSet) func() error {
+	path := keysFileFlag(f)
+	name := f.String("name", "", "The name of the user.")
+	notifier := f.String("notifier", "", "Notifier API URI receiving the user's reminders.")
+	quiet := f.String("quiet-hours", "", "Quiet hours, e.g. \"mon-fri 22:00-07:00; sat,sun 23:00-09:00\" (off removes them).")
+	quietTZ := f.String("quiet-tz", "", "Time zone of the quiet hours.")
+	quietMode := f.String("quiet-mode", models.QuietModeDefer, "What happens to reminders during quiet hours: defer or silent.")
+	quietChannel := f.String("quiet-channel", "", "Channel used to deliver reminders silently during quiet hours.")
+	return func() error {
+		if *name == "" {
+			return usageErrorf("missing user name, use --name")
+		}
+		set := map[string]bool{}
+		f.Visit(func(fl *flag.Flag) { set[fl.Name] = true })
+
+		manager := s.userManager(*path)
+		users, err := manager.Users()
+		if err != nil {
+			return wrapError("Could not load users.", err)
+		}
+		user := models.User{Name: *name}
+		for _, u := range users {
+			if u.Name == *name {
+				user = u
+			}
+		}
+		if set["notifier"] {
+			user.Notifier = *notifier
+		}
+		if set["quiet-hours"] {
+			user.QuietHours = nil
+			if *quiet != "off" {
+				windows, err := models.ParseQuietWindows(*quiet)
+				if err != nil {
+					return err
+				}
+				user.QuietHours = &models.QuietHours{Windows: windows}
+			}
+		}
+		if user.QuietHours != nil {
+			if set["quiet-tz"] {
+				user.QuietHours.TimeZone = *quietTZ
+			}
+			if set["quiet-mode"] {
+				user.QuietHours.Mode = *quietMode
+			}
+			if set["quiet-channel"] {
+				user.QuietHours.Channel = *quietChannel
+			}
+			if err := user.QuietHours.Validate(); err != nil {
+				return err
+			}
+		}
+		err = manager.SetUser(user)
+		if err != nil {
+			return wrapError("Could not save user.", err)
+		}
+		s.output.Message("User %s saved.", *name)
+		return nil
+	}
+}
+
+/** List all users */
+func (s Switch) listUsers(f *flag.FlagSet) func() error {
+	path := keysFileFlag(f)
+	return func() error {
+		users, err := s.userManager(*path).Users()
+		if err != nil {
+			return wrapError("Could not list users.", err)
+		}
+		return s.output.Print(users)
+	}
+}
+
+/** Opens the users stored in the keys file at the given path */
+func (s Switch) userManager(path string) UserManager {
+	return keyfile.NewKeys(path)
+}
@@ -0,0 +1,45 @@
+package client
+
+import (
+	"context"
+	"flag"
+	"time"
+
+	"app-pointment/client/sdk"
+)
+
+/** Render reminder events in real time */
+func (s Switch) watch(f *flag.FlagSet) func() error {
+	types := idsFlag{}
+	f.Var(&types, "type", "Only show events of this type, e.g. reminder.fired (repeatable).")
+	since := f.Int64("since", 0, "Replay buffered events newer than this event id.")
+	return func() error {
+		show := func(e sdk.Event) {
+			if len(types) > 0 && !contains(types, e.Type) {
+				return
+			}
+			if err := s.output.PrintEvent(e); err != nil {
+				s.output.Message("Could not print event %d: %v", e.ID, err)
+			}
+		}
+		lastID := *since
+		for {
+			id, err := s.client.Watch(context.Background(), lastID, show)
+			if err != nil && id == lastID {
+				return wrapError("Could not watch events.", err)
+			}
+			lastID = id
+			s.output.Message("Event stream closed, reconnecting...")
+			time.Sleep(time.Second)
+		}
+	}
+}
+
+func contains(list []string, v string) bool {
+	for _, item := range list {
+		if item == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,269 @@
+package client
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/** Hour used when a day is given without a clock time, e.g. "tomorrow" */
+const defaultHour = 9
+
+/** Layouts of absolute times without a zone, they are read in the user's time zone */
+var absoluteLayouts = []string{
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+}
+
+var weekdays = map[string]time.Weekday{
+	"sunday": time.Sunday, "sun": time.Sunday,
+	"monday": time.Monday, "mon": time.Monday,
+	"tuesday": time.Tuesday, "tue": time.Tuesday, "tues": time.Tuesday,
+	"wednesday": time.Wednesday, "wed": time.Wednesday,
+	"thursday": time.Thursday, "thu": time.Thursday, "thurs": time.Thursday,
+	"friday": time.Friday, "fri": time.Friday,
+	"saturday": time.Saturday, "sat": time.Saturday,
+}
+
+var clockUnits = map[string]time.Duration{
+	"s": time.Second, "sec": time.Second, "secs": time.Second, "second": time.Second, "seconds": time.Second,
+	"m": time.Minute, "min": time.Minute, "mins": time.Minute, "minute": time.Minute, "minutes": time.Minute,
+	"h": time.Hour, "hr": time.Hour, "hrs": time.Hour, "hour": time.Hour, "hours": time.Hour,
+}
+
+var dayUnits = map[string]int{
+	"d": 1, "day": 1, "days": 1,
+	"w": 7, "week": 7, "weeks": 7,
+}
+
+/** Clock time of a day */
+type clock struct {
+	hour, minute int
+}
+
+/**
+ * Resolves a point in time in the future from user input, relative forms are resolved from now in loc:
+ *  RFC 3339 and local timestamps  2026-10-20T09:00:00+02:00, 2026-10-20 09:00
+ *  Go durations and phrases       90m, in 2 hours, in 1 hour and 30 minutes, 3 days from now
+ *  Day offsets                    +2d, in 3 days at 14:30, +1w 9am
+ *  Clock times                    9am, 14:30, noon (today or tomorrow, whichever comes first)
+ *  Days                           today 18:00, tomorrow 9:00, friday, next monday at 2:30pm, 2026-10-20
+ * Days without a clock time resolve to 9:00. A bare weekday is its nearest occurrence still ahead,
+ * "next" always skips today.
+ */
+func ParseTime(input string, now time.Time, loc *time.Location) (time.Time, error) {
+	now = now.In(loc)
+	res, err := parseTime(strings.TrimSpace(input), now, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q: %v", input, err)
+	}
+	if !res.After(now) {
+		return time.Time{}, fmt.Errorf("time %q resolves to %s which is in the past", input, res.Format("Mon Jan 02 15:04 MST"))
+	}
+	return res, nil
+}
+
+func parseTime(input string, now time.Time, loc *time.Location) (time.Time, error) {
+	if input == "" {
+		return time.Time{}, fmt.Errorf("empty time")
+	}
+	if t, err := time.Parse(time.RFC3339, input); err == nil {
+		return t, nil
+	}
+	for _, layout := range absoluteLayouts {
+		if t, err := time.ParseInLocation(layout, input, loc); err == nil {
+			return t, nil
+		}
+	}
+	if d, err := time.ParseDuration(input); err == nil {
+		return now.Add(d), nil
+	}
+	fields := strings.Fields(strings.ToLower(strings.Replace(input, ",", " ", -1)))
+	n := len(fields)
+	switch {
+	case fields[0] == "in":
+		return relativeTime(fields[1:], now)
+	case strings.HasPrefix(fields[0], "+"):
+		fields[0] = strings.TrimPrefix(fields[0], "+")
+		return relativeTime(fields, now)
+	case n > 2 && fields[n-2] == "from" && fields[n-1] == "now":
+		return relativeTime(fields[:n-2], now)
+	}
+	return dayTime(fields, now, loc)
+}
+
+/** Resolves "2 hours 30 minutes", "1h30m" or "3 days at 9am" from now */
+func relativeTime(fields []string, now time.Time) (time.Time, error) {
+	var days int
+	var d time.Duration
+	var parsed bool
+	for i := 0; i < len(fields); i++ {
+		f := fields[i]
+		if f == "and" {
+			continue
+		}
+		if v, err := time.ParseDuration(f); err == nil {
+			d += v
+			parsed = true
+			continue
+		}
+		start := i
+		amount, unit := splitAmount(f)
+		if amount >= 0 && unit == "" {
+			if i+1 == len(fields) {
+				return time.Time{}, fmt.Errorf("missing unit after %q", f)
+			}
+			i++
+			unit = fields[i]
+		}
+		if u, ok := clockUnits[unit]; ok && amount >= 0 {
+			d += time.Duration(amount) * u
+		} else if u, ok := dayUnits[unit]; ok && amount >= 0 {
+			days += amount * u
+		} else if days > 0 && d == 0 {
+			// a day offset may be followed by a clock time
+			c, err := parseClock(fields[start:])
+			if err != nil {
+				return time.Time{}, err
+			}
+			return c.on(now.AddDate(0, 0, days)), nil
+		} else if amount >= 0 {
+			return time.Time{}, fmt.Errorf("unknown unit %q", unit)
+		} else {
+			return time.Time{}, fmt.Errorf("unexpected %q", f)
+		}
+		parsed = true
+	}
+	if !parsed {
+		return time.Time{}, fmt.Errorf("missing amount")
+	}
+	return now.AddDate(0, 0, days).Add(d), nil
+}
+
+/** Splits "3d" into 3 and "d", "a" and "an" count as one, a negative amount means no number */
+func splitAmount(f string) (int, string) {
+	if f == "a" || f == "an" {
+		return 1, ""
+	}
+	i := 0
+	for i < len(f) && f[i] >= '0' && f[i] <= '9' {
+		i++
+	}
+	if i == 0 {
+		return -1, f
+	}
+	n, err := strconv.Atoi(f[:i])
+	if err != nil {
+		return -1, f
+	}
+	return n, f[i:]
+}
+
+/** Resolves an optional day followed by an optional clock time */
+func dayTime(fields []string, now time.Time, loc *time.Location) (time.Time, error) {
+	day, rest, weekday, ok := parseDay(fields, now, loc)
+	if !ok {
+		c, err := parseClock(fields)
+		if err != nil {
+			return time.Time{}, err
+		}
+		t := c.on(now)
+		if !t.After(now) {
+			t = c.on(now.AddDate(0, 0, 1))
+		}
+		return t, nil
+	}
+	c := clock{hour: defaultHour}
+	if len(rest) > 0 {
+		var err error
+		if c, err = parseClock(rest); err != nil {
+			return time.Time{}, err
+		}
+	}
+	t := c.on(day)
+	if weekday && !t.After(now) {
+		t = c.on(day.AddDate(0, 0, 7))
+	}
+	return t, nil
+}
+
+/** Parses the leading day of the fields, weekday is set when a bare weekday may roll over to next week */
+func parseDay(fields []string, now time.Time, loc *time.Location) (day time.Time, rest []string, weekday, ok bool) {
+	switch f := fields[0]; f {
+	case "today":
+		return now, fields[1:], false, true
+	case "tomorrow":
+		return now.AddDate(0, 0, 1), fields[1:], false, true
+	case "next":
+		if len(fields) < 2 {
+			return time.Time{}, nil, false, false
+		}
+		wd, found := weekdays[fields[1]]
+		if !found {
+			return time.Time{}, nil, false, false
+		}
+		ahead := (int(wd)-int(now.Weekday())+6)%7 + 1
+		return now.AddDate(0, 0, ahead), fields[2:], false, true
+	default:
+		if wd, found := weekdays[f]; found {
+			ahead := (int(wd) - int(now.Weekday()) + 7) % 7
+			return now.AddDate(0, 0, ahead), fields[1:], true, true
+		}
+		if t, err := time.ParseInLocation("2006-01-02", f, loc); err == nil {
+			return t, fields[1:], false, true
+		}
+	}
+	return time.Time{}, nil, false, false
+}
+
+/** Parses "9", "9am", "9:30 pm", "14:30", "noon" or "midnight", optionally preceded by "at" */
+func parseClock(fields []string) (clock, error) {
+	if len(fields) > 0 && fields[0] == "at" {
+		fields = fields[1:]
+	}
+	s := strings.Join(fields, "")
+	switch s {
+	case "":
+		return clock{}, fmt.Errorf("missing clock time")
+	case "noon":
+		return clock{hour: 12}, nil
+	case "midnight":
+		return clock{}, nil
+	}
+	meridiem := ""
+	if strings.HasSuffix(s, "am") || strings.HasSuffix(s, "pm") {
+		meridiem, s = s[len(s)-2:], s[:len(s)-2]
+	}
+	parts := strings.SplitN(s, ":", 2)
+	hour, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return clock{}, fmt.Errorf("unknown time %q", strings.Join(fields, " "))
+	}
+	var minute int
+	if len(parts) == 2 {
+		if minute, err = strconv.Atoi(parts[1]); err != nil || len(parts[1]) != 2 || minute > 59 {
+			return clock{}, fmt.Errorf("invalid minutes in %q", strings.Join(fields, " "))
+		}
+	}
+	if meridiem != "" {
+		if hour < 1 || hour > 12 {
+			return clock{}, fmt.Errorf("invalid hour in %q", strings.Join(fields, " "))
+		}
+		hour %= 12
+		if meridiem == "pm" {
+			hour += 12
+		}
+	}
+	if hour < 0 || hour > 23 {
+		return clock{}, fmt.Errorf("invalid hour in %q", strings.Join(fields, " "))
+	}
+	return clock{hour: hour, minute: minute}, nil
+}
+
+/** The clock time on the given day */
+func (c clock) on(day time.Time) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), c.hour, c.minute, 0, 0, day.Location())
+}
@@ -0,0 +1,87 @@
+package client
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTime(t *testing.T) {
+	loc := time.FixedZone("CEST", 2*60*60)
+	// a Monday afternoon
+	now := time.Date(2026, 10, 19, 15, 4, 5, 0, loc)
+	at := func(day, hour, min int) time.Time {
+		return time.Date(2026, 10, day, hour, min, 0, 0, loc)
+	}
+	tests := []struct {
+		input string
+		want  time.Time
+	}{
+		{"2026-10-20T09:00:00+02:00", at(20, 9, 0)},
+		{"2026-10-20T07:00:00Z", at(20, 9, 0)},
+		{"2026-10-20 09:00", at(20, 9, 0)},
+		{"2026-10-20T09:00", at(20, 9, 0)},
+		{"90m", now.Add(90 * time.Minute)},
+		{"in 2 hours", now.Add(2 * time.Hour)},
+		{"in 1 hour and 30 minutes", now.Add(90 * time.Minute)},
+		{"in an hour", now.Add(time.Hour)},
+		{"in 1h30m", now.Add(90 * time.Minute)},
+		{"3 days from now", now.AddDate(0, 0, 3)},
+		{"+2d", now.AddDate(0, 0, 2)},
+		{"in 3 days at 14:30", at(22, 14, 30)},
+		{"+1w 9am", at(26, 9, 0)},
+		{"16:30", at(19, 16, 30)},
+		{"9am", at(20, 9, 0)},
+		{"noon", at(20, 12, 0)},
+		{"midnight", at(20, 0, 0)},
+		{"today 18:00", at(19, 18, 0)},
+		{"tomorrow", at(20, 9, 0)},
+		{"tomorrow 9:00", at(20, 9, 0)},
+		{"Tomorrow, 9:30 PM", at(20, 21, 30)},
+		{"friday", at(23, 9, 0)},
+		{"fri at 5pm", at(23, 17, 0)},
+		{"monday", at(26, 9, 0)},
+		{"monday 18:00", at(19, 18, 0)},
+		{"next monday at 2:30pm", at(26, 14, 30)},
+		{"next friday", at(23, 9, 0)},
+		{"2026-10-20", at(20, 9, 0)},
+		{"2026-10-20 at 7pm", at(20, 19, 0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseTime(tt.input, now, loc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("ParseTime(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTimeErrors(t *testing.T) {
+	loc := time.FixedZone("CEST", 2*60*60)
+	now := time.Date(2026, 10, 19, 15, 4, 5, 0, loc)
+	tests := []string{
+		"",
+		"in",
+		"next",
+		"yesterday",
+		"in 3",
+		"in 3 parsecs",
+		"13pm",
+		"9:7",
+		"25:00",
+		"today 9:00",
+		"2026-10-18 09:00",
+		"-1h",
+		"tomorrow at teatime",
+	}
+	for _, input := range tests {
+		t.Run(input, func(t *testing.T) {
+			if got, err := ParseTime(input, now, loc); err == nil {
+				t.Errorf("ParseTime(%q) = %v, want an error", input, got)
+			}
+		})
+	}
+}
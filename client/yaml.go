@@ -0,0 +1,583 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+/** Node of a decoded JSON document, maps keep the order of their keys */
+type yamlNode struct {
+	keys   []string
+	values []*yamlNode
+	list   bool
+	object bool
+	scalar string
+}
+
+/** Writes v as YAML, keeping the field order of its JSON encoding */
+func encodeYAML(w io.Writer, v interface{}) error {
+	bs, err := json.Marshal(v)
+	if err != nil {
+		return wrapError("could not marshal value", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(bs))
+	dec.UseNumber()
+	node, err := readYAMLNode(dec)
+	if err != nil {
+		return wrapError("could not decode value", err)
+	}
+	var buf bytes.Buffer
+	switch {
+	case (node.object || node.list) && len(node.values) == 0:
+		buf.WriteString(node.inline() + "\n")
+	case node.object || node.list:
+		node.write(&buf, 0)
+	default:
+		buf.WriteString(node.scalar + "\n")
+	}
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+func readYAMLNode(dec *json.Decoder) (*yamlNode, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch t := tok.(type) {
+	case json.Delim:
+		node := &yamlNode{object: t == '{', list: t == '['}
+		for dec.More() {
+			if node.object {
+				key, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				node.keys = append(node.keys, fmt.Sprint(key))
+			}
+			value, err := readYAMLNode(dec)
+			if err != nil {
+				return nil, err
+			}
+			node.values = append(node.values, value)
+		}
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+		return node, nil
+	case string:
+		return &yamlNode{scalar: quoteYAML(t)}, nil
+	case nil:
+		return &yamlNode{scalar: "null"}, nil
+	default:
+		return &yamlNode{scalar: fmt.Sprint(t)}, nil
+	}
+}
+
+/** Renders empty collections and scalars on the line of their key */
+func (n *yamlNode) inline() string {
+	switch {
+	case n.object && len(n.values) == 0:
+		return "{}"
+	case n.list && len(n.values) == 0:
+		return "[]"
+	}
+	return n.scalar
+}
+
+func (n *yamlNode) nested() bool {
+	return (n.object || n.list) && len(n.values) > 0
+}
+
+func (n *yamlNode) write(buf *bytes.Buffer, indent int) {
+	pad := strings.Repeat("  ", indent)
+	for i, value := range n.values {
+		prefix := pad + "- "
+		if n.object {
+			prefix = pad + quoteYAML(n.keys[i]) + ":"
+			if !value.nested() {
+				prefix += " "
+			}
+		}
+		switch {
+		case !value.nested():
+			buf.WriteString(prefix + value.inline() + "\n")
+		case n.list && value.object:
+			// The first key of a map in a list goes on the dash line.
+			var item bytes.Buffer
+			value.write(&item, indent+1)
+			buf.WriteString(prefix + strings.TrimPrefix(item.String(), pad+"  "))
+		case n.list:
+			buf.WriteString(strings.TrimSuffix(prefix, " ") + "\n")
+			value.write(buf, indent+1)
+		default:
+			buf.WriteString(prefix + "\n")
+			value.write(buf, indent+1)
+		}
+	}
+}
+
+/** Quotes strings which YAML would read as another type or could not parse plainly */
+func quoteYAML(s string) string {
+	if s == "" || strings.TrimSpace(s) != s || strings.ContainsAny(s, "\n\r\t\"\\") {
+		return strconv.Quote(s)
+	}
+	if strings.ContainsAny(s[:1], "-?:,[]{}#&*!|>'%@`") || strings.Contains(s, ": ") || strings.Contains(s, " #") {
+		return strconv.Quote(s)
+	}
+	switch strings.ToLower(s) {
+	case "true", "false", "yes", "no", "on", "off", "null", "~", "y", "n":
+		return strconv.Quote(s)
+	}
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+/** Plain scalars read as numbers */
+var yamlNumber = regexp.MustCompile(`^[-+]?([0-9]+\.?[0-9]*|\.[0-9]+)([eE][-+]?[0-9]+)?$`)
+
+/**
+ * Decodes the YAML subset written by encodeYAML and by hand into v through its JSON encoding:
+ * block maps and lists, flow collections, quoted and plain scalars, comments and | or > block scalars.
+ * JSON documents are decoded as they are.
+ */
+func decodeYAML(data []byte, v interface{}) error {
+	var doc interface{}
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') && json.Valid(trimmed) {
+		doc = json.RawMessage(trimmed)
+	} else {
+		d := &yamlDecoder{lines: strings.Split(strings.Replace(string(data), "\r\n", "\n", -1), "\n")}
+		for i, line := range d.lines {
+			if strings.HasPrefix(strings.TrimLeft(line, " "), "\t") && strings.TrimSpace(line) != "" {
+				return fmt.Errorf("yaml line %d: tabs cannot be used for indentation", i+1)
+			}
+		}
+		value, err := d.document()
+		if err != nil {
+			return err
+		}
+		doc = value
+	}
+	bs, err := json.Marshal(doc)
+	if err != nil {
+		return wrapError("could not convert yaml", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(bs))
+	dec.DisallowUnknownFields()
+	return dec.Decode(v)
+}
+
+type yamlDecoder struct {
+	lines []string
+	pos   int
+}
+
+/** Error at the current line */
+func (d *yamlDecoder) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("yaml line %d: %s", d.pos+1, fmt.Sprintf(format, args...))
+}
+
+func (d *yamlDecoder) document() (interface{}, error) {
+	indent, _, ok := d.peek()
+	if !ok {
+		return nil, nil
+	}
+	value, err := d.block(indent)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, ok := d.peek(); ok {
+		return nil, d.errorf("unexpected content")
+	}
+	return value, nil
+}
+
+/** Indentation and content without comments of the next significant line */
+func (d *yamlDecoder) peek() (int, string, bool) {
+	for ; d.pos < len(d.lines); d.pos++ {
+		line := d.lines[d.pos]
+		content := strings.TrimSpace(stripYAMLComment(line))
+		if content == "" || content == "---" || content == "..." {
+			continue
+		}
+		return len(line) - len(strings.TrimLeft(line, " ")), content, true
+	}
+	return 0, "", false
+}
+
+func (d *yamlDecoder) block(indent int) (interface{}, error) {
+	_, content, _ := d.peek()
+	if content == "-" || strings.HasPrefix(content, "- ") {
+		return d.list(indent)
+	}
+	if _, _, ok := splitYAMLKey(content); ok {
+		return d.object(indent)
+	}
+	d.pos++
+	return parseYAMLScalar(content)
+}
+
+func (d *yamlDecoder) list(indent int) (interface{}, error) {
+	res := []interface{}{}
+	for {
+		i, content, ok := d.peek()
+		if !ok || i < indent {
+			return res, nil
+		}
+		if i > indent {
+			return nil, d.errorf("unexpected indentation")
+		}
+		if content != "-" && !strings.HasPrefix(content, "- ") {
+			return res, nil
+		}
+		item := strings.TrimLeft(strings.TrimPrefix(content, "-"), " ")
+		if item == "" {
+			d.pos++
+			value, err := d.nested(indent)
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, value)
+			continue
+		}
+		// The item continues as a block indented at its first character.
+		line := d.lines[d.pos]
+		itemIndent := len(line) - len(strings.TrimLeft(strings.TrimLeft(line, " ")[1:], " "))
+		d.lines[d.pos] = strings.Repeat(" ", itemIndent) + item
+		value, err := d.block(itemIndent)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, value)
+	}
+}
+
+func (d *yamlDecoder) object(indent int) (interface{}, error) {
+	res := map[string]interface{}{}
+	for {
+		i, content, ok := d.peek()
+		if !ok || i < indent {
+			return res, nil
+		}
+		if i > indent {
+			return nil, d.errorf("unexpected indentation")
+		}
+		key, value, ok := splitYAMLKey(content)
+		if !ok {
+			return nil, d.errorf("expected a key, got %q", content)
+		}
+		if _, dup := res[key]; dup {
+			return nil, d.errorf("duplicate key %q", key)
+		}
+		d.pos++
+		var err error
+		switch {
+		case value == "":
+			res[key], err = d.nested(indent)
+		case value[0] == '|' || value[0] == '>':
+			res[key], err = d.text(indent, value)
+		default:
+			res[key], err = parseYAMLScalar(value)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+/** Value of an empty key or list item, a more indented block, a list at the same indentation or null */
+func (d *yamlDecoder) nested(indent int) (interface{}, error) {
+	i, content, ok := d.peek()
+	switch {
+	case ok && i > indent:
+		return d.block(i)
+	case ok && i == indent && (content == "-" || strings.HasPrefix(content, "- ")):
+		return d.list(i)
+	}
+	return nil, nil
+}
+
+/** Literal (|) or folded (>) block scalar */
+func (d *yamlDecoder) text(indent int, header string) (interface{}, error) {
+	folded := header[0] == '>'
+	chomp := strings.TrimSpace(header[1:])
+	if chomp != "" && chomp != "-" && chomp != "+" {
+		return nil, d.errorf("unsupported block scalar header %q", header)
+	}
+	var lines []string
+	textIndent := -1
+	for ; d.pos < len(d.lines); d.pos++ {
+		line := strings.TrimRight(d.lines[d.pos], " ")
+		i := len(line) - len(strings.TrimLeft(line, " "))
+		if line == "" {
+			lines = append(lines, "")
+			continue
+		}
+		if textIndent < 0 {
+			textIndent = i
+		}
+		if i <= indent || i < textIndent {
+			break
+		}
+		lines = append(lines, line[textIndent:])
+	}
+	trailing := 0
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+		trailing++
+	}
+	var res string
+	if folded {
+		for i, line := range lines {
+			switch {
+			case i == 0:
+			case line == "":
+				res += "\n"
+			case lines[i-1] == "":
+			default:
+				res += " "
+			}
+			res += line
+		}
+	} else {
+		res = strings.Join(lines, "\n")
+	}
+	switch chomp {
+	case "":
+		res += "\n"
+	case "+":
+		res += strings.Repeat("\n", trailing+1)
+	}
+	return res, nil
+}
+
+/** Splits "key: value" and "key:" lines */
+func splitYAMLKey(content string) (string, string, bool) {
+	if content[0] == '"' || content[0] == '\'' {
+		end := closingQuote(content, 0)
+		if end < 0 || end+1 >= len(content) || content[end+1] != ':' {
+			return "", "", false
+		}
+		key, err := unquoteYAML(content[:end+1])
+		if err != nil {
+			return "", "", false
+		}
+		return key, strings.TrimSpace(content[end+2:]), true
+	}
+	if content[0] == '[' || content[0] == '{' {
+		return "", "", false
+	}
+	if strings.HasSuffix(content, ":") {
+		return strings.TrimSpace(strings.TrimSuffix(content, ":")), "", true
+	}
+	i := strings.Index(content, ": ")
+	if i <= 0 {
+		return "", "", false
+	}
+	return strings.TrimSpace(content[:i]), strings.TrimSpace(content[i+2:]), true
+}
+
+/** Removes a comment, a # at the start of the line or after a space outside quotes */
+func stripYAMLComment(line string) string {
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case c == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return line[:i]
+		case (c == '"' || c == '\'') && startsYAMLValue(line[:i]):
+			end := closingQuote(line, i)
+			if end < 0 {
+				return line
+			}
+			i = end
+		}
+	}
+	return line
+}
+
+/** Reports whether a quote after the prefix opens a quoted scalar */
+func startsYAMLValue(prefix string) bool {
+	prefix = strings.TrimRight(prefix, " ")
+	return prefix == "" || strings.ContainsAny(prefix[len(prefix)-1:], ":-[{,")
+}
+
+/** Index of the quote closing the one at start, -1 when it is not closed */
+func closingQuote(s string, start int) int {
+	quote := s[start]
+	for i := start + 1; i < len(s); i++ {
+		switch {
+		case quote == '"' && s[i] == '\\':
+			i++
+		case s[i] == quote && quote == '\'' && i+1 < len(s) && s[i+1] == '\'':
+			i++
+		case s[i] == quote:
+			return i
+		}
+	}
+	return -1
+}
+
+func unquoteYAML(s string) (string, error) {
+	if s[0] == '\'' {
+		return strings.Replace(s[1:len(s)-1], "''", "'", -1), nil
+	}
+	return strconv.Unquote(s)
+}
+
+func parseYAMLScalar(s string) (interface{}, error) {
+	p := &yamlFlow{s: s}
+	value, err := p.value()
+	if err != nil {
+		return nil, err
+	}
+	if p.skipSpace(); p.i < len(p.s) {
+		return nil, fmt.Errorf("yaml: unexpected %q after %q", p.s[p.i:], p.s[:p.i])
+	}
+	return value, nil
+}
+
+/** Parser of flow collections and scalars, e.g. [a, "b"] or {name: Bob} */
+type yamlFlow struct {
+	s string
+	i int
+}
+
+func (p *yamlFlow) skipSpace() {
+	for p.i < len(p.s) && p.s[p.i] == ' ' {
+		p.i++
+	}
+}
+
+func (p *yamlFlow) value() (interface{}, error) {
+	p.skipSpace()
+	if p.i >= len(p.s) {
+		return nil, nil
+	}
+	switch c := p.s[p.i]; c {
+	case '[', '{':
+		return p.collection(c == '{')
+	case '"', '\'':
+		end := closingQuote(p.s, p.i)
+		if end < 0 {
+			return nil, fmt.Errorf("yaml: unterminated string %s", p.s[p.i:])
+		}
+		v, err := unquoteYAML(p.s[p.i : end+1])
+		if err != nil {
+			return nil, fmt.Errorf("yaml: invalid string %s", p.s[p.i:end+1])
+		}
+		p.i = end + 1
+		return v, nil
+	}
+	return p.plain(), nil
+}
+
+/** Plain scalar, inside flow collections it ends at the next indicator */
+func (p *yamlFlow) plain() interface{} {
+	start := p.i
+	for p.i < len(p.s) && (!p.inFlow() || !strings.ContainsAny(p.s[p.i:p.i+1], ",]}")) {
+		if p.inFlow() && p.s[p.i] == ':' && (p.i+1 == len(p.s) || p.s[p.i+1] == ' ') {
+			break
+		}
+		p.i++
+	}
+	raw := strings.TrimSpace(p.s[start:p.i])
+	switch raw {
+	case "", "~", "null", "Null", "NULL":
+		return nil
+	case "true", "True", "TRUE":
+		return true
+	case "false", "False", "FALSE":
+		return false
+	}
+	if yamlNumber.MatchString(raw) {
+		return json.Number(strings.TrimPrefix(raw, "+"))
+	}
+	return raw
+}
+
+func (p *yamlFlow) inFlow() bool {
+	return p.s != "" && (p.s[0] == '[' || p.s[0] == '{')
+}
+
+func (p *yamlFlow) collection(object bool) (interface{}, error) {
+	closing := byte(']')
+	if object {
+		closing = '}'
+	}
+	p.i++
+	list := []interface{}{}
+	obj := map[string]interface{}{}
+	for {
+		p.skipSpace()
+		if p.i < len(p.s) && p.s[p.i] == closing {
+			p.i++
+			break
+		}
+		value, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		if object {
+			key, ok := value.(string)
+			if !ok && value != nil {
+				key = fmt.Sprint(value)
+			}
+			if p.i >= len(p.s) || p.s[p.i] != ':' {
+				return nil, fmt.Errorf("yaml: expected ':' after key %q in %s", key, p.s)
+			}
+			p.i++
+			if value, err = p.value(); err != nil {
+				return nil, err
+			}
+			obj[key] = value
+			p.skipSpace()
+		} else {
+			list = append(list, value)
+		}
+		if p.i >= len(p.s) {
+			return nil, fmt.Errorf("yaml: unterminated collection %s", p.s)
+		}
+		switch p.s[p.i] {
+		case ',':
+			p.i++
+		case closing:
+		default:
+			return nil, fmt.Errorf("yaml: unexpected %q in %s", p.s[p.i], p.s)
+		}
+	}
+	if object {
+		return obj, nil
+	}
+	return list, nil
+}
+
+/** String field which also accepts numbers and booleans, e.g. a variable written as room: 12 */
+type yamlText string
+
+func (t *yamlText) UnmarshalJSON(bs []byte) error {
+	var s string
+	if err := json.Unmarshal(bs, &s); err == nil {
+		*t = yamlText(s)
+		return nil
+	}
+	var v interface{}
+	if err := json.Unmarshal(bs, &v); err != nil {
+		return err
+	}
+	switch v.(type) {
+	case nil:
+		*t = ""
+	case float64, bool:
+		*t = yamlText(bs)
+	default:
+		return fmt.Errorf("expected a string, got %s", bs)
+	}
+	return nil
+}
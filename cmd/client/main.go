@@ -2,27 +2,100 @@ package main
 
 import (
 	"app-pointment/client"
+	"app-pointment/client/sdk"
 	"flag"
 	"fmt"
 	"os"
+	"strconv"
+	"time"
 )
 
 var (
+	contextFlag    = flag.String("context", "", "Config file context to use instead of the current one")
 	backendURIFlag = flag.String("backend", "http://localhost:8008", "Backend API URL")
+	apiKeyFlag     = flag.String("api-key", "", "Backend API key")
+	timeZoneFlag   = flag.String("time-zone", "", "Time zone used to show due times (local time when empty)")
+	timeoutFlag    = flag.Duration("timeout", envDuration("APP_POINTMENT_TIMEOUT", sdk.DefaultTimeout), "Timeout of a single backend request")
+	retriesFlag    = flag.Int("retries", envInt("APP_POINTMENT_RETRIES", sdk.DefaultRetries), "Retries of requests which failed with a transient error")
+	outputFlag     = flag.String("output", client.OutputTable, "Output format: table, json, yaml, ids or template")
+	templateFlag   = flag.String("template", "", "Go template rendering the output, e.g. '{{range .}}{{.ID}}:{{.Title}} {{end}}'")
 	helpFlag       = flag.Bool("help", false, "Display helpful message")
 )
 
 func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Run '%s --help' for usage.\n", os.Args[0])
+	}
 	flag.Parse()
-	s := client.NewSwitch(*backendURIFlag)
+	file, err := client.LoadConfigFile(client.ConfigPath())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid config: %v\n", err)
+		os.Exit(client.ExitUsage)
+	}
+	ctx, err := file.Context(resolve("context", *contextFlag, client.ContextEnv, ""))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid config: %v\n", err)
+		os.Exit(client.ExitUsage)
+	}
+	s, err := client.NewSwitch(client.Config{
+		BackendURI: resolve("backend", *backendURIFlag, client.BackendEnv, ctx.Backend),
+		APIKey:     resolve("api-key", *apiKeyFlag, client.APIKeyEnv, ctx.APIKey),
+		Output:     resolve("output", *outputFlag, client.OutputEnv, ctx.Output),
+		Template:   *templateFlag,
+		TimeZone:   resolve("time-zone", *timeZoneFlag, client.TimeZoneEnv, ctx.TimeZone),
+		Options: []sdk.Option{
+			sdk.WithTimeout(*timeoutFlag),
+			sdk.WithRetries(*retriesFlag),
+		},
+		Globals: flag.CommandLine,
+		Args:    flag.Args(),
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid options: %v\n", err)
+		os.Exit(client.ExitUsage)
+	}
 
-	if *helpFlag || len(os.Args) == 1 {
+	if *helpFlag {
 		s.Help()
 		return
 	}
-	err := s.Switch()
+	err = s.Switch()
 	if err != nil {
-		fmt.Printf("Cmd switch error: %v\n", err)
-		os.Exit(2)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(client.ExitCode(err))
+	}
+}
+
+/** Resolves a setting from its flag, its environment variable, the config context and then the flag default */
+func resolve(name, value, env, fromContext string) string {
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	if set {
+		return value
+	}
+	if v := os.Getenv(env); v != "" {
+		return v
+	}
+	if fromContext != "" {
+		return fromContext
+	}
+	return value
+}
+
+func envDuration(name string, def time.Duration) time.Duration {
+	if d, err := time.ParseDuration(os.Getenv(name)); err == nil {
+		return d
+	}
+	return def
+}
+
+func envInt(name string, def int) int {
+	if n, err := strconv.Atoi(os.Getenv(name)); err == nil {
+		return n
 	}
+	return def
 }
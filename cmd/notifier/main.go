@@ -0,0 +1,81 @@
+package main
+
+import (
+	"app-pointment/notifier"
+	"context"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+)
+
+var (
+	addrFlag     = flag.String("addr", ":"+port(), "HTTP notifier address")
+	backendsFlag = flag.String("backends", "desktop,terminal", "Comma separated output backends: terminal, log, desktop")
+	logFileFlag  = flag.String("log-file", "notifications.log", "Path to the log backend file")
+	iconFlag     = flag.String("icon", "notifier/gopher.png", "Icon shown by the desktop backend")
+	timeoutFlag  = flag.Duration("timeout", 15*time.Second, "How long to wait for a reply to a notification")
+	asyncFlag    = flag.Duration("async-timeout", 10*time.Minute, "How long to wait for a reply to a notification carrying a callback")
+	apiKeyFlag   = flag.String("api-key", os.Getenv("APP_POINTMENT_API_KEY"), "API key used to acknowledge notifications on the server")
+)
+
+func main() {
+	flag.Parse()
+	var backends []notifier.Backend
+	for _, name := range strings.Split(*backendsFlag, ",") {
+		switch strings.TrimSpace(name) {
+		case "terminal":
+			backends = append(backends, notifier.NewTerminalBackend())
+		case "log":
+			backends = append(backends, notifier.NewLogFileBackend(*logFileFlag))
+		case "desktop":
+			b, err := notifier.NewDesktopBackend(*iconFlag, *timeoutFlag)
+			if err != nil {
+				log.Printf("desktop backend disabled: %v", err)
+				continue
+			}
+			backends = append(backends, b)
+		default:
+			log.Fatalf("unknown backend %q", name)
+		}
+	}
+	if len(backends) == 0 {
+		log.Println("no backend available, falling back to terminal")
+		backends = append(backends, notifier.NewTerminalBackend())
+	}
+
+	n := notifier.NewServer(*timeoutFlag, backends...)
+	n.AsyncTimeout = *asyncFlag
+	n.APIKey = *apiKeyFlag
+	srv := &http.Server{
+		Addr:    *addrFlag,
+		Handler: n.Handler(),
+	}
+	go func() {
+		log.Printf("server is running on address: %s", srv.Addr)
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("could not start notifier: %v", err)
+		}
+	}()
+
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, syscall.SIGINT, syscall.SIGTERM)
+	sig := <-c
+	log.Printf("received shutdown signal: %v\n", sig.String())
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		log.Fatalf("error on notifier shutdown: %v", err)
+	}
+}
+
+func port() string {
+	if p := os.Getenv("PORT"); p != "" {
+		return p
+	}
+	return "9000"
+}
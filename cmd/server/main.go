@@ -2,39 +2,199 @@ package main
 
 import (
 	"app-pointment/server"
+	"app-pointment/server/keyfile"
+	"app-pointment/server/models"
 	"app-pointment/server/repositories"
 	"app-pointment/server/services"
 	"flag"
 	"log"
 	"os"
+	"strings"
 	"syscall"
+	"time"
 )
 
+type channelsFlag []string
+
+func (c *channelsFlag) String() string {
+	return strings.Join(*c, ",")
+}
+
+func (c *channelsFlag) Set(v string) error {
+	*c = append(*c, v)
+	return nil
+}
+
 var (
 	addrFlag        = flag.String("addr", ":8008", "HTTP server address")
 	notifierURIFlag = flag.String("notifier", "http://localhost:9000", "Notifier API URI")
 	dbFlag          = flag.String("db", "db.json", "Path to db.json file")
 	dbCfgFlag       = flag.String("db-cfg", ".db.config.json", "Path to .db.config.json file")
+	keysFlag        = flag.String("keys", "", "Path to the API keys file, required unless --no-auth is set")
+	noAuthFlag      = flag.Bool("no-auth", false, "Disable API key authentication, every request is handled as an admin")
+	timeoutFlag     = flag.Duration("timeout", 10*time.Second, "Per-request handler timeout (0 disables it)")
+	defaultChsFlag  = flag.String("default-channels", services.DesktopChannel, "Comma separated channels used by reminders without explicit channels")
+	retryFlag       = flag.String("retry", "attempts=10,initial=30s,max=30m,multiplier=2,jitter=0.2", "Default retry policy for failed notifications")
+	breakerFlag     = flag.Int("breaker-threshold", 5, "Consecutive notifier failures before the circuit breaker opens (0 disables it)")
+	cooldownFlag    = flag.Duration("breaker-cooldown", 30*time.Second, "How long the circuit breaker stays open before probing the notifier")
+	callbackFlag    = flag.String("callback-url", "", "Public base URL of this server, enables asynchronous notifier acknowledgements when set")
+	ackTimeoutFlag  = flag.Duration("ack-timeout", 15*time.Minute, "How long to wait for an asynchronous acknowledgement before notifying again")
+	webhooksFlag    = flag.String("webhooks", "webhooks.json", "Path to the webhook subscriptions file")
+	webhookRetry    = flag.String("webhook-retry", "attempts=5,initial=10s,max=10m,multiplier=2,jitter=0.2", "Retry policy for failed webhook deliveries")
+	eventBufferFlag = flag.Int("event-buffer", 1000, "Number of recent events kept for resuming event stream clients")
+	templatesFlag   = flag.String("templates", "templates.json", "Path to the message templates file")
+	idempotencyFlag = flag.Duration("idempotency-ttl", 24*time.Hour, "How long responses of requests with an Idempotency-Key are kept (0 disables it)")
+	deliveryLogFlag = flag.String("delivery-log", "deliveries.json", "Path to the delivery attempts log file")
+	quietFlag       = flag.String("quiet-hours", "", "Global quiet hours, e.g. \"mon-fri 22:00-07:00; sat,sun 23:00-09:00\"")
+	quietTZFlag     = flag.String("quiet-tz", "", "Time zone of the global quiet hours (local time when empty)")
+	quietModeFlag   = flag.String("quiet-mode", models.QuietModeDefer, "What happens to reminders during quiet hours: defer or silent")
+	quietChFlag     = flag.String("quiet-channel", "", "Channel used to deliver reminders silently during quiet hours")
+	channelFlags    channelsFlag
+	channelRetries  channelsFlag
+	escalationFlags channelsFlag
 )
 
 func main() {
+	flag.Var(&channelFlags, "channel", "Notification channel as name=kind:target, kinds: desktop, webhook, smtp, file, stdout, unix (repeatable)")
+	flag.Var(&channelRetries, "channel-retry", "Channel retry policy as name:attempts=5,initial=10s,max=10m,multiplier=2,jitter=0.2 (repeatable)")
+	flag.Var(&escalationFlags, "escalation", "Escalation policy as name=trigger:channels;..., e.g. oncall=3:email;10m:pager,sms (repeatable)")
 	flag.Parse()
+	cfg := server.Config{
+		Addr:    *addrFlag,
+		Timeout: *timeoutFlag,
+	}
+	var users services.NotifierResolver
+	var userQuiet services.QuietHoursResolver
+	switch {
+	case *keysFlag != "" && *noAuthFlag:
+		log.Fatal("--keys and --no-auth cannot be used together")
+	case *keysFlag != "":
+		keys := keyfile.NewKeys(*keysFlag)
+		cfg.Keys = keys
+		cfg.KeyAdmin = keys
+		users = keys
+		userQuiet = keys
+	case *noAuthFlag:
+		log.Println("api key authentication is disabled, every request is handled as an admin")
+	default:
+		log.Fatal("missing --keys, pass --no-auth to run without authentication")
+	}
+	retryPolicy, err := models.ParseRetryPolicy(*retryFlag)
+	if err != nil {
+		log.Fatalf("invalid retry policy: %v", err)
+	}
+	opts := services.ChannelOptions{
+		Users:       users,
+		Breaker:     services.BreakerConfig{Threshold: *breakerFlag, Cooldown: *cooldownFlag},
+		CallbackURI: strings.TrimSuffix(*callbackFlag, "/"),
+	}
+	channels := services.NewChannels(retryPolicy, strings.Split(*defaultChsFlag, ",")...)
+	desktop := services.NewHTTPClient(services.DesktopChannel, *notifierURIFlag, opts)
+	if err := channels.Register(desktop); err != nil {
+		log.Fatalf("could not register desktop channel: %v", err)
+	}
+	for _, spec := range channelFlags {
+		ch, err := services.ParseChannel(spec, opts)
+		if err != nil {
+			log.Fatalf("could not configure channel: %v", err)
+		}
+		if err := channels.Register(ch); err != nil {
+			log.Fatalf("could not register channel: %v", err)
+		}
+	}
+	for _, spec := range channelRetries {
+		parts := strings.SplitN(spec, ":", 2)
+		if len(parts) != 2 {
+			log.Fatalf("invalid channel retry policy %q, expected name:policy", spec)
+		}
+		policy, err := models.ParseRetryPolicy(parts[1])
+		if err != nil {
+			log.Fatalf("invalid retry policy for channel %s: %v", parts[0], err)
+		}
+		if err := channels.SetRetryPolicy(parts[0], policy); err != nil {
+			log.Fatalf("could not set channel retry policy: %v", err)
+		}
+	}
+	for _, spec := range escalationFlags {
+		policy, err := models.ParseEscalationPolicy(spec)
+		if err != nil {
+			log.Fatalf("invalid escalation policy: %v", err)
+		}
+		if err := channels.SetEscalationPolicy(policy); err != nil {
+			log.Fatalf("could not set escalation policy: %v", err)
+		}
+	}
+	if err := channels.Validate(channels.Defaults()); err != nil {
+		log.Fatalf("invalid default channels: %v", err)
+	}
+	var globalQuiet *models.QuietHours
+	if *quietFlag != "" {
+		windows, err := models.ParseQuietWindows(*quietFlag)
+		if err != nil {
+			log.Fatalf("invalid quiet hours: %v", err)
+		}
+		globalQuiet = &models.QuietHours{
+			TimeZone: *quietTZFlag,
+			Mode:     *quietModeFlag,
+			Channel:  *quietChFlag,
+			Windows:  windows,
+		}
+		if err := globalQuiet.Validate(); err != nil {
+			log.Fatalf("invalid quiet hours: %v", err)
+		}
+		if globalQuiet.Mode == models.QuietModeSilent {
+			if err := channels.Validate([]string{globalQuiet.Channel}); err != nil {
+				log.Fatalf("invalid quiet hours channel: %v", err)
+			}
+		}
+	}
+
+	webhookPolicy, err := models.ParseRetryPolicy(*webhookRetry)
+	if err != nil {
+		log.Fatalf("invalid webhook retry policy: %v", err)
+	}
+	webhooks := services.NewWebhooks(repositories.NewWebhooks(*webhooksFlag), webhookPolicy)
+	events := services.NewEvents()
+	stream := services.NewEventStream(*eventBufferFlag)
+	events.Subscribe(webhooks.Handle)
+	events.Subscribe(stream.Handle)
+	cfg.Webhooks = webhooks
+	cfg.Events = stream
+
+	if *idempotencyFlag > 0 {
+		cfg.Idempotency = services.NewIdempotency(*idempotencyFlag)
+	}
+
+	templates := services.NewTemplates(repositories.NewTemplates(*templatesFlag))
+	cfg.Templates = templates
+
 	db := repositories.NewDB(*dbFlag, *dbCfgFlag)
 	repo := repositories.NewReminders(db)
-	service := services.NewReminders(repo)
-	backend := server.New(*addrFlag, service)
+	service := services.NewReminders(repo, channels, templates, events)
+	templates.Reminders = service
+	deliveryLog := services.NewDeliveryLog(repositories.NewAttempts(*deliveryLogFlag))
+	service.DeliveryLog = deliveryLog
 	saver := services.NewSaver(service)
-	notifier := services.NewNotifier(*notifierURIFlag, service)
+	notifier := services.NewNotifier(channels, service)
+	notifier.Events = events
+	notifier.Templates = templates
+	notifier.Quiet = services.NewQuietHours(globalQuiet, userQuiet)
+	notifier.AckTimeout = *ackTimeoutFlag
+	notifier.Log = deliveryLog
+	checkers := []services.HealthChecker{db, saver, notifier}
+	cfg.Health = services.NewHealth(append(checkers, channels.Checkers()...)...)
+	backend := server.New(cfg, service)
 	if err := db.Start(); err != nil {
 		log.Fatalf("could not start file database service: %v", err)
 	}
 	go saver.Start()
 	go notifier.Start()
+	go webhooks.Start()
 	go func() {
 		if err := backend.Start(); err != nil {
 			log.Fatalf("could not start backend api service: %v", err)
 		}
 	}()
 	signals := []os.Signal{syscall.SIGINT, syscall.SIGTERM}
-	server.ListenForSignals(signals, backend, saver, notifier, db)
+	server.ListenForSignals(signals, backend, webhooks, saver, notifier, db)
 }
@@ -0,0 +1,207 @@
+package notifier
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type TerminalBackend struct {
+	mu          sync.Mutex
+	out         io.Writer
+	lines       chan string
+	interactive int32
+	// prompting is set while a notification waits for an answer, lines typed
+	// in between are dropped.
+	prompting int32
+}
+
+func NewTerminalBackend() *TerminalBackend {
+	b := &TerminalBackend{out: os.Stdout}
+	info, err := os.Stdin.Stat()
+	if err == nil && info.Mode()&os.ModeCharDevice != 0 {
+		b.interactive = 1
+		b.lines = make(chan string)
+		go b.scan(os.Stdin)
+	}
+	return b
+}
+
+func (b *TerminalBackend) Name() string {
+	return "terminal"
+}
+
+// Interactive is false when stdin is not a terminal or was closed.
+func (b *TerminalBackend) Interactive() bool {
+	return atomic.LoadInt32(&b.interactive) == 1
+}
+
+// Notify shows one notification at a time, the time to answer starts when
+// the prompt is shown.
+func (b *TerminalBackend) Notify(ctx context.Context, n Notification) (Reply, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if ctx.Err() != nil {
+		return Reply{ActivationType: ActivationTimeout}, nil
+	}
+	ctx, cancel := n.answerContext(ctx)
+	defer cancel()
+	fmt.Fprintf(b.out, "\n\a=== %s ===\n%s\n", n.Title, n.Message)
+	if !b.Interactive() {
+		return Reply{ActivationType: ActivationClosed}, nil
+	}
+	if !b.drain() {
+		return Reply{ActivationType: ActivationClosed}, nil
+	}
+	atomic.StoreInt32(&b.prompting, 1)
+	defer atomic.StoreInt32(&b.prompting, 0)
+	fmt.Fprint(b.out, "Press enter to complete or type a duration to snooze (e.g. 5m): ")
+	select {
+	case line, ok := <-b.lines:
+		if !ok {
+			b.closed()
+			return Reply{ActivationType: ActivationClosed}, nil
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			return Reply{ActivationType: ActivationClosed}, nil
+		}
+		if _, err := time.ParseDuration(line); err != nil {
+			fmt.Fprintf(b.out, "invalid duration %q, the reminder will be retried\n", line)
+			return Reply{ActivationType: ActivationTimeout}, nil
+		}
+		return Reply{ActivationType: ActivationReplied, ActivationValue: line}, nil
+	case <-ctx.Done():
+		fmt.Fprintln(b.out, "\nno answer, the reminder will be retried")
+		return Reply{ActivationType: ActivationTimeout}, nil
+	}
+}
+
+// drain drops a line typed before the prompt so it cannot answer it, it is
+// false once stdin was closed.
+func (b *TerminalBackend) drain() bool {
+	for {
+		select {
+		case _, ok := <-b.lines:
+			if !ok {
+				b.closed()
+				return false
+			}
+		default:
+			return true
+		}
+	}
+}
+
+func (b *TerminalBackend) closed() {
+	atomic.StoreInt32(&b.interactive, 0)
+	fmt.Fprintln(b.out)
+}
+
+func (b *TerminalBackend) scan(r io.Reader) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if atomic.LoadInt32(&b.prompting) == 0 {
+			continue
+		}
+		b.lines <- scanner.Text()
+	}
+	close(b.lines)
+}
+
+type LogFileBackend struct {
+	mu   sync.Mutex
+	path string
+}
+
+func NewLogFileBackend(path string) *LogFileBackend {
+	return &LogFileBackend{path: path}
+}
+
+func (b *LogFileBackend) Name() string {
+	return "log"
+}
+
+func (b *LogFileBackend) Interactive() bool {
+	return false
+}
+
+func (b *LogFileBackend) Notify(ctx context.Context, n Notification) (Reply, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	f, err := os.OpenFile(b.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return Reply{}, fmt.Errorf("could not open log file: %v", err)
+	}
+	defer f.Close()
+	_, err = fmt.Fprintf(f, "%s\t%d\t%s\t%s\n", time.Now().Format(time.RFC3339), n.ID, n.Title, n.Message)
+	if err != nil {
+		return Reply{}, fmt.Errorf("could not write log file: %v", err)
+	}
+	return Reply{ActivationType: ActivationClosed}, nil
+}
+
+type DesktopBackend struct {
+	command string
+	icon    string
+	timeout time.Duration
+	// noActions is set once notify-send turned out not to support actions.
+	noActions int32
+}
+
+func NewDesktopBackend(icon string, timeout time.Duration) (*DesktopBackend, error) {
+	command, err := exec.LookPath("notify-send")
+	if err != nil {
+		return nil, fmt.Errorf("notify-send is not available: %v", err)
+	}
+	return &DesktopBackend{command: command, icon: icon, timeout: timeout}, nil
+}
+
+func (b *DesktopBackend) Name() string {
+	return "desktop"
+}
+
+func (b *DesktopBackend) Interactive() bool {
+	return atomic.LoadInt32(&b.noActions) == 0
+}
+
+// Notify waits for the "Completed?" action when notify-send supports actions
+// and falls back to a fire-and-forget notification otherwise.
+func (b *DesktopBackend) Notify(ctx context.Context, n Notification) (Reply, error) {
+	ctx, cancel := n.answerContext(ctx)
+	defer cancel()
+	args := []string{
+		"--app-name=app-pointment",
+		fmt.Sprintf("--expire-time=%d", b.timeout.Milliseconds()),
+	}
+	if b.icon != "" {
+		args = append(args, "--icon="+b.icon)
+	}
+	if b.Interactive() {
+		interactive := append(args, "--wait", "--action="+ActivationClosed+"=Completed?", n.Title, n.Message)
+		out, err := exec.CommandContext(ctx, b.command, interactive...).Output()
+		if ctx.Err() != nil {
+			return Reply{ActivationType: ActivationTimeout}, nil
+		}
+		if err == nil {
+			if strings.TrimSpace(string(out)) == ActivationClosed {
+				return Reply{ActivationType: ActivationClosed}, nil
+			}
+			return Reply{ActivationType: ActivationTimeout}, nil
+		}
+		log.Printf("notify-send does not support actions, falling back: %v", err)
+		atomic.StoreInt32(&b.noActions, 1)
+	}
+	if err := exec.CommandContext(ctx, b.command, append(args, n.Title, n.Message)...).Run(); err != nil {
+		return Reply{}, fmt.Errorf("notify-send failed: %v", err)
+	}
+	return Reply{ActivationType: ActivationClosed}, nil
+}
@@ -0,0 +1,83 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+)
+
+type callbackBody struct {
+	DeliveryID string `json:"delivery_id"`
+	Duration   string `json:"duration,omitempty"`
+}
+
+// notifyAsync accepts the notification right away and reports the reply to
+// the server callback once the user interacted with it.
+func (s *Server) notifyAsync(w http.ResponseWriter, n Notification) {
+	deliveryID, err := newDeliveryID()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	go func() {
+		n.Timeout = s.AsyncTimeout
+		reply := s.dispatch(context.Background(), n)
+		body := callbackBody{DeliveryID: deliveryID}
+		var uri string
+		switch reply.ActivationType {
+		case ActivationClosed:
+			uri = n.Callback + "/ack"
+		case ActivationReplied:
+			uri = n.Callback + "/snooze"
+			body.Duration = reply.ActivationValue
+		default:
+			log.Printf("notification %d (%s) timed out, the server will notify again", n.ID, deliveryID)
+			return
+		}
+		if err := s.callback(uri, body); err != nil {
+			log.Printf("could not call back %s: %v", uri, err)
+		}
+	}()
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	if err := json.NewEncoder(w).Encode(map[string]string{"deliveryId": deliveryID}); err != nil {
+		log.Printf("could not encode reply: %v", err)
+	}
+}
+
+func (s *Server) callback(uri string, body callbackBody) error {
+	bs, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, uri, bytes.NewReader(bs))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+s.APIKey)
+	}
+	res, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code: %d", res.StatusCode)
+	}
+	return nil
+}
+
+func newDeliveryID() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
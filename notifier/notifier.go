@@ -0,0 +1,148 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+)
+
+const (
+	ActivationClosed  = "closed"
+	ActivationReplied = "replied"
+	ActivationTimeout = "timeout"
+)
+
+type Notification struct {
+	ID       int    `json:"id"`
+	Title    string `json:"title"`
+	Message  string `json:"message"`
+	Callback string `json:"callback,omitempty"`
+
+	// Timeout is how long the user has to answer once the notification is
+	// shown, backends showing one notification at a time start it when its
+	// turn comes rather than when it arrived.
+	Timeout time.Duration `json:"-"`
+}
+
+// answerContext bounds ctx by the time the user has to answer n.
+func (n Notification) answerContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if n.Timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, n.Timeout)
+}
+
+type Reply struct {
+	ActivationType  string `json:"activationType"`
+	ActivationValue string `json:"activationValue,omitempty"`
+}
+
+type Backend interface {
+	Name() string
+	Notify(ctx context.Context, n Notification) (Reply, error)
+	// Interactive reports whether the user can answer the notification,
+	// replies of other backends only mean it was shown.
+	Interactive() bool
+}
+
+type Server struct {
+	backends []Backend
+	timeout  time.Duration
+	client   *http.Client
+
+	AsyncTimeout time.Duration
+	APIKey       string
+}
+
+func NewServer(timeout time.Duration, backends ...Backend) *Server {
+	return &Server{
+		backends:     backends,
+		timeout:      timeout,
+		client:       &http.Client{Timeout: 10 * time.Second},
+		AsyncTimeout: 10 * time.Minute,
+	}
+}
+
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health", s.health)
+	mux.HandleFunc("/notify", s.notify)
+	return mux
+}
+
+func (s *Server) health(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) notify(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	var n Notification
+	if err := json.NewDecoder(r.Body).Decode(&n); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if n.Title == "" {
+		n.Title = "Unknown title"
+	}
+	if n.Message == "" {
+		n.Message = "Unknown message"
+	}
+	if n.Callback != "" {
+		s.notifyAsync(w, n)
+		return
+	}
+	n.Timeout = s.timeout
+	reply := s.dispatch(r.Context(), n)
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(reply); err != nil {
+		log.Printf("could not encode reply: %v", err)
+	}
+}
+
+// dispatch shows the notification on every backend and answers with the
+// first interactive reply, so a click on the desktop wins over a log line.
+// Replies of backends the user cannot answer on only count when no
+// interactive backend is left.
+func (s *Server) dispatch(ctx context.Context, n Notification) Reply {
+	type result struct {
+		reply       Reply
+		interactive bool
+	}
+	results := make(chan result, len(s.backends))
+	for _, b := range s.backends {
+		go func(b Backend) {
+			reply, err := b.Notify(ctx, n)
+			if err != nil {
+				log.Printf("%s backend could not show notification %d: %v", b.Name(), n.ID, err)
+				reply = Reply{ActivationType: ActivationTimeout}
+			}
+			results <- result{reply: reply, interactive: b.Interactive()}
+		}(b)
+	}
+	shown := Reply{ActivationType: ActivationTimeout}
+	for range s.backends {
+		res := <-results
+		switch {
+		case res.reply.ActivationType == ActivationTimeout:
+		case res.interactive:
+			return res.reply
+		default:
+			shown = Reply{ActivationType: ActivationClosed}
+		}
+	}
+	for _, b := range s.backends {
+		if b.Interactive() {
+			return Reply{ActivationType: ActivationTimeout}
+		}
+	}
+	return shown
+}
@@ -8,21 +8,49 @@ import (
 	"time"
 
 	"app-pointment/server/controllers"
+	"app-pointment/server/keyfile"
+	"app-pointment/server/middleware"
 	"app-pointment/server/models"
 	"app-pointment/server/services"
 )
 
+type Config struct {
+	Addr      string
+	Timeout   time.Duration
+	Health    *services.Health
+	Keys      middleware.Authenticator
+	KeyAdmin  *keyfile.Keys
+	Webhooks  *services.Webhooks
+	Events    *services.EventStream
+	Templates *services.Templates
+
+	Idempotency middleware.IdempotencyStore
+}
+
 type Backend struct {
 	server  *http.Server
 	service *services.Reminders
 }
 
-func New(addr string, service *services.Reminders) *Backend {
-	cfg := controllers.RouterConfig{Service: service}
-	router := controllers.NewRouter(cfg)
+func New(cfg Config, service *services.Reminders) *Backend {
+	routes := controllers.RouterConfig{
+		Service:   service,
+		Webhooks:  cfg.Webhooks,
+		Events:    cfg.Events,
+		Templates: cfg.Templates,
+		Health:    cfg.Health,
+		Keys:      cfg.Keys,
+		Timeout:   cfg.Timeout,
+
+		Idempotency: cfg.Idempotency,
+	}
+	if cfg.KeyAdmin != nil {
+		routes.KeyAdmin = cfg.KeyAdmin
+	}
+	router := controllers.NewRouter(routes)
 	return &Backend{
 		server: &http.Server{
-			Addr:    addr,
+			Addr:    cfg.Addr,
 			Handler: router,
 		},
 		service: service,
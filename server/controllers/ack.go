@@ -0,0 +1,97 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"app-pointment/server/models"
+	"app-pointment/server/transport"
+)
+
+type acknowledger interface {
+	Ack(ctx context.Context, p models.Principal, id int, deliveryID string) (models.Reminder, error)
+	Snooze(ctx context.Context, p models.Principal, id int, deliveryID string, d time.Duration) (models.Reminder, error)
+}
+
+// jsonDuration accepts either nanoseconds, like the rest of the API, or a
+// duration string such as "10m" as replied by the notifier.
+type jsonDuration time.Duration
+
+func (d *jsonDuration) UnmarshalJSON(b []byte) error {
+	var n int64
+	if err := json.Unmarshal(b, &n); err == nil {
+		*d = jsonDuration(n)
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("duration must be a number or a string")
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = jsonDuration(v)
+	return nil
+}
+
+type ackBody struct {
+	DeliveryID string       `json:"delivery_id"`
+	Duration   jsonDuration `json:"duration"`
+}
+
+func decodeAckBody(r *http.Request) (ackBody, error) {
+	var body ackBody
+	if r.ContentLength == 0 {
+		return body, nil
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		return body, models.InvalidJSONError{Message: err.Error()}
+	}
+	return body, nil
+}
+
+func ackReminder(service acknowledger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, err := parseIDParam(r.Context())
+		if err != nil {
+			transport.SendError(w, err)
+			return
+		}
+		body, err := decodeAckBody(r)
+		if err != nil {
+			transport.SendError(w, err)
+			return
+		}
+		reminder, err := service.Ack(r.Context(), ctxPrincipal(r.Context()), id, body.DeliveryID)
+		if err != nil {
+			transport.SendError(w, err)
+			return
+		}
+		transport.SendJSON(w, reminder, http.StatusOK)
+	})
+}
+
+func snoozeReminder(service acknowledger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, err := parseIDParam(r.Context())
+		if err != nil {
+			transport.SendError(w, err)
+			return
+		}
+		body, err := decodeAckBody(r)
+		if err != nil {
+			transport.SendError(w, err)
+			return
+		}
+		reminder, err := service.Snooze(r.Context(), ctxPrincipal(r.Context()), id, body.DeliveryID, time.Duration(body.Duration))
+		if err != nil {
+			transport.SendError(w, err)
+			return
+		}
+		transport.SendJSON(w, reminder, http.StatusOK)
+	})
+}
@@ -0,0 +1,102 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"app-pointment/server/models"
+	"app-pointment/server/services"
+	"app-pointment/server/transport"
+)
+
+type batcher interface {
+	Batch(ctx context.Context, p models.Principal, mode string, ops []services.BatchOperation) (models.BatchResponse, error)
+}
+
+func batchReminders(service batcher) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Mode       string `json:"mode"`
+			Operations []struct {
+				Op          string              `json:"op"`
+				ID          int                 `json:"id"`
+				Owner       string              `json:"owner"`
+				Title       string              `json:"title"`
+				Message     string              `json:"message"`
+				Template    string              `json:"template"`
+				Variables   map[string]string   `json:"variables"`
+				Duration    time.Duration       `json:"duration"`
+				Channels    []string            `json:"channels"`
+				RetryPolicy *models.RetryPolicy `json:"retry_policy"`
+
+				IgnoreQuietHours *bool  `json:"ignore_quiet_hours"`
+				EscalationPolicy string `json:"escalation_policy"`
+			} `json:"operations"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			transport.SendError(w, models.InvalidJSONError{Message: err.Error()})
+			return
+		}
+		principal := ctxPrincipal(r.Context())
+		ops := make([]services.BatchOperation, len(body.Operations))
+		for i, op := range body.Operations {
+			owner := principal.User
+			if op.Owner != "" && op.Owner != owner {
+				if !principal.Admin {
+					transport.SendError(w, models.ForbiddenError{
+						Message: "only admins can create reminders for other users",
+					})
+					return
+				}
+				owner = op.Owner
+			}
+			ignoreQuietHours := false
+			if op.IgnoreQuietHours != nil {
+				ignoreQuietHours = *op.IgnoreQuietHours
+			}
+			ops[i] = services.BatchOperation{
+				Op: op.Op,
+				ID: op.ID,
+				Create: services.ReminderCreateBody{
+					Owner:       owner,
+					Title:       op.Title,
+					Message:     op.Message,
+					Template:    op.Template,
+					Variables:   op.Variables,
+					Duration:    op.Duration,
+					Channels:    op.Channels,
+					RetryPolicy: op.RetryPolicy,
+
+					IgnoreQuietHours: ignoreQuietHours,
+					EscalationPolicy: op.EscalationPolicy,
+				},
+				Edit: services.ReminderEditBody{
+					Title:       op.Title,
+					Message:     op.Message,
+					Template:    op.Template,
+					Variables:   op.Variables,
+					Duration:    op.Duration,
+					Channels:    op.Channels,
+					RetryPolicy: op.RetryPolicy,
+
+					IgnoreQuietHours: op.IgnoreQuietHours,
+					EscalationPolicy: op.EscalationPolicy,
+				},
+			}
+		}
+		res, err := service.Batch(r.Context(), principal, body.Mode, ops)
+		if err != nil {
+			transport.SendError(w, err)
+			return
+		}
+		for i, result := range res.Results {
+			if result.Err != nil {
+				e := transport.ErrorBody(result.Err)
+				res.Results[i].Error = &e
+			}
+		}
+		transport.SendJSON(w, res, http.StatusOK)
+	})
+}
@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"app-pointment/server/middleware"
 	"app-pointment/server/models"
 	"context"
 	"fmt"
@@ -16,6 +17,14 @@ func ctxParam(ctx context.Context, key string) urlParam {
 	return ps[key]
 }
 
+func ctxPrincipal(ctx context.Context) models.Principal {
+	key, ok := middleware.APIKeyFromContext(ctx)
+	if !ok {
+		return models.Principal{Admin: true}
+	}
+	return key.Principal()
+}
+
 func parseIDParam(ctx context.Context) (int, error) {
 	id, err := strconv.Atoi(ctxParam(ctx, idParamName).value)
 	if err != nil {
@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"time"
@@ -11,24 +12,51 @@ import (
 )
 
 type creator interface {
-	Create(reminderBody services.ReminderCreateBody) (models.Reminder, error)
+	Create(ctx context.Context, reminderBody services.ReminderCreateBody) (models.Reminder, error)
 }
 
 func createReminder(service creator) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		var body struct {
-			Title    string        `json:"title"`
-			Message  string        `json:"message"`
-			Duration time.Duration `json:"duration"`
+			Owner       string              `json:"owner"`
+			Title       string              `json:"title"`
+			Message     string              `json:"message"`
+			Template    string              `json:"template"`
+			Variables   map[string]string   `json:"variables"`
+			Duration    time.Duration       `json:"duration"`
+			Channels    []string            `json:"channels"`
+			RetryPolicy *models.RetryPolicy `json:"retry_policy"`
+
+			IgnoreQuietHours bool   `json:"ignore_quiet_hours"`
+			EscalationPolicy string `json:"escalation_policy"`
 		}
 		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
 			transport.SendError(w, models.InvalidJSONError{Message: err.Error()})
 			return
 		}
-		reminder, err := service.Create(services.ReminderCreateBody{
-			Title:    body.Title,
-			Message:  body.Message,
-			Duration: body.Duration,
+		principal := ctxPrincipal(r.Context())
+		owner := principal.User
+		if body.Owner != "" && body.Owner != owner {
+			if !principal.Admin {
+				transport.SendError(w, models.ForbiddenError{
+					Message: "only admins can create reminders for other users",
+				})
+				return
+			}
+			owner = body.Owner
+		}
+		reminder, err := service.Create(r.Context(), services.ReminderCreateBody{
+			Owner:       owner,
+			Title:       body.Title,
+			Message:     body.Message,
+			Template:    body.Template,
+			Variables:   body.Variables,
+			Duration:    body.Duration,
+			Channels:    body.Channels,
+			RetryPolicy: body.RetryPolicy,
+
+			IgnoreQuietHours: body.IgnoreQuietHours,
+			EscalationPolicy: body.EscalationPolicy,
 		})
 		if err != nil {
 			transport.SendError(w, err)
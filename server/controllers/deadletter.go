@@ -0,0 +1,41 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+
+	"app-pointment/server/models"
+	"app-pointment/server/transport"
+)
+
+type deadLetterManager interface {
+	DeadLetter(p models.Principal) ([]models.Reminder, error)
+	Requeue(ctx context.Context, p models.Principal, id int) (models.Reminder, error)
+}
+
+func listDeadLetter(service deadLetterManager) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reminders, err := service.DeadLetter(ctxPrincipal(r.Context()))
+		if err != nil {
+			transport.SendError(w, err)
+			return
+		}
+		transport.SendJSON(w, reminders, http.StatusOK)
+	})
+}
+
+func requeueReminder(service deadLetterManager) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, err := parseIDParam(r.Context())
+		if err != nil {
+			transport.SendError(w, err)
+			return
+		}
+		reminder, err := service.Requeue(r.Context(), ctxPrincipal(r.Context()), id)
+		if err != nil {
+			transport.SendError(w, err)
+			return
+		}
+		transport.SendJSON(w, reminder, http.StatusOK)
+	})
+}
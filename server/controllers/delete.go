@@ -1,13 +1,15 @@
 package controllers
 
 import (
+	"context"
 	"net/http"
 
+	"app-pointment/server/models"
 	"app-pointment/server/transport"
 )
 
 type deleter interface {
-	Delete(ids []int) error
+	Delete(ctx context.Context, p models.Principal, ids []int) error
 }
 
 func deleteReminders(service deleter) http.Handler {
@@ -17,7 +19,7 @@ func deleteReminders(service deleter) http.Handler {
 			transport.SendError(w, err)
 			return
 		}
-		err = service.Delete(ids)
+		err = service.Delete(r.Context(), ctxPrincipal(r.Context()), ids)
 		if err != nil {
 			transport.SendError(w, err)
 			return
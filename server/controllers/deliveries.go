@@ -0,0 +1,28 @@
+package controllers
+
+import (
+	"net/http"
+
+	"app-pointment/server/models"
+	"app-pointment/server/transport"
+)
+
+type deliveryLister interface {
+	Deliveries(p models.Principal, id int) ([]models.DeliveryAttempt, error)
+}
+
+func listReminderDeliveries(service deliveryLister) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, err := parseIDParam(r.Context())
+		if err != nil {
+			transport.SendError(w, err)
+			return
+		}
+		attempts, err := service.Deliveries(ctxPrincipal(r.Context()), id)
+		if err != nil {
+			transport.SendError(w, err)
+			return
+		}
+		transport.SendJSON(w, attempts, http.StatusOK)
+	})
+}
@@ -2,6 +2,7 @@ package controllers
 
 import (
 	"app-pointment/server/transport"
+	"context"
 	"encoding/json"
 	"net/http"
 	"time"
@@ -11,7 +12,7 @@ import (
 )
 
 type editor interface {
-	Edit(reminderBody services.ReminderEditBody) (models.Reminder, error)
+	Edit(ctx context.Context, reminderBody services.ReminderEditBody) (models.Reminder, error)
 }
 
 func editReminder(service editor) http.Handler {
@@ -22,19 +23,34 @@ func editReminder(service editor) http.Handler {
 			return
 		}
 		var body struct {
-			Title    string        `json:"title"`
-			Message  string        `json:"message"`
-			Duration time.Duration `json:"duration"`
+			Title       string              `json:"title"`
+			Message     string              `json:"message"`
+			Template    string              `json:"template"`
+			Variables   map[string]string   `json:"variables"`
+			Duration    time.Duration       `json:"duration"`
+			Channels    []string            `json:"channels"`
+			RetryPolicy *models.RetryPolicy `json:"retry_policy"`
+
+			IgnoreQuietHours *bool  `json:"ignore_quiet_hours"`
+			EscalationPolicy string `json:"escalation_policy"`
 		}
 		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
 			transport.SendError(w, models.InvalidJSONError{Message: err.Error()})
 			return
 		}
-		reminder, err := service.Edit(services.ReminderEditBody{
-			ID:       id,
-			Title:    body.Title,
-			Message:  body.Message,
-			Duration: body.Duration,
+		reminder, err := service.Edit(r.Context(), services.ReminderEditBody{
+			Principal:   ctxPrincipal(r.Context()),
+			ID:          id,
+			Title:       body.Title,
+			Message:     body.Message,
+			Template:    body.Template,
+			Variables:   body.Variables,
+			Duration:    body.Duration,
+			Channels:    body.Channels,
+			RetryPolicy: body.RetryPolicy,
+
+			IgnoreQuietHours: body.IgnoreQuietHours,
+			EscalationPolicy: body.EscalationPolicy,
 		})
 		if err != nil {
 			transport.SendError(w, err)
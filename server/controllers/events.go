@@ -0,0 +1,86 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"app-pointment/server/models"
+	"app-pointment/server/transport"
+)
+
+const streamHeartbeat = 15 * time.Second
+
+type eventStreamer interface {
+	Subscribe(lastID int64) ([]models.Event, <-chan models.Event, func())
+}
+
+func streamEvents(stream eventStreamer) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			transport.SendError(w, fmt.Errorf("streaming is not supported"))
+			return
+		}
+		var lastID int64
+		if v := r.Header.Get("Last-Event-ID"); v != "" {
+			id, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				transport.SendError(w, models.DataValidationError{Message: "invalid Last-Event-ID header"})
+				return
+			}
+			lastID = id
+		}
+		principal := ctxPrincipal(r.Context())
+		backlog, events, cancel := stream.Subscribe(lastID)
+		defer cancel()
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		send := func(e models.Event) error {
+			if !principal.CanAccess(e.Reminder) {
+				return nil
+			}
+			bs, err := json.Marshal(e)
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Type, bs)
+			return err
+		}
+		for _, e := range backlog {
+			if err := send(e); err != nil {
+				return
+			}
+		}
+		flusher.Flush()
+
+		heartbeat := time.NewTicker(streamHeartbeat)
+		defer heartbeat.Stop()
+		for {
+			select {
+			case e, ok := <-events:
+				if !ok {
+					return
+				}
+				if err := send(e); err != nil {
+					return
+				}
+				flusher.Flush()
+			case <-heartbeat.C:
+				if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+					return
+				}
+				flusher.Flush()
+			case <-r.Context().Done():
+				return
+			}
+		}
+	})
+}
@@ -0,0 +1,85 @@
+package controllers
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"app-pointment/server/keyfile"
+	"app-pointment/server/models"
+	"app-pointment/server/services"
+)
+
+func TestStreamEvents(t *testing.T) {
+	dir, err := ioutil.TempDir("", "keys")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	keys := keyfile.NewKeys(filepath.Join(dir, "keys.json"))
+	admin, _, err := keys.Create("root", "", []string{models.ScopeAdmin})
+	if err != nil {
+		t.Fatal(err)
+	}
+	alice, _, err := keys.Create("alice", "", []string{models.ScopeRemindersRead})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stream := services.NewEventStream(10)
+	events := services.NewEvents()
+	events.Subscribe(stream.Handle)
+	events.Publish(models.EventReminderCreated, models.Reminder{ID: 1, Owner: "alice"})
+	events.Publish(models.EventReminderCreated, models.Reminder{ID: 2, Owner: "bob"})
+	events.Publish(models.EventReminderFired, models.Reminder{ID: 1, Owner: "alice"})
+	router := NewRouter(RouterConfig{Service: &services.Reminders{}, Keys: keys, Events: stream})
+
+	tests := []struct {
+		name   string
+		key    string
+		lastID string
+		code   int
+		want   []string
+	}{
+		{"admin sees every owner", admin, "", http.StatusOK, []string{"id: 1", "id: 2", "id: 3"}},
+		{"owner sees own reminders", alice, "", http.StatusOK, []string{"id: 1", "id: 3"}},
+		{"resume after last event id", alice, "1", http.StatusOK, []string{"id: 3"}},
+		{"invalid last event id", alice, "latest", http.StatusBadRequest, nil},
+		{"without key", "", "", http.StatusUnauthorized, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// the stream ends once the backlog is sent
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			req := httptest.NewRequest(http.MethodGet, "/events", nil).WithContext(ctx)
+			if tt.key != "" {
+				req.Header.Set("X-API-Key", tt.key)
+			}
+			if tt.lastID != "" {
+				req.Header.Set("Last-Event-ID", tt.lastID)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			if rec.Code != tt.code {
+				t.Fatalf("got status %d, want %d: %s", rec.Code, tt.code, rec.Body)
+			}
+			if tt.want == nil {
+				return
+			}
+			var got []string
+			for _, line := range strings.Split(rec.Body.String(), "\n") {
+				if strings.HasPrefix(line, "id: ") {
+					got = append(got, line)
+				}
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("streamed %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
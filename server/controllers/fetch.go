@@ -2,13 +2,15 @@ package controllers
 
 import (
 	"net/http"
+	"strings"
 
 	"app-pointment/server/models"
 	"app-pointment/server/transport"
 )
 
 type lister interface {
-	List(ids []int) ([]models.Reminder, error)
+	List(p models.Principal, ids []int) ([]models.Reminder, error)
+	ListAll(p models.Principal, statuses []string) ([]models.Reminder, error)
 }
 
 func listReminders(service lister) http.Handler {
@@ -18,7 +20,26 @@ func listReminders(service lister) http.Handler {
 			transport.SendError(w, err)
 			return
 		}
-		reminders, err := service.List(ids)
+		reminders, err := service.List(ctxPrincipal(r.Context()), ids)
+		if err != nil {
+			transport.SendError(w, err)
+			return
+		}
+		transport.SendJSON(w, reminders, http.StatusOK)
+	})
+}
+
+func listAllReminders(service lister) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var statuses []string
+		for _, v := range r.URL.Query()["status"] {
+			for _, status := range strings.Split(v, ",") {
+				if status = strings.TrimSpace(status); status != "" {
+					statuses = append(statuses, status)
+				}
+			}
+		}
+		reminders, err := service.ListAll(ctxPrincipal(r.Context()), statuses)
 		if err != nil {
 			transport.SendError(w, err)
 			return
@@ -2,10 +2,29 @@ package controllers
 
 import (
 	"net/http"
+
+	"app-pointment/server/models"
+	"app-pointment/server/transport"
 )
 
-func health() http.Handler {
+type healthReporter interface {
+	Live() models.Health
+	Ready() models.Health
+}
+
+func health(service healthReporter) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		transport.SendJSON(w, service.Live(), http.StatusOK)
+	})
+}
+
+func readiness(service healthReporter) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
+		report := service.Ready()
+		code := http.StatusOK
+		if report.Status != models.HealthUp {
+			code = http.StatusServiceUnavailable
+		}
+		transport.SendJSON(w, report, code)
 	})
 }
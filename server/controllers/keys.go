@@ -0,0 +1,125 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"app-pointment/server/models"
+	"app-pointment/server/transport"
+)
+
+type keyManager interface {
+	Create(name, user string, scopes []string) (string, models.APIKey, error)
+	List() ([]models.APIKey, error)
+	Revoke(id string) (models.APIKey, error)
+	Users() ([]models.User, error)
+	SetUser(user models.User) error
+}
+
+// keyResponse leaves out the hash of the key, the plain key is only sent
+// once when the key is created.
+type keyResponse struct {
+	ID        string     `json:"id"`
+	Name      string     `json:"name"`
+	User      string     `json:"user"`
+	Scopes    []string   `json:"scopes"`
+	Key       string     `json:"key,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+func newKeyResponse(key models.APIKey) keyResponse {
+	return keyResponse{
+		ID:        key.ID,
+		Name:      key.Name,
+		User:      key.User,
+		Scopes:    key.Scopes,
+		CreatedAt: key.CreatedAt,
+		RevokedAt: key.RevokedAt,
+	}
+}
+
+func createKey(service keyManager) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Name   string   `json:"name"`
+			User   string   `json:"user"`
+			Scopes []string `json:"scopes"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			transport.SendError(w, models.InvalidJSONError{Message: err.Error()})
+			return
+		}
+		plain, key, err := service.Create(body.Name, body.User, body.Scopes)
+		if err != nil {
+			transport.SendError(w, err)
+			return
+		}
+		res := newKeyResponse(key)
+		res.Key = plain
+		transport.SendJSON(w, res, http.StatusCreated)
+	})
+}
+
+func listKeys(service keyManager) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys, err := service.List()
+		if err != nil {
+			transport.SendError(w, err)
+			return
+		}
+		res := make([]keyResponse, 0, len(keys))
+		for _, key := range keys {
+			res = append(res, newKeyResponse(key))
+		}
+		transport.SendJSON(w, res, http.StatusOK)
+	})
+}
+
+func revokeKey(service keyManager) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key, err := service.Revoke(ctxParam(r.Context(), nameParamName).value)
+		if err != nil {
+			transport.SendError(w, err)
+			return
+		}
+		transport.SendJSON(w, newKeyResponse(key), http.StatusOK)
+	})
+}
+
+func listUsers(service keyManager) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		users, err := service.Users()
+		if err != nil {
+			transport.SendError(w, err)
+			return
+		}
+		if users == nil {
+			users = []models.User{}
+		}
+		transport.SendJSON(w, users, http.StatusOK)
+	})
+}
+
+func setUser(service keyManager) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var user models.User
+		if err := json.NewDecoder(r.Body).Decode(&user); err != nil {
+			transport.SendError(w, models.InvalidJSONError{Message: err.Error()})
+			return
+		}
+		user.Name = ctxParam(r.Context(), nameParamName).value
+		if user.QuietHours != nil {
+			if err := user.QuietHours.Validate(); err != nil {
+				transport.SendError(w, err)
+				return
+			}
+		}
+		if err := service.SetUser(user); err != nil {
+			transport.SendError(w, err)
+			return
+		}
+		transport.SendJSON(w, user, http.StatusOK)
+	})
+}
@@ -0,0 +1,92 @@
+package controllers
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"app-pointment/server/keyfile"
+	"app-pointment/server/models"
+	"app-pointment/server/services"
+)
+
+func TestAdminRoutesRequireAdminScope(t *testing.T) {
+	dir, err := ioutil.TempDir("", "keys")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	keys := keyfile.NewKeys(filepath.Join(dir, "keys.json"))
+	admin, _, err := keys.Create("root", "", []string{models.ScopeAdmin})
+	if err != nil {
+		t.Fatal(err)
+	}
+	writer, _, err := keys.Create("bob", "", []string{models.ScopeRemindersRead, models.ScopeRemindersWrite})
+	if err != nil {
+		t.Fatal(err)
+	}
+	router := NewRouter(RouterConfig{Service: &services.Reminders{}, Keys: keys, KeyAdmin: keys})
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		key    string
+		code   int
+	}{
+		{"list keys without key", http.MethodGet, "/keys", "", "", http.StatusUnauthorized},
+		{"list keys with write scope", http.MethodGet, "/keys", "", writer, http.StatusForbidden},
+		{"create key with write scope", http.MethodPost, "/keys", `{"name":"eve","scopes":["admin"]}`, writer, http.StatusForbidden},
+		{"set user with write scope", http.MethodPut, "/users/bob", `{}`, writer, http.StatusForbidden},
+		{"list keys as admin", http.MethodGet, "/keys", "", admin, http.StatusOK},
+		{"create key as admin", http.MethodPost, "/keys", `{"name":"eve","scopes":["reminders:read"]}`, admin, http.StatusCreated},
+		{"invalid scope", http.MethodPost, "/keys", `{"name":"eve","scopes":["root"]}`, admin, http.StatusBadRequest},
+		{"set user as admin", http.MethodPut, "/users/bob", `{"notifier":"http://bob:9000"}`, admin, http.StatusOK},
+		{"list users as admin", http.MethodGet, "/users", "", admin, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			if tt.key != "" {
+				req.Header.Set("X-API-Key", tt.key)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			if rec.Code != tt.code {
+				t.Errorf("status = %d, want %d: %s", rec.Code, tt.code, rec.Body)
+			}
+			if strings.Contains(rec.Body.String(), `"hash"`) {
+				t.Errorf("response contains key hashes: %s", rec.Body)
+			}
+		})
+	}
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/keys", nil)
+	req.Header.Set("X-API-Key", admin)
+	router.ServeHTTP(rec, req)
+	var listed []keyResponse
+	if err := json.NewDecoder(rec.Body).Decode(&listed); err != nil {
+		t.Fatal(err)
+	}
+	if len(listed) != 3 {
+		t.Fatalf("listed %d keys, want 3", len(listed))
+	}
+
+	rec = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodDelete, "/keys/"+listed[1].ID, nil)
+	req.Header.Set("X-API-Key", admin)
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("revoke status = %d: %s", rec.Code, rec.Body)
+	}
+	if _, err := keys.Authenticate(writer); err == nil {
+		t.Error("revoked key still authenticates")
+	}
+}
@@ -0,0 +1,61 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"app-pointment/server/models"
+	"app-pointment/server/transport"
+)
+
+type lifecycleManager interface {
+	Complete(ctx context.Context, p models.Principal, id int) (models.Reminder, error)
+	Dismiss(ctx context.Context, p models.Principal, id int) (models.Reminder, error)
+	Reopen(ctx context.Context, p models.Principal, id int, d time.Duration) (models.Reminder, error)
+}
+
+func completeReminder(service lifecycleManager) http.Handler {
+	return transition(service.Complete)
+}
+
+func dismissReminder(service lifecycleManager) http.Handler {
+	return transition(service.Dismiss)
+}
+
+func reopenReminder(service lifecycleManager) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, err := parseIDParam(r.Context())
+		if err != nil {
+			transport.SendError(w, err)
+			return
+		}
+		body, err := decodeAckBody(r)
+		if err != nil {
+			transport.SendError(w, err)
+			return
+		}
+		reminder, err := service.Reopen(r.Context(), ctxPrincipal(r.Context()), id, time.Duration(body.Duration))
+		if err != nil {
+			transport.SendError(w, err)
+			return
+		}
+		transport.SendJSON(w, reminder, http.StatusOK)
+	})
+}
+
+func transition(fn func(ctx context.Context, p models.Principal, id int) (models.Reminder, error)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, err := parseIDParam(r.Context())
+		if err != nil {
+			transport.SendError(w, err)
+			return
+		}
+		reminder, err := fn(r.Context(), ctxPrincipal(r.Context()), id)
+		if err != nil {
+			transport.SendError(w, err)
+			return
+		}
+		transport.SendJSON(w, reminder, http.StatusOK)
+	})
+}
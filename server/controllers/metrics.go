@@ -0,0 +1,68 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"app-pointment/server/metrics"
+)
+
+const unmatchedRoute = "unmatched"
+
+var (
+	httpRequests = metrics.NewCounterVec(
+		"app_pointment_http_requests_total",
+		"Total number of HTTP requests by route, method and status code.",
+		"route", "method", "code",
+	)
+	httpDuration = metrics.NewHistogramVec(
+		"app_pointment_http_request_duration_seconds",
+		"HTTP request latency by route and method.",
+		metrics.DefaultBuckets,
+		"route", "method",
+	)
+)
+
+type statusRecorder struct {
+	http.ResponseWriter
+	code int
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	if r.code == 0 {
+		r.code = code
+	}
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *statusRecorder) Write(bs []byte) (int, error) {
+	if r.code == 0 {
+		r.code = http.StatusOK
+	}
+	return r.ResponseWriter.Write(bs)
+}
+
+func (r *statusRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func instrument(routeName string, w http.ResponseWriter, r *http.Request, next func(http.ResponseWriter)) {
+	start := time.Now()
+	rec := &statusRecorder{ResponseWriter: w}
+	defer func() {
+		code := rec.code
+		if code == 0 {
+			code = http.StatusOK
+		}
+		httpRequests.With(routeName, r.Method, strconv.Itoa(code)).Inc()
+		httpDuration.With(routeName, r.Method).ObserveSince(start)
+	}()
+	next(rec)
+}
+
+func metricsHandler() http.Handler {
+	return metrics.Default.Handler()
+}
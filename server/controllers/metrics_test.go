@@ -0,0 +1,69 @@
+package controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestInstrumentRouteLabels(t *testing.T) {
+	r := RegexpMux{}
+	r.Get("/instrumented/"+idParam, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	r.Post("/instrumented", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/instrumented/1", nil),
+		httptest.NewRequest(http.MethodGet, "/instrumented/22", nil),
+		httptest.NewRequest(http.MethodPost, "/instrumented", nil),
+		httptest.NewRequest(http.MethodDelete, "/instrumented/x", nil),
+	} {
+		r.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	body := scrape(t)
+	for _, want := range []string{
+		// the id is replaced by its parameter name so routes do not explode the series
+		`app_pointment_http_requests_total{route="/instrumented/{id}",method="GET",code="202"} 2`,
+		`app_pointment_http_requests_total{route="/instrumented",method="POST",code="200"} 1`,
+		`app_pointment_http_requests_total{route="unmatched",method="DELETE",code="404"} 1`,
+		`app_pointment_http_request_duration_seconds_count{route="/instrumented/{id}",method="GET"} 2`,
+	} {
+		if !strings.Contains(body, want+"\n") {
+			t.Errorf("metrics do not contain %s", want)
+		}
+	}
+	if strings.Contains(body, `route="/instrumented/1"`) {
+		t.Error("metrics contain the raw request path as route label")
+	}
+}
+
+func TestMetricsHandler(t *testing.T) {
+	rec := httptest.NewRecorder()
+	metricsHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if got := rec.Header().Get("Content-Type"); !strings.HasPrefix(got, "text/plain; version=0.0.4") {
+		t.Errorf("Content-Type = %q, want the Prometheus text format", got)
+	}
+	for _, want := range []string{
+		"# TYPE app_pointment_http_requests_total counter\n",
+		"# TYPE app_pointment_http_request_duration_seconds histogram\n",
+	} {
+		if !strings.Contains(rec.Body.String(), want) {
+			t.Errorf("metrics do not contain %q", want)
+		}
+	}
+}
+
+func scrape(t *testing.T) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	metricsHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	return rec.Body.String()
+}
@@ -22,6 +22,7 @@ type urlParam struct {
 }
 
 type route struct {
+	name    string
 	path    string
 	method  string
 	params  map[string]urlParam
@@ -78,6 +79,7 @@ func (h *RegexpMux) Delete(pattern string, handler http.Handler) {
 func (h *RegexpMux) Handle(method, pattern string, handler http.Handler) {
 	ps := h.params(pattern)
 	r := &route{
+		name:    routeName(pattern),
 		method:  method,
 		path:    pattern,
 		params:  ps,
@@ -95,14 +97,18 @@ func (h RegexpMux) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	key := r.Method + r.URL.Path
 	route, ok := h.routesMap[key]
 	if !ok {
-		transport.SendError(w, models.NotFoundError{})
+		instrument(unmatchedRoute, w, r, func(w http.ResponseWriter) {
+			transport.SendError(w, models.NotFoundError{})
+		})
 		return
 	}
 	ctx := r.Context()
 	if len(route.params) != 0 {
 		ctx = context.WithValue(ctx, ctxKey(paramsKey), route.params)
 	}
-	route.handler.ServeHTTP(w, r.WithContext(ctx))
+	instrument(route.name, w, r, func(w http.ResponseWriter) {
+		route.handler.ServeHTTP(w, r.WithContext(ctx))
+	})
 }
 
 func (h RegexpMux) params(url string) map[string]urlParam {
@@ -144,6 +150,15 @@ func (h RegexpMux) parseParam(url, regexParam string) urlParam {
 	}
 }
 
+func routeName(pattern string) string {
+	r := regexp.MustCompile(`^({[a-z]+}):.+$`)
+	parts := splitURL(pattern)
+	for i, p := range parts {
+		parts[i] = r.ReplaceAllString(p, "$1")
+	}
+	return "/" + strings.Join(parts, "/")
+}
+
 func splitURL(s string) []string {
 	var res []string
 	for _, p := range strings.Split(strings.TrimSpace(s), "/") {
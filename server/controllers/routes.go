@@ -2,37 +2,98 @@ package controllers
 
 import (
 	"net/http"
+	"time"
 
 	"app-pointment/server/middleware"
+	"app-pointment/server/models"
 )
 
 const (
-	idParamName  = "id"
-	idsParamName = "ids"
-	idParam      = `{` + idParamName + `}:^[0-9]+$`
-	idsParam     = `{` + idsParamName + `}:[0-9]+(,[0-9]+)*`
+	idParamName   = "id"
+	idsParamName  = "ids"
+	nameParamName = "name"
+	idParam       = `{` + idParamName + `}:^[0-9]+$`
+	idsParam      = `{` + idsParamName + `}:[0-9]+(,[0-9]+)*`
+	nameParam     = `{` + nameParamName + `}:^[a-zA-Z0-9_.-]+$`
 )
 
 type RemindersService interface {
 	creator
 	editor
+	batcher
 	lister
 	deleter
+	deadLetterManager
+	acknowledger
+	lifecycleManager
+	deliveryLister
 }
 
 type RouterConfig struct {
-	Service RemindersService
+	Service   RemindersService
+	Webhooks  webhookManager
+	Events    eventStreamer
+	Templates templateManager
+	Health    healthReporter
+	Keys      middleware.Authenticator
+	KeyAdmin  keyManager
+	Timeout   time.Duration
+
+	Idempotency middleware.IdempotencyStore
 }
 
 func NewRouter(cfg RouterConfig) http.Handler {
 	r := RegexpMux{}
 	m := middleware.New(
+		middleware.RequestID,
+		middleware.HTTPLogger,
+		middleware.Timeout(cfg.Timeout),
+		middleware.Recovery,
+	)
+	// The event stream is long lived, it must not be cut by the timeout.
+	stream := middleware.New(
+		middleware.RequestID,
 		middleware.HTTPLogger,
+		middleware.Recovery,
+		middleware.Authenticate(cfg.Keys, models.ScopeRemindersRead),
 	)
-	r.Get("/health", m.Then(health()))
-	r.Post("/reminders", m.Then(createReminder(cfg.Service)))
-	r.Get("/reminders/"+idsParam, m.Then(listReminders(cfg.Service)))
-	r.Delete("/reminders/"+idsParam, m.Then(deleteReminders(cfg.Service)))
-	r.Patch("/reminders/"+idParam, m.Then(editReminder(cfg.Service)))
+	read := m.With(middleware.Authenticate(cfg.Keys, models.ScopeRemindersRead))
+	write := m.With(middleware.Authenticate(cfg.Keys, models.ScopeRemindersWrite))
+	admin := m.With(middleware.Authenticate(cfg.Keys, models.ScopeAdmin))
+	r.Get("/health", m.Then(health(cfg.Health)))
+	r.Get("/health/live", m.Then(health(cfg.Health)))
+	r.Get("/health/ready", m.Then(readiness(cfg.Health)))
+	r.Get("/metrics", m.Then(metricsHandler()))
+	r.Get("/events", stream.Then(streamEvents(cfg.Events)))
+	r.Post("/reminders", write.With(middleware.Idempotency(cfg.Idempotency)).Then(createReminder(cfg.Service)))
+	r.Post("/reminders:batch", write.With(middleware.Idempotency(cfg.Idempotency)).Then(batchReminders(cfg.Service)))
+	r.Get("/reminders", read.Then(listAllReminders(cfg.Service)))
+	r.Get("/reminders/dead-letter", read.Then(listDeadLetter(cfg.Service)))
+	r.Get("/reminders/"+idsParam, read.Then(listReminders(cfg.Service)))
+	r.Delete("/reminders/"+idsParam, write.Then(deleteReminders(cfg.Service)))
+	r.Patch("/reminders/"+idParam, write.Then(editReminder(cfg.Service)))
+	r.Get("/reminders/"+idParam+"/deliveries", read.Then(listReminderDeliveries(cfg.Service)))
+	r.Post("/reminders/"+idParam+"/requeue", write.Then(requeueReminder(cfg.Service)))
+	r.Post("/reminders/"+idParam+"/ack", write.Then(ackReminder(cfg.Service)))
+	r.Post("/reminders/"+idParam+"/snooze", write.Then(snoozeReminder(cfg.Service)))
+	r.Post("/reminders/"+idParam+"/complete", write.Then(completeReminder(cfg.Service)))
+	r.Post("/reminders/"+idParam+"/dismiss", write.Then(dismissReminder(cfg.Service)))
+	r.Post("/reminders/"+idParam+"/reopen", write.Then(reopenReminder(cfg.Service)))
+	r.Post("/templates", write.Then(createTemplate(cfg.Templates)))
+	r.Get("/templates", read.Then(listTemplates(cfg.Templates)))
+	r.Get("/templates/"+nameParam, read.Then(getTemplate(cfg.Templates)))
+	r.Put("/templates/"+nameParam, write.Then(updateTemplate(cfg.Templates)))
+	r.Delete("/templates/"+nameParam, write.Then(deleteTemplate(cfg.Templates)))
+	r.Post("/webhooks", write.Then(createWebhook(cfg.Webhooks)))
+	r.Get("/webhooks", read.Then(listWebhooks(cfg.Webhooks)))
+	r.Delete("/webhooks/"+idParam, write.Then(deleteWebhook(cfg.Webhooks)))
+	r.Get("/webhooks/"+idParam+"/deliveries", read.Then(listWebhookDeliveries(cfg.Webhooks)))
+	if cfg.KeyAdmin != nil {
+		r.Post("/keys", admin.Then(createKey(cfg.KeyAdmin)))
+		r.Get("/keys", admin.Then(listKeys(cfg.KeyAdmin)))
+		r.Delete("/keys/"+nameParam, admin.Then(revokeKey(cfg.KeyAdmin)))
+		r.Get("/users", admin.Then(listUsers(cfg.KeyAdmin)))
+		r.Put("/users/"+nameParam, admin.Then(setUser(cfg.KeyAdmin)))
+	}
 	return r
 }
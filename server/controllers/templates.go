@@ -0,0 +1,98 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"app-pointment/server/models"
+	"app-pointment/server/services"
+	"app-pointment/server/transport"
+)
+
+type templateManager interface {
+	Create(body services.TemplateBody) (models.Template, error)
+	Update(body services.TemplateBody) (models.Template, error)
+	Get(p models.Principal, name string) (models.Template, error)
+	List(p models.Principal) ([]models.Template, error)
+	Delete(p models.Principal, name string) error
+}
+
+type templateBody struct {
+	Name    string `json:"name"`
+	Title   string `json:"title"`
+	Message string `json:"message"`
+}
+
+func createTemplate(service templateManager) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body templateBody
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			transport.SendError(w, models.InvalidJSONError{Message: err.Error()})
+			return
+		}
+		t, err := service.Create(services.TemplateBody{
+			Principal: ctxPrincipal(r.Context()),
+			Name:      body.Name,
+			Title:     body.Title,
+			Message:   body.Message,
+		})
+		if err != nil {
+			transport.SendError(w, err)
+			return
+		}
+		transport.SendJSON(w, t, http.StatusCreated)
+	})
+}
+
+func updateTemplate(service templateManager) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body templateBody
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			transport.SendError(w, models.InvalidJSONError{Message: err.Error()})
+			return
+		}
+		t, err := service.Update(services.TemplateBody{
+			Principal: ctxPrincipal(r.Context()),
+			Name:      ctxParam(r.Context(), nameParamName).value,
+			Title:     body.Title,
+			Message:   body.Message,
+		})
+		if err != nil {
+			transport.SendError(w, err)
+			return
+		}
+		transport.SendJSON(w, t, http.StatusOK)
+	})
+}
+
+func getTemplate(service templateManager) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t, err := service.Get(ctxPrincipal(r.Context()), ctxParam(r.Context(), nameParamName).value)
+		if err != nil {
+			transport.SendError(w, err)
+			return
+		}
+		transport.SendJSON(w, t, http.StatusOK)
+	})
+}
+
+func listTemplates(service templateManager) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		templates, err := service.List(ctxPrincipal(r.Context()))
+		if err != nil {
+			transport.SendError(w, err)
+			return
+		}
+		transport.SendJSON(w, templates, http.StatusOK)
+	})
+}
+
+func deleteTemplate(service templateManager) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := service.Delete(ctxPrincipal(r.Context()), ctxParam(r.Context(), nameParamName).value); err != nil {
+			transport.SendError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
@@ -0,0 +1,84 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"app-pointment/server/models"
+	"app-pointment/server/services"
+	"app-pointment/server/transport"
+)
+
+type webhookManager interface {
+	Create(body services.WebhookCreateBody) (models.Webhook, error)
+	List(p models.Principal) ([]models.Webhook, error)
+	Delete(p models.Principal, id int) error
+	Deliveries(p models.Principal, id int) ([]models.WebhookDelivery, error)
+}
+
+func createWebhook(service webhookManager) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			URL    string   `json:"url"`
+			Events []string `json:"events"`
+			Secret string   `json:"secret"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			transport.SendError(w, models.InvalidJSONError{Message: err.Error()})
+			return
+		}
+		webhook, err := service.Create(services.WebhookCreateBody{
+			Principal: ctxPrincipal(r.Context()),
+			URL:       body.URL,
+			Events:    body.Events,
+			Secret:    body.Secret,
+		})
+		if err != nil {
+			transport.SendError(w, err)
+			return
+		}
+		transport.SendJSON(w, webhook, http.StatusCreated)
+	})
+}
+
+func listWebhooks(service webhookManager) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		webhooks, err := service.List(ctxPrincipal(r.Context()))
+		if err != nil {
+			transport.SendError(w, err)
+			return
+		}
+		transport.SendJSON(w, webhooks, http.StatusOK)
+	})
+}
+
+func deleteWebhook(service webhookManager) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, err := parseIDParam(r.Context())
+		if err != nil {
+			transport.SendError(w, err)
+			return
+		}
+		if err := service.Delete(ctxPrincipal(r.Context()), id); err != nil {
+			transport.SendError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+func listWebhookDeliveries(service webhookManager) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, err := parseIDParam(r.Context())
+		if err != nil {
+			transport.SendError(w, err)
+			return
+		}
+		deliveries, err := service.Deliveries(ctxPrincipal(r.Context()), id)
+		if err != nil {
+			transport.SendError(w, err)
+			return
+		}
+		transport.SendJSON(w, deliveries, http.StatusOK)
+	})
+}
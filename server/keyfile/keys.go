@@ -0,0 +1,265 @@
+// Package keyfile stores API keys and users in a JSON file shared by the
+// server and the CLI, it only depends on the models.
+package keyfile
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"app-pointment/server/models"
+)
+
+const keyPrefix = "ak_"
+
+type keysFile struct {
+	Keys  []models.APIKey `json:"keys"`
+	Users []models.User   `json:"users,omitempty"`
+}
+
+type Keys struct {
+	path    string
+	mu      sync.Mutex
+	modTime time.Time
+	file    keysFile
+}
+
+func NewKeys(path string) *Keys {
+	return &Keys{path: path}
+}
+
+func (k *Keys) Authenticate(plain string) (models.APIKey, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if err := k.load(); err != nil {
+		return models.APIKey{}, err
+	}
+	id, _, ok := splitKey(plain)
+	if !ok {
+		return models.APIKey{}, models.UnauthorizedError{Message: "malformed api key"}
+	}
+	hash := hashKey(plain)
+	for _, key := range k.file.Keys {
+		if key.ID != id {
+			continue
+		}
+		if subtle.ConstantTimeCompare([]byte(key.Hash), []byte(hash)) != 1 {
+			break
+		}
+		if key.Revoked() {
+			return models.APIKey{}, models.UnauthorizedError{Message: "api key was revoked"}
+		}
+		return key, nil
+	}
+	return models.APIKey{}, models.UnauthorizedError{Message: "invalid api key"}
+}
+
+func (k *Keys) Create(name, user string, scopes []string) (string, models.APIKey, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if err := k.load(); err != nil {
+		return "", models.APIKey{}, err
+	}
+	if len(scopes) == 0 {
+		return "", models.APIKey{}, models.DataValidationError{Message: "at least one scope is required"}
+	}
+	for _, scope := range scopes {
+		if !models.ValidScope(scope) {
+			err := models.DataValidationError{
+				Message: fmt.Sprintf("invalid scope %q, expected one of %v", scope, models.Scopes),
+			}
+			return "", models.APIKey{}, err
+		}
+	}
+	id, err := randomHex(4)
+	if err != nil {
+		return "", models.APIKey{}, err
+	}
+	secret, err := randomHex(24)
+	if err != nil {
+		return "", models.APIKey{}, err
+	}
+	plain := keyPrefix + id + "." + secret
+	key := models.APIKey{
+		ID:        id,
+		Name:      name,
+		User:      user,
+		Hash:      hashKey(plain),
+		Scopes:    scopes,
+		CreatedAt: time.Now(),
+	}
+	k.file.Keys = append(k.file.Keys, key)
+	if err := k.save(); err != nil {
+		return "", models.APIKey{}, err
+	}
+	return plain, key, nil
+}
+
+func (k *Keys) List() ([]models.APIKey, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if err := k.load(); err != nil {
+		return nil, err
+	}
+	return append([]models.APIKey(nil), k.file.Keys...), nil
+}
+
+func (k *Keys) Revoke(id string) (models.APIKey, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if err := k.load(); err != nil {
+		return models.APIKey{}, err
+	}
+	for i, key := range k.file.Keys {
+		if key.ID != id {
+			continue
+		}
+		if !key.Revoked() {
+			now := time.Now()
+			key.RevokedAt = &now
+			k.file.Keys[i] = key
+			if err := k.save(); err != nil {
+				return models.APIKey{}, err
+			}
+		}
+		return key, nil
+	}
+	return models.APIKey{}, models.NotFoundError{Message: fmt.Sprintf("could not find api key with id: %s", id)}
+}
+
+func (k *Keys) Users() ([]models.User, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if err := k.load(); err != nil {
+		return nil, err
+	}
+	return append([]models.User(nil), k.file.Users...), nil
+}
+
+func (k *Keys) SetUser(user models.User) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if err := k.load(); err != nil {
+		return err
+	}
+	if strings.TrimSpace(user.Name) == "" {
+		return models.DataValidationError{Message: "user name cannot be empty"}
+	}
+	for i, u := range k.file.Users {
+		if u.Name == user.Name {
+			k.file.Users[i] = user
+			return k.save()
+		}
+	}
+	k.file.Users = append(k.file.Users, user)
+	return k.save()
+}
+
+func (k *Keys) NotifierURI(user string) (string, bool) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if err := k.load(); err != nil {
+		log.Printf("could not load users: %v", err)
+		return "", false
+	}
+	for _, u := range k.file.Users {
+		if u.Name == user && u.Notifier != "" {
+			return u.Notifier, true
+		}
+	}
+	return "", false
+}
+
+func (k *Keys) QuietHours(user string) (models.QuietHours, bool) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if err := k.load(); err != nil {
+		log.Printf("could not load users: %v", err)
+		return models.QuietHours{}, false
+	}
+	for _, u := range k.file.Users {
+		if u.Name == user && u.QuietHours != nil {
+			return *u.QuietHours, true
+		}
+	}
+	return models.QuietHours{}, false
+}
+
+func (k *Keys) load() error {
+	info, err := os.Stat(k.path)
+	if errors.Is(err, os.ErrNotExist) {
+		k.file = keysFile{}
+		k.modTime = time.Time{}
+		return nil
+	}
+	if err != nil {
+		return models.WrapError("could not stat keys file", err)
+	}
+	if info.ModTime().Equal(k.modTime) {
+		return nil
+	}
+	bs, err := ioutil.ReadFile(k.path)
+	if err != nil {
+		return models.WrapError("could not read keys file", err)
+	}
+	var file keysFile
+	if len(bs) > 0 {
+		if err := json.Unmarshal(bs, &file); err != nil {
+			return models.WrapError("could not unmarshal keys file", err)
+		}
+	}
+	k.file = file
+	k.modTime = info.ModTime()
+	return nil
+}
+
+func (k *Keys) save() error {
+	bs, err := json.MarshalIndent(k.file, "", "  ")
+	if err != nil {
+		return models.WrapError("could not marshal keys file", err)
+	}
+	bs = append(bs, '\n')
+	if err := ioutil.WriteFile(k.path, bs, 0600); err != nil {
+		return models.WrapError("could not write keys file", err)
+	}
+	info, err := os.Stat(k.path)
+	if err != nil {
+		return models.WrapError("could not stat keys file", err)
+	}
+	k.modTime = info.ModTime()
+	return nil
+}
+
+func splitKey(plain string) (string, string, bool) {
+	if !strings.HasPrefix(plain, keyPrefix) {
+		return "", "", false
+	}
+	parts := strings.SplitN(strings.TrimPrefix(plain, keyPrefix), ".", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
+
+func hashKey(plain string) string {
+	sum := sha256.Sum256([]byte(plain))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomHex(n int) (string, error) {
+	bs := make([]byte, n)
+	if _, err := rand.Read(bs); err != nil {
+		return "", models.WrapError("could not generate random bytes", err)
+	}
+	return hex.EncodeToString(bs), nil
+}
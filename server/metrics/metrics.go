@@ -0,0 +1,352 @@
+package metrics
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+type desc struct {
+	metricName string
+	metricHelp string
+	labelNames []string
+}
+
+func (d desc) name() string {
+	return d.metricName
+}
+
+func (d desc) help() string {
+	return d.metricHelp
+}
+
+func (d desc) labels(values []string) []label {
+	if len(values) != len(d.labelNames) {
+		panic("metrics: wrong number of label values for " + d.metricName)
+	}
+	ls := make([]label, len(values))
+	for i, v := range values {
+		ls[i] = label{name: d.labelNames[i], value: v}
+	}
+	return ls
+}
+
+type Counter struct {
+	mu    sync.Mutex
+	value float64
+}
+
+func (c *Counter) Inc() {
+	c.Add(1)
+}
+
+func (c *Counter) Add(v float64) {
+	if v < 0 {
+		panic("metrics: counters cannot decrease")
+	}
+	c.mu.Lock()
+	c.value += v
+	c.mu.Unlock()
+}
+
+func (c *Counter) get() float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.value
+}
+
+type Gauge struct {
+	mu    sync.Mutex
+	value float64
+}
+
+func (g *Gauge) Set(v float64) {
+	g.mu.Lock()
+	g.value = v
+	g.mu.Unlock()
+}
+
+func (g *Gauge) Add(v float64) {
+	g.mu.Lock()
+	g.value += v
+	g.mu.Unlock()
+}
+
+func (g *Gauge) get() float64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.value
+}
+
+type Histogram struct {
+	mu      sync.Mutex
+	buckets []float64
+	counts  []uint64
+	sum     float64
+	count   uint64
+}
+
+func newHistogram(buckets []float64) *Histogram {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	bs := append([]float64(nil), buckets...)
+	sort.Float64s(bs)
+	return &Histogram{buckets: bs, counts: make([]uint64, len(bs))}
+}
+
+func (h *Histogram) Observe(v float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i, upper := range h.buckets {
+		if v <= upper {
+			h.counts[i]++
+		}
+	}
+	h.sum += v
+	h.count++
+}
+
+func (h *Histogram) ObserveSince(start time.Time) {
+	h.Observe(time.Since(start).Seconds())
+}
+
+func (h *Histogram) samples(ls []label) []sample {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	res := make([]sample, 0, len(h.buckets)+3)
+	for i, upper := range h.buckets {
+		res = append(res, sample{
+			suffix: "_bucket",
+			labels: append(append([]label(nil), ls...), label{"le", formatValue(upper)}),
+			value:  float64(h.counts[i]),
+		})
+	}
+	res = append(res,
+		sample{"_bucket", append(append([]label(nil), ls...), label{"le", formatValue(math.Inf(1))}), float64(h.count)},
+		sample{"_sum", ls, h.sum},
+		sample{"_count", ls, float64(h.count)},
+	)
+	return res
+}
+
+type vec struct {
+	desc
+	mu     sync.Mutex
+	series map[string][]string
+}
+
+func (v *vec) key(values []string) string {
+	v.desc.labels(values)
+	key := strings.Join(values, "\xff")
+	v.mu.Lock()
+	if _, ok := v.series[key]; !ok {
+		v.series[key] = append([]string(nil), values...)
+	}
+	v.mu.Unlock()
+	return key
+}
+
+func (v *vec) keys() []string {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	keys := make([]string, 0, len(v.series))
+	for k := range v.series {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (v *vec) values(key string) []string {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.series[key]
+}
+
+type counterCollector struct {
+	desc
+	counter *Counter
+}
+
+func (c counterCollector) kind() string {
+	return "counter"
+}
+
+func (c counterCollector) samples() []sample {
+	return []sample{{value: c.counter.get()}}
+}
+
+func NewCounter(name, help string) *Counter {
+	c := &Counter{}
+	Default.register(counterCollector{desc{name, help, nil}, c})
+	return c
+}
+
+type CounterVec struct {
+	vec
+	counters sync.Map
+}
+
+func NewCounterVec(name, help string, labelNames ...string) *CounterVec {
+	c := &CounterVec{vec: vec{desc: desc{name, help, labelNames}, series: map[string][]string{}}}
+	Default.register(c)
+	return c
+}
+
+func (c *CounterVec) With(labelValues ...string) *Counter {
+	key := c.key(labelValues)
+	counter, _ := c.counters.LoadOrStore(key, &Counter{})
+	return counter.(*Counter)
+}
+
+func (c *CounterVec) kind() string {
+	return "counter"
+}
+
+func (c *CounterVec) samples() []sample {
+	var res []sample
+	for _, key := range c.keys() {
+		counter, ok := c.counters.Load(key)
+		if !ok {
+			continue
+		}
+		res = append(res, sample{
+			labels: c.desc.labels(c.values(key)),
+			value:  counter.(*Counter).get(),
+		})
+	}
+	return res
+}
+
+type gaugeCollector struct {
+	desc
+	gauge *Gauge
+}
+
+func (g gaugeCollector) kind() string {
+	return "gauge"
+}
+
+func (g gaugeCollector) samples() []sample {
+	return []sample{{value: g.gauge.get()}}
+}
+
+func NewGauge(name, help string) *Gauge {
+	g := &Gauge{}
+	Default.register(gaugeCollector{desc{name, help, nil}, g})
+	return g
+}
+
+type gaugeFunc struct {
+	desc
+	fn func() float64
+}
+
+func (g gaugeFunc) kind() string {
+	return "gauge"
+}
+
+func (g gaugeFunc) samples() []sample {
+	return []sample{{value: g.fn()}}
+}
+
+func NewGaugeFunc(name, help string, fn func() float64) {
+	Default.register(gaugeFunc{desc{name, help, nil}, fn})
+}
+
+type histogramCollector struct {
+	desc
+	histogram *Histogram
+}
+
+func (h histogramCollector) kind() string {
+	return "histogram"
+}
+
+func (h histogramCollector) samples() []sample {
+	return h.histogram.samples(nil)
+}
+
+func NewHistogram(name, help string, buckets []float64) *Histogram {
+	h := newHistogram(buckets)
+	Default.register(histogramCollector{desc{name, help, nil}, h})
+	return h
+}
+
+type HistogramVec struct {
+	vec
+	buckets    []float64
+	histograms sync.Map
+}
+
+func NewHistogramVec(name, help string, buckets []float64, labelNames ...string) *HistogramVec {
+	h := &HistogramVec{
+		vec:     vec{desc: desc{name, help, labelNames}, series: map[string][]string{}},
+		buckets: buckets,
+	}
+	Default.register(h)
+	return h
+}
+
+func (h *HistogramVec) With(labelValues ...string) *Histogram {
+	key := h.key(labelValues)
+	histogram, _ := h.histograms.LoadOrStore(key, newHistogram(h.buckets))
+	return histogram.(*Histogram)
+}
+
+func (h *HistogramVec) kind() string {
+	return "histogram"
+}
+
+func (h *HistogramVec) samples() []sample {
+	var res []sample
+	for _, key := range h.keys() {
+		histogram, ok := h.histograms.Load(key)
+		if !ok {
+			continue
+		}
+		res = append(res, histogram.(*Histogram).samples(h.desc.labels(h.values(key)))...)
+	}
+	return res
+}
+
+type GaugeVec struct {
+	vec
+	gauges sync.Map
+}
+
+func NewGaugeVec(name, help string, labelNames ...string) *GaugeVec {
+	g := &GaugeVec{vec: vec{desc: desc{name, help, labelNames}, series: map[string][]string{}}}
+	Default.register(g)
+	return g
+}
+
+func (g *GaugeVec) With(labelValues ...string) *Gauge {
+	key := g.key(labelValues)
+	gauge, _ := g.gauges.LoadOrStore(key, &Gauge{})
+	return gauge.(*Gauge)
+}
+
+func (g *GaugeVec) kind() string {
+	return "gauge"
+}
+
+func (g *GaugeVec) samples() []sample {
+	var res []sample
+	for _, key := range g.keys() {
+		gauge, ok := g.gauges.Load(key)
+		if !ok {
+			continue
+		}
+		res = append(res, sample{
+			labels: g.desc.labels(g.values(key)),
+			value:  gauge.(*Gauge).get(),
+		})
+	}
+	return res
+}
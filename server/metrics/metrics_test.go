@@ -0,0 +1,123 @@
+package metrics
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRegistryWriteTo(t *testing.T) {
+	r := NewRegistry()
+	counter := &Counter{}
+	r.register(counterCollector{desc{"test_counter_total", "A counter\nwith \\ escapes.", nil}, counter})
+	gauge := &Gauge{}
+	r.register(gaugeCollector{desc{"test_gauge", "A gauge.", nil}, gauge})
+	vec := &CounterVec{vec: vec{desc: desc{"test_requests_total", "Requests.", []string{"route", "code"}}, series: map[string][]string{}}}
+	r.register(vec)
+
+	counter.Add(2.5)
+	counter.Inc()
+	gauge.Set(-4)
+	vec.With("/b", "500").Inc()
+	vec.With(`/a"quoted"`, "200").Add(3)
+
+	var buf bytes.Buffer
+	n, err := r.WriteTo(&buf)
+	if err != nil {
+		t.Fatalf("WriteTo() error = %v", err)
+	}
+	if n != int64(buf.Len()) {
+		t.Errorf("WriteTo() = %d bytes, wrote %d", n, buf.Len())
+	}
+	want := `# HELP test_counter_total A counter\nwith \\ escapes.
+# TYPE test_counter_total counter
+test_counter_total 3.5
+# HELP test_gauge A gauge.
+# TYPE test_gauge gauge
+test_gauge -4
+# HELP test_requests_total Requests.
+# TYPE test_requests_total counter
+test_requests_total{route="/a\"quoted\"",code="200"} 3
+test_requests_total{route="/b",code="500"} 1
+`
+	if got := buf.String(); got != want {
+		t.Errorf("WriteTo() output\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestHistogramSamples(t *testing.T) {
+	r := NewRegistry()
+	h := newHistogram([]float64{1, 0.1})
+	r.register(histogramCollector{desc{"test_duration_seconds", "Durations.", nil}, h})
+	hv := &HistogramVec{vec: vec{desc: desc{"test_route_seconds", "Routes.", []string{"route"}}, series: map[string][]string{}}, buckets: []float64{0.5}}
+	r.register(hv)
+
+	h.Observe(0.05)
+	h.Observe(0.5)
+	h.Observe(2)
+	hv.With("/x").Observe(0.25)
+
+	var buf bytes.Buffer
+	if _, err := r.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo() error = %v", err)
+	}
+	want := `# HELP test_duration_seconds Durations.
+# TYPE test_duration_seconds histogram
+test_duration_seconds_bucket{le="0.1"} 1
+test_duration_seconds_bucket{le="1"} 2
+test_duration_seconds_bucket{le="+Inf"} 3
+test_duration_seconds_sum 2.55
+test_duration_seconds_count 3
+# HELP test_route_seconds Routes.
+# TYPE test_route_seconds histogram
+test_route_seconds_bucket{route="/x",le="0.5"} 1
+test_route_seconds_bucket{route="/x",le="+Inf"} 1
+test_route_seconds_sum{route="/x"} 0.25
+test_route_seconds_count{route="/x"} 1
+`
+	if got := buf.String(); got != want {
+		t.Errorf("WriteTo() output\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestRegistryRejectsDuplicates(t *testing.T) {
+	r := NewRegistry()
+	r.register(gaugeCollector{desc{"test_dup", "Dup.", nil}, &Gauge{}})
+	defer func() {
+		if recover() == nil {
+			t.Error("register() of a duplicate name did not panic")
+		}
+	}()
+	r.register(gaugeCollector{desc{"test_dup", "Dup.", nil}, &Gauge{}})
+}
+
+func TestVecRejectsWrongLabelCount(t *testing.T) {
+	v := &CounterVec{vec: vec{desc: desc{"test_labels_total", "Labels.", []string{"a", "b"}}, series: map[string][]string{}}}
+	defer func() {
+		if recover() == nil {
+			t.Error("With() with a missing label value did not panic")
+		}
+	}()
+	v.With("only-one")
+}
+
+func TestHandler(t *testing.T) {
+	r := NewRegistry()
+	c := &Counter{}
+	r.register(counterCollector{desc{"test_handler_total", "Handler.", nil}, c})
+	c.Inc()
+
+	rec := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if got := rec.Header().Get("Content-Type"); got != contentType {
+		t.Errorf("Content-Type = %q, want %q", got, contentType)
+	}
+	if !strings.Contains(rec.Body.String(), "test_handler_total 1\n") {
+		t.Errorf("body does not contain the counter:\n%s", rec.Body.String())
+	}
+}
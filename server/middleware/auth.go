@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"app-pointment/server/models"
+	"app-pointment/server/transport"
+)
+
+const (
+	APIKeyHeader = "X-API-Key"
+	apiKeyCtxKey = ctxKey("api-key")
+)
+
+type Authenticator interface {
+	Authenticate(key string) (models.APIKey, error)
+}
+
+func Authenticate(auth Authenticator, scope string) func(h http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		if auth == nil {
+			return h
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			plain := requestKey(r)
+			if plain == "" {
+				transport.SendError(w, models.UnauthorizedError{})
+				return
+			}
+			key, err := auth.Authenticate(plain)
+			if err != nil {
+				transport.SendError(w, err)
+				return
+			}
+			if !key.HasScope(scope) {
+				transport.SendError(w, models.ForbiddenError{
+					Message: fmt.Sprintf("api key %s lacks the %q scope", key.ID, scope),
+				})
+				return
+			}
+			ctx := context.WithValue(r.Context(), apiKeyCtxKey, key)
+			h.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+func APIKeyFromContext(ctx context.Context) (models.APIKey, bool) {
+	key, ok := ctx.Value(apiKeyCtxKey).(models.APIKey)
+	return key, ok
+}
+
+func requestKey(r *http.Request) string {
+	if key := r.Header.Get(APIKeyHeader); key != "" {
+		return key
+	}
+	auth := r.Header.Get("Authorization")
+	if strings.HasPrefix(auth, "Bearer ") {
+		return strings.TrimSpace(strings.TrimPrefix(auth, "Bearer "))
+	}
+	return ""
+}
@@ -8,7 +8,7 @@ import (
 
 func HTTPLogger(h http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		log.Printf("%s %s\n", strings.ToUpper(r.Method), r.URL.Path)
+		log.Printf("[%s] %s %s\n", RequestIDFromContext(r.Context()), strings.ToUpper(r.Method), r.URL.Path)
 		h.ServeHTTP(w, r)
 	})
 }
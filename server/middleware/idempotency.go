@@ -0,0 +1,113 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+
+	"app-pointment/server/models"
+	"app-pointment/server/transport"
+)
+
+const (
+	IdempotencyKeyHeader = "Idempotency-Key"
+	ReplayedHeader       = "Idempotent-Replayed"
+
+	maxIdempotencyKey = 255
+)
+
+type IdempotencyStore interface {
+	Begin(key, fingerprint string) (models.IdempotentResponse, bool, error)
+	Finish(key string, res models.IdempotentResponse)
+	Abort(key string)
+}
+
+// Idempotency replays the stored response of a request repeated with the same
+// Idempotency-Key header instead of executing it again. Keys are scoped to the
+// authenticated user and server errors are not stored, so they can be retried.
+func Idempotency(store IdempotencyStore) func(h http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		if store == nil {
+			return h
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(IdempotencyKeyHeader)
+			if key == "" {
+				h.ServeHTTP(w, r)
+				return
+			}
+			if len(key) > maxIdempotencyKey {
+				transport.SendError(w, models.FormatValidationError{
+					Message: "idempotency key is too long",
+				})
+				return
+			}
+			body, err := ioutil.ReadAll(r.Body)
+			if err != nil {
+				transport.SendError(w, models.InvalidJSONError{Message: err.Error()})
+				return
+			}
+			r.Body = ioutil.NopCloser(bytes.NewReader(body))
+			if apiKey, ok := APIKeyFromContext(r.Context()); ok {
+				key = apiKey.Principal().User + ":" + key
+			}
+			sum := sha256.Sum256(append([]byte(r.Method+" "+r.URL.Path+"\n"), body...))
+			fingerprint := hex.EncodeToString(sum[:])
+
+			stored, ok, err := store.Begin(key, fingerprint)
+			if err != nil {
+				transport.SendError(w, err)
+				return
+			}
+			if ok {
+				w.Header().Set("Content-Type", stored.ContentType)
+				w.Header().Set(ReplayedHeader, "true")
+				w.WriteHeader(stored.StatusCode)
+				_, _ = w.Write(stored.Body)
+				return
+			}
+
+			rec := &responseRecorder{ResponseWriter: w}
+			finished := false
+			defer func() {
+				if !finished {
+					store.Abort(key)
+				}
+			}()
+			h.ServeHTTP(rec, r)
+			if rec.code == 0 || rec.code >= http.StatusInternalServerError {
+				return
+			}
+			store.Finish(key, models.IdempotentResponse{
+				Fingerprint: fingerprint,
+				StatusCode:  rec.code,
+				ContentType: w.Header().Get("Content-Type"),
+				Body:        rec.body.Bytes(),
+			})
+			finished = true
+		})
+	}
+}
+
+type responseRecorder struct {
+	http.ResponseWriter
+	code int
+	body bytes.Buffer
+}
+
+func (rec *responseRecorder) WriteHeader(code int) {
+	if rec.code == 0 {
+		rec.code = code
+	}
+	rec.ResponseWriter.WriteHeader(code)
+}
+
+func (rec *responseRecorder) Write(bs []byte) (int, error) {
+	if rec.code == 0 {
+		rec.code = http.StatusOK
+	}
+	rec.body.Write(bs)
+	return rec.ResponseWriter.Write(bs)
+}
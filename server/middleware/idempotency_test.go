@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"app-pointment/server/models"
+	"app-pointment/server/services"
+)
+
+type idempotentRequest struct {
+	user string
+	key  string
+	body string
+}
+
+func TestIdempotency(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		first    idempotentRequest
+		second   idempotentRequest
+		code     int
+		replayed bool
+		calls    int
+	}{
+		{"replayed", http.StatusCreated, idempotentRequest{key: "k1", body: "a"}, idempotentRequest{key: "k1", body: "a"}, http.StatusCreated, true, 1},
+		{"without key", http.StatusCreated, idempotentRequest{body: "a"}, idempotentRequest{body: "a"}, http.StatusCreated, false, 2},
+		{"other key", http.StatusCreated, idempotentRequest{key: "k1", body: "a"}, idempotentRequest{key: "k2", body: "a"}, http.StatusCreated, false, 2},
+		{"key reused for another request", http.StatusCreated, idempotentRequest{key: "k1", body: "a"}, idempotentRequest{key: "k1", body: "b"}, http.StatusBadRequest, false, 1},
+		{"client errors are replayed", http.StatusBadRequest, idempotentRequest{key: "k1", body: "a"}, idempotentRequest{key: "k1", body: "a"}, http.StatusBadRequest, true, 1},
+		{"server errors are retried", http.StatusInternalServerError, idempotentRequest{key: "k1", body: "a"}, idempotentRequest{key: "k1", body: "a"}, http.StatusInternalServerError, false, 2},
+		{"keys are scoped to the user", http.StatusCreated, idempotentRequest{user: "alice", key: "k1", body: "a"}, idempotentRequest{user: "bob", key: "k1", body: "a"}, http.StatusCreated, false, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			h := Idempotency(services.NewIdempotency(time.Hour))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.status)
+				fmt.Fprintf(w, `{"call":%d}`, calls)
+			}))
+			send := func(req idempotentRequest) *httptest.ResponseRecorder {
+				r := httptest.NewRequest(http.MethodPost, "/reminders", strings.NewReader(req.body))
+				if req.key != "" {
+					r.Header.Set(IdempotencyKeyHeader, req.key)
+				}
+				if req.user != "" {
+					r = r.WithContext(context.WithValue(r.Context(), apiKeyCtxKey, models.APIKey{Name: req.user}))
+				}
+				rec := httptest.NewRecorder()
+				h.ServeHTTP(rec, r)
+				return rec
+			}
+			first := send(tt.first)
+			second := send(tt.second)
+			if second.Code != tt.code {
+				t.Errorf("got status %d, want %d", second.Code, tt.code)
+			}
+			if replayed := second.Header().Get(ReplayedHeader) == "true"; replayed != tt.replayed {
+				t.Errorf("replayed = %v, want %v", replayed, tt.replayed)
+			}
+			if tt.replayed && second.Body.String() != first.Body.String() {
+				t.Errorf("replayed %s, want %s", second.Body, first.Body)
+			}
+			if calls != tt.calls {
+				t.Errorf("handler called %d time(s), want %d", calls, tt.calls)
+			}
+		})
+	}
+}
@@ -14,6 +14,14 @@ type Middleware struct {
 	functions []func(h http.Handler) http.Handler
 }
 
+func (m *Middleware) With(ms ...func(h http.Handler) http.Handler) *Middleware {
+	functions := make([]func(h http.Handler) http.Handler, 0, len(m.functions)+len(ms))
+	functions = append(functions, m.functions...)
+	return &Middleware{
+		functions: append(functions, ms...),
+	}
+}
+
 func (m *Middleware) Then(h http.Handler) http.Handler {
 	if h == nil {
 		h = http.DefaultServeMux
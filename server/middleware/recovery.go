@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"runtime/debug"
+
+	"app-pointment/server/transport"
+)
+
+func Recovery(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			p := recover()
+			if p == nil {
+				return
+			}
+			if p == http.ErrAbortHandler {
+				panic(p)
+			}
+			log.Printf(
+				"[%s] panic while serving %s %s: %v\n%s",
+				RequestIDFromContext(r.Context()),
+				r.Method,
+				r.URL.Path,
+				p,
+				debug.Stack(),
+			)
+			transport.SendError(w, fmt.Errorf("panic: %v", p))
+		}()
+		h.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,124 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"app-pointment/server/models"
+	"app-pointment/server/transport"
+)
+
+func Timeout(d time.Duration) func(h http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		if d <= 0 {
+			return h
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithTimeout(r.Context(), d)
+			defer cancel()
+
+			tw := &timeoutWriter{header: http.Header{}}
+			done := make(chan struct{})
+			panicked := make(chan interface{}, 1)
+			go func() {
+				defer func() {
+					if p := recover(); p != nil {
+						panicked <- p
+					}
+				}()
+				h.ServeHTTP(tw, r.WithContext(ctx))
+				close(done)
+			}()
+
+			select {
+			case p := <-panicked:
+				panic(p)
+			case <-done:
+				tw.flushTo(w)
+			case <-ctx.Done():
+				if ctx.Err() == context.DeadlineExceeded && !safeMethod(r.Method) {
+					// A change may already be committed, the handler answers
+					// itself once the services have given up on the request.
+					log.Printf("%s %s did not complete within %v, waiting for the change to settle", r.Method, r.URL.Path, d)
+					select {
+					case p := <-panicked:
+						panic(p)
+					case <-done:
+						tw.flushTo(w)
+					}
+					return
+				}
+				tw.expire()
+				if ctx.Err() != context.DeadlineExceeded {
+					return
+				}
+				transport.SendError(w, models.TimeoutError{
+					Message: fmt.Sprintf("request did not complete within %v", d),
+				})
+			}
+		})
+	}
+}
+
+// safeMethod reports whether the request leaves the state untouched, so it can
+// be answered before the handler returns.
+func safeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+type timeoutWriter struct {
+	mu      sync.Mutex
+	header  http.Header
+	body    bytes.Buffer
+	code    int
+	expired bool
+}
+
+func (tw *timeoutWriter) Header() http.Header {
+	return tw.header
+}
+
+func (tw *timeoutWriter) Write(bs []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.expired {
+		return 0, http.ErrHandlerTimeout
+	}
+	if tw.code == 0 {
+		tw.code = http.StatusOK
+	}
+	return tw.body.Write(bs)
+}
+
+func (tw *timeoutWriter) WriteHeader(code int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.expired || tw.code != 0 {
+		return
+	}
+	tw.code = code
+}
+
+func (tw *timeoutWriter) expire() {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	tw.expired = true
+}
+
+func (tw *timeoutWriter) flushTo(w http.ResponseWriter) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	for k, v := range tw.header {
+		w.Header()[k] = v
+	}
+	if tw.code == 0 {
+		tw.code = http.StatusOK
+	}
+	w.WriteHeader(tw.code)
+	_, _ = w.Write(tw.body.Bytes())
+}
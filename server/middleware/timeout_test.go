@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTimeout(t *testing.T) {
+	tests := []struct {
+		name    string
+		method  string
+		delay   time.Duration
+		code    int
+		applied bool
+	}{
+		{"fast read", http.MethodGet, 0, http.StatusOK, true},
+		{"slow read", http.MethodGet, 50 * time.Millisecond, http.StatusServiceUnavailable, false},
+		{"fast write", http.MethodPost, 0, http.StatusCreated, true},
+		{"slow write", http.MethodPost, 50 * time.Millisecond, http.StatusCreated, true},
+		{"slow delete", http.MethodDelete, 50 * time.Millisecond, http.StatusCreated, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var applied int32
+			delay := tt.delay
+			h := Timeout(10 * time.Millisecond)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				time.Sleep(delay)
+				atomic.StoreInt32(&applied, 1)
+				if r.Method == http.MethodGet {
+					w.WriteHeader(http.StatusOK)
+					return
+				}
+				w.WriteHeader(http.StatusCreated)
+			}))
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(tt.method, "/reminders", nil))
+			if rec.Code != tt.code {
+				t.Errorf("got status %d, want %d", rec.Code, tt.code)
+			}
+			// Once answered, writes must not change anything the client was
+			// told about, reads may still finish in the background.
+			if got := atomic.LoadInt32(&applied) == 1; tt.applied && !got {
+				t.Errorf("handler had not finished when the response was sent")
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package models
+
+const (
+	BatchCreate = "create"
+	BatchEdit   = "edit"
+	BatchDelete = "delete"
+
+	// BatchAtomic applies every operation or none of them, BatchBestEffort
+	// applies the valid ones and reports the others.
+	BatchAtomic     = "atomic"
+	BatchBestEffort = "best_effort"
+
+	BatchStatusOK      = "ok"
+	BatchStatusFailed  = "failed"
+	BatchStatusSkipped = "skipped"
+
+	MaxBatchOperations = 500
+)
+
+var BatchOps = []string{BatchCreate, BatchEdit, BatchDelete}
+
+var BatchModes = []string{BatchAtomic, BatchBestEffort}
+
+type BatchResult struct {
+	Index    int        `json:"index"`
+	Op       string     `json:"op"`
+	ID       int        `json:"id,omitempty"`
+	Status   string     `json:"status"`
+	Reminder *Reminder  `json:"reminder,omitempty"`
+	Error    *HTTPError `json:"error,omitempty"`
+
+	Err error `json:"-"`
+}
+
+type BatchResponse struct {
+	Mode      string        `json:"mode"`
+	Succeeded int           `json:"succeeded"`
+	Failed    int           `json:"failed"`
+	Results   []BatchResult `json:"results"`
+}
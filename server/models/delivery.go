@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+const (
+	DeliveryDelivered = "delivered"
+	DeliveryAwaiting  = "awaiting_ack"
+	DeliverySnoozed   = "snoozed"
+	DeliveryFailed    = "failed"
+	DeliveryDeferred  = "deferred"
+	// DeliveryUnanswered means the notification was shown but the user did
+	// not answer it before the notifier gave up.
+	DeliveryUnanswered = "unanswered"
+	// DeliveryCircuitOpen means the channel was not tried because its circuit
+	// breaker is open, Snooze holds the time left until it probes again.
+	DeliveryCircuitOpen = "circuit_open"
+)
+
+type ChannelDelivery struct {
+	Status     string        `json:"status"`
+	DeliveryID string        `json:"delivery_id,omitempty"`
+	Error      string        `json:"error,omitempty"`
+	Snooze     time.Duration `json:"snooze,omitempty"`
+	At         time.Time     `json:"at"`
+}
+
+// DeliveryAttempt records a single notification of a reminder through a
+// channel, the answer to an awaiting notification or a quiet hours deferral.
+type DeliveryAttempt struct {
+	ReminderID      int           `json:"reminder_id"`
+	Channel         string        `json:"channel"`
+	Attempt         int           `json:"attempt"`
+	Outcome         string        `json:"outcome"`
+	ActivationType  string        `json:"activation_type,omitempty"`
+	ActivationValue string        `json:"activation_value,omitempty"`
+	DeliveryID      string        `json:"delivery_id,omitempty"`
+	Error           string        `json:"error,omitempty"`
+	Latency         time.Duration `json:"latency"`
+	DeferredUntil   *time.Time    `json:"deferred_until,omitempty"`
+	At              time.Time     `json:"at"`
+}
@@ -2,6 +2,7 @@ package models
 
 import (
 	"fmt"
+	"time"
 )
 
 type HTTPError struct {
@@ -49,6 +50,62 @@ func (e NotFoundError) Error() string {
 	return e.Message
 }
 
+type TimeoutError struct {
+	Message string
+}
+
+func (e TimeoutError) Error() string {
+	if e.Message == "" {
+		return "request timed out"
+	}
+	return e.Message
+}
+
+type UnauthorizedError struct {
+	Message string
+}
+
+func (e UnauthorizedError) Error() string {
+	if e.Message == "" {
+		return "authentication required"
+	}
+	return e.Message
+}
+
+type ForbiddenError struct {
+	Message string
+}
+
+func (e ForbiddenError) Error() string {
+	if e.Message == "" {
+		return "permission denied"
+	}
+	return e.Message
+}
+
+type ConflictError struct {
+	Message string
+}
+
+func (e ConflictError) Error() string {
+	if e.Message == "" {
+		return "conflicting request"
+	}
+	return e.Message
+}
+
+type CircuitOpenError struct {
+	Message    string
+	RetryAfter time.Duration
+}
+
+func (e CircuitOpenError) Error() string {
+	if e.Message == "" {
+		return "circuit breaker is open"
+	}
+	return e.Message
+}
+
 func WrapError(customErr string, originalErr error) error {
 	err := fmt.Errorf("%s: %v", customErr, originalErr)
 	return err
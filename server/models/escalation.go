@@ -0,0 +1,85 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EscalationStep adds channels to a reminder once it stayed unacknowledged
+// for Attempts notifications at the previous step or for After since it was
+// first notified.
+type EscalationStep struct {
+	Attempts int           `json:"attempts,omitempty"`
+	After    time.Duration `json:"after,omitempty"`
+	Channels []string      `json:"channels"`
+}
+
+type EscalationPolicy struct {
+	Name  string           `json:"name"`
+	Steps []EscalationStep `json:"steps"`
+}
+
+// ResetEscalation restarts the escalation of a reminder from its first step.
+func (r *Reminder) ResetEscalation() {
+	r.EscalationLevel = 0
+	r.Unacknowledged = 0
+	r.FirstNotifiedAt = nil
+}
+
+func (s EscalationStep) Due(unacknowledged int, since time.Duration) bool {
+	if s.Attempts > 0 && unacknowledged >= s.Attempts {
+		return true
+	}
+	return s.After > 0 && since >= s.After
+}
+
+func (s EscalationStep) String() string {
+	if s.Attempts > 0 {
+		return fmt.Sprintf("%d unacknowledged attempt(s)", s.Attempts)
+	}
+	return fmt.Sprintf("unacknowledged for %v", s.After)
+}
+
+// ParseEscalationPolicy parses a policy like "oncall=3:email;10m:pager,sms",
+// each step triggers after a number of unacknowledged attempts or a duration
+// since the first notification and lists the channels it adds.
+func ParseEscalationPolicy(spec string) (EscalationPolicy, error) {
+	parts := strings.SplitN(spec, "=", 2)
+	if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+		return EscalationPolicy{}, fmt.Errorf("invalid escalation policy %q, expected name=trigger:channels;...", spec)
+	}
+	policy := EscalationPolicy{Name: strings.TrimSpace(parts[0])}
+	for _, item := range strings.Split(parts[1], ";") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		kv := strings.SplitN(item, ":", 2)
+		if len(kv) != 2 || kv[1] == "" {
+			return EscalationPolicy{}, fmt.Errorf("invalid escalation step %q, expected trigger:channels", item)
+		}
+		var step EscalationStep
+		if n, err := strconv.Atoi(kv[0]); err == nil {
+			step.Attempts = n
+		} else if d, err := time.ParseDuration(kv[0]); err == nil {
+			step.After = d
+		} else {
+			return EscalationPolicy{}, fmt.Errorf("invalid escalation trigger %q, expected attempts or a duration", kv[0])
+		}
+		if step.Attempts <= 0 && step.After <= 0 {
+			return EscalationPolicy{}, fmt.Errorf("escalation trigger %q must be > 0", kv[0])
+		}
+		for _, ch := range strings.Split(kv[1], ",") {
+			if ch = strings.TrimSpace(ch); ch != "" {
+				step.Channels = append(step.Channels, ch)
+			}
+		}
+		policy.Steps = append(policy.Steps, step)
+	}
+	if len(policy.Steps) == 0 {
+		return EscalationPolicy{}, fmt.Errorf("escalation policy %s has no steps", policy.Name)
+	}
+	return policy, nil
+}
@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+const (
+	EventReminderCreated   = "reminder.created"
+	EventReminderUpdated   = "reminder.updated"
+	EventReminderDeleted   = "reminder.deleted"
+	EventReminderFired     = "reminder.fired"
+	EventReminderSnoozed   = "reminder.snoozed"
+	EventReminderCompleted = "reminder.completed"
+	EventReminderDismissed = "reminder.dismissed"
+	EventReminderReopened  = "reminder.reopened"
+	EventReminderFailed    = "reminder.failed"
+	EventReminderDeferred  = "reminder.deferred"
+	EventReminderEscalated = "reminder.escalated"
+)
+
+var EventTypes = []string{
+	EventReminderCreated,
+	EventReminderUpdated,
+	EventReminderDeleted,
+	EventReminderFired,
+	EventReminderSnoozed,
+	EventReminderCompleted,
+	EventReminderDismissed,
+	EventReminderReopened,
+	EventReminderFailed,
+	EventReminderDeferred,
+	EventReminderEscalated,
+}
+
+func ValidEventType(t string) bool {
+	for _, e := range EventTypes {
+		if e == t {
+			return true
+		}
+	}
+	return false
+}
+
+type Event struct {
+	ID       int64     `json:"id"`
+	Type     string    `json:"type"`
+	Reminder Reminder  `json:"reminder"`
+	At       time.Time `json:"at"`
+}
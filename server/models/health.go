@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+const (
+	HealthUp   = "up"
+	HealthDown = "down"
+)
+
+type ComponentHealth struct {
+	Name    string                 `json:"name"`
+	Status  string                 `json:"status"`
+	Message string                 `json:"message,omitempty"`
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+type Health struct {
+	Status     string            `json:"status"`
+	CheckedAt  time.Time         `json:"checked_at"`
+	Components []ComponentHealth `json:"components,omitempty"`
+}
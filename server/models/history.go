@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+const (
+	HistoryEscalation = "escalation"
+
+	maxHistory = 100
+)
+
+type HistoryEntry struct {
+	At       time.Time `json:"at"`
+	Type     string    `json:"type"`
+	Level    int       `json:"level,omitempty"`
+	Channels []string  `json:"channels,omitempty"`
+	Reason   string    `json:"reason,omitempty"`
+}
+
+// Record appends the entry to the reminder history, keeping only the most
+// recent ones.
+func (r *Reminder) Record(e HistoryEntry) {
+	history := make([]HistoryEntry, 0, len(r.History)+1)
+	history = append(history, r.History...)
+	history = append(history, e)
+	if len(history) > maxHistory {
+		history = history[len(history)-maxHistory:]
+	}
+	r.History = history
+}
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// IdempotentResponse is the response replayed for a repeated request with the
+// same idempotency key.
+type IdempotentResponse struct {
+	Fingerprint string
+	StatusCode  int
+	ContentType string
+	Body        []byte
+	ExpiresAt   time.Time
+}
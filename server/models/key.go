@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+const (
+	ScopeRemindersRead  = "reminders:read"
+	ScopeRemindersWrite = "reminders:write"
+	ScopeAdmin          = "admin"
+)
+
+var Scopes = []string{ScopeRemindersRead, ScopeRemindersWrite, ScopeAdmin}
+
+type APIKey struct {
+	ID        string     `json:"id"`
+	Name      string     `json:"name"`
+	User      string     `json:"user"`
+	Hash      string     `json:"hash"`
+	Scopes    []string   `json:"scopes"`
+	CreatedAt time.Time  `json:"created_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+func (k APIKey) Revoked() bool {
+	return k.RevokedAt != nil
+}
+
+func (k APIKey) HasScope(scope string) bool {
+	for _, s := range k.Scopes {
+		if s == scope || s == ScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+func (k APIKey) Principal() Principal {
+	user := k.User
+	if user == "" {
+		user = k.Name
+	}
+	return Principal{User: user, Admin: k.HasScope(ScopeAdmin)}
+}
+
+func ValidScope(scope string) bool {
+	for _, s := range Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,222 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	QuietModeDefer  = "defer"
+	QuietModeSilent = "silent"
+)
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+type QuietWindow struct {
+	Days  []string `json:"days,omitempty"`
+	Start string   `json:"start"`
+	End   string   `json:"end"`
+}
+
+type QuietHours struct {
+	TimeZone string        `json:"timezone,omitempty"`
+	Mode     string        `json:"mode,omitempty"`
+	Channel  string        `json:"channel,omitempty"`
+	Windows  []QuietWindow `json:"windows"`
+}
+
+func (q QuietHours) Validate() error {
+	if _, err := q.location(); err != nil {
+		return DataValidationError{Message: fmt.Sprintf("invalid quiet hours time zone %q", q.TimeZone)}
+	}
+	switch q.Mode {
+	case "", QuietModeDefer:
+	case QuietModeSilent:
+		if q.Channel == "" {
+			return DataValidationError{Message: "silent quiet hours require a channel"}
+		}
+	default:
+		return DataValidationError{
+			Message: fmt.Sprintf("invalid quiet hours mode %q, expected %s or %s", q.Mode, QuietModeDefer, QuietModeSilent),
+		}
+	}
+	for _, w := range q.Windows {
+		if _, _, err := w.bounds(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Until reports whether t falls into a quiet window and, if so, when the
+// window ends.
+func (q QuietHours) Until(t time.Time) (time.Time, bool) {
+	loc, err := q.location()
+	if err != nil {
+		return time.Time{}, false
+	}
+	t = t.In(loc)
+	var until time.Time
+	for _, w := range q.Windows {
+		start, end, err := w.bounds()
+		if err != nil {
+			continue
+		}
+		// Windows ending before they start run over midnight, so the one
+		// that started yesterday may still be active.
+		for _, offset := range []int{-1, 0} {
+			day := time.Date(t.Year(), t.Month(), t.Day()+offset, 0, 0, 0, 0, loc)
+			if !w.on(day.Weekday()) {
+				continue
+			}
+			from := day.Add(start)
+			to := day.Add(end)
+			if end <= start {
+				to = to.Add(24 * time.Hour)
+			}
+			if !t.Before(from) && t.Before(to) && to.After(until) {
+				until = to
+			}
+		}
+	}
+	return until, !until.IsZero()
+}
+
+func (q QuietHours) location() (*time.Location, error) {
+	if q.TimeZone == "" {
+		return time.Local, nil
+	}
+	return time.LoadLocation(q.TimeZone)
+}
+
+func (w QuietWindow) on(day time.Weekday) bool {
+	if len(w.Days) == 0 {
+		return true
+	}
+	for _, d := range w.Days {
+		if weekdays[d] == day {
+			return true
+		}
+	}
+	return false
+}
+
+func (w QuietWindow) bounds() (time.Duration, time.Duration, error) {
+	start, err := parseClock(w.Start)
+	if err != nil {
+		return 0, 0, err
+	}
+	end, err := parseClock(w.End)
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, d := range w.Days {
+		if _, ok := weekdays[d]; !ok {
+			return 0, 0, DataValidationError{Message: fmt.Sprintf("invalid quiet hours day %q", d)}
+		}
+	}
+	return start, end, nil
+}
+
+func parseClock(v string) (time.Duration, error) {
+	t, err := time.Parse("15:04", v)
+	if err != nil {
+		return 0, DataValidationError{Message: fmt.Sprintf("invalid quiet hours time %q, expected HH:MM", v)}
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// ParseQuietWindows parses windows like "mon-fri 22:00-07:00; sat,sun 23:00-09:00",
+// a window without days applies to every day.
+func ParseQuietWindows(spec string) ([]QuietWindow, error) {
+	var windows []QuietWindow
+	for _, part := range strings.Split(spec, ";") {
+		fields := strings.Fields(part)
+		if len(fields) == 0 {
+			continue
+		}
+		var w QuietWindow
+		if len(fields) == 2 {
+			days, err := parseDays(fields[0])
+			if err != nil {
+				return nil, err
+			}
+			w.Days = days
+			fields = fields[1:]
+		}
+		if len(fields) != 1 {
+			return nil, DataValidationError{Message: fmt.Sprintf("invalid quiet window %q, expected [days] HH:MM-HH:MM", strings.TrimSpace(part))}
+		}
+		bounds := strings.SplitN(fields[0], "-", 2)
+		if len(bounds) != 2 {
+			return nil, DataValidationError{Message: fmt.Sprintf("invalid quiet window %q, expected [days] HH:MM-HH:MM", strings.TrimSpace(part))}
+		}
+		w.Start, w.End = bounds[0], bounds[1]
+		if _, _, err := w.bounds(); err != nil {
+			return nil, err
+		}
+		windows = append(windows, w)
+	}
+	return windows, nil
+}
+
+func parseDays(spec string) ([]string, error) {
+	order := []string{"mon", "tue", "wed", "thu", "fri", "sat", "sun"}
+	index := func(d string) (int, error) {
+		for i, o := range order {
+			if o == d {
+				return i, nil
+			}
+		}
+		return 0, DataValidationError{Message: fmt.Sprintf("invalid quiet hours day %q", d)}
+	}
+	var days []string
+	for _, item := range strings.Split(strings.ToLower(spec), ",") {
+		bounds := strings.SplitN(item, "-", 2)
+		from, err := index(bounds[0])
+		if err != nil {
+			return nil, err
+		}
+		to := from
+		if len(bounds) == 2 {
+			if to, err = index(bounds[1]); err != nil {
+				return nil, err
+			}
+		}
+		for i := from; ; i = (i + 1) % len(order) {
+			days = append(days, order[i])
+			if i == to {
+				break
+			}
+		}
+	}
+	return days, nil
+}
+
+func (q QuietHours) String() string {
+	var parts []string
+	for _, w := range q.Windows {
+		s := w.Start + "-" + w.End
+		if len(w.Days) > 0 {
+			s = strings.Join(w.Days, ",") + " " + s
+		}
+		parts = append(parts, s)
+	}
+	res := strings.Join(parts, "; ")
+	if q.TimeZone != "" {
+		res += " (" + q.TimeZone + ")"
+	}
+	if q.Mode == QuietModeSilent {
+		res += " silent:" + q.Channel
+	}
+	return res
+}
@@ -0,0 +1,93 @@
+package models
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestQuietHoursUntil(t *testing.T) {
+	at := func(day, hour, min int) time.Time {
+		return time.Date(2026, 10, day, hour, min, 0, 0, time.UTC)
+	}
+	hours := QuietHours{TimeZone: "UTC", Windows: []QuietWindow{
+		{Days: []string{"mon", "tue", "wed", "thu", "fri"}, Start: "22:00", End: "07:00"},
+		{Days: []string{"sat", "sun"}, Start: "23:00", End: "09:00"},
+		{Start: "12:00", End: "13:00"},
+	}}
+	tests := []struct {
+		name  string
+		t     time.Time
+		until time.Time
+		quiet bool
+	}{
+		{"monday night", at(19, 23, 0), at(20, 7, 0), true},
+		{"window started the day before", at(20, 6, 59), at(20, 7, 0), true},
+		{"window end", at(20, 7, 0), time.Time{}, false},
+		{"working hours", at(19, 10, 0), time.Time{}, false},
+		{"friday night into saturday", at(24, 6, 0), at(24, 7, 0), true},
+		{"weekend window", at(25, 8, 0), at(25, 9, 0), true},
+		{"daily window", at(21, 12, 30), at(21, 13, 0), true},
+		{"other time zone", time.Date(2026, 10, 19, 18, 0, 0, 0, time.FixedZone("UTC-5", -5*3600)), at(20, 7, 0), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			until, quiet := hours.Until(tt.t)
+			if quiet != tt.quiet || !until.Equal(tt.until) {
+				t.Errorf("Until(%v) = %v, %v, want %v, %v", tt.t, until, quiet, tt.until, tt.quiet)
+			}
+		})
+	}
+}
+
+func TestParseQuietWindows(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    string
+		wantErr bool
+	}{
+		{"mon-fri 22:00-07:00; sat,sun 23:00-09:00", "mon,tue,wed,thu,fri 22:00-07:00; sat,sun 23:00-09:00", false},
+		{"fri-mon 01:00-02:00", "fri,sat,sun,mon 01:00-02:00", false},
+		{"12:00-13:00;", "12:00-13:00", false},
+		{"mon-fri", "", true},
+		{"someday 22:00-07:00", "", true},
+		{"22:00", "", true},
+		{"25:00-07:00", "", true},
+		{"mon 22:00 07:00", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			windows, err := ParseQuietWindows(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+			if got := (QuietHours{Windows: windows}).String(); !tt.wantErr && got != tt.want {
+				t.Errorf("parsed %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuietHoursValidate(t *testing.T) {
+	window := []QuietWindow{{Start: "22:00", End: "07:00"}}
+	tests := []struct {
+		name  string
+		hours QuietHours
+		err   string
+	}{
+		{"defer", QuietHours{Windows: window}, ""},
+		{"silent", QuietHours{Mode: QuietModeSilent, Channel: "email", Windows: window}, ""},
+		{"silent without channel", QuietHours{Mode: QuietModeSilent, Windows: window}, "require a channel"},
+		{"unknown mode", QuietHours{Mode: "mute", Windows: window}, "invalid quiet hours mode"},
+		{"unknown time zone", QuietHours{TimeZone: "Mars/Olympus", Windows: window}, "time zone"},
+		{"invalid window", QuietHours{Windows: []QuietWindow{{Start: "late", End: "07:00"}}}, "invalid quiet hours time"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.hours.Validate()
+			if tt.err == "" && err != nil || tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)) {
+				t.Errorf("got error %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
@@ -2,11 +2,56 @@ package models
 
 import "time"
 
+const (
+	StatusPending   = "pending"
+	StatusCompleted = "completed"
+	StatusFailed    = "failed"
+	StatusDismissed = "dismissed"
+	StatusDeferred  = "deferred"
+)
+
+var Statuses = []string{StatusPending, StatusDeferred, StatusCompleted, StatusDismissed, StatusFailed}
+
+// Terminal reports whether the reminder is no longer notified until it is
+// reopened or requeued.
+func (r Reminder) Terminal() bool {
+	switch r.Status {
+	case StatusCompleted, StatusDismissed, StatusFailed:
+		return true
+	}
+	return false
+}
+
+func ValidStatus(status string) bool {
+	for _, s := range Statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
 type Reminder struct {
-	ID         int           `json:"id"`
-	Title      string        `json:"title"`
-	Message    string        `json:"message"`
-	Duration   time.Duration `json:"duration"`
-	CreatedAt  time.Time     `json:"created_at"`
-	ModifiedAt time.Time     `json:"modified_at"`
+	ID               int                        `json:"id"`
+	Owner            string                     `json:"owner,omitempty"`
+	Title            string                     `json:"title"`
+	Message          string                     `json:"message"`
+	Template         string                     `json:"template,omitempty"`
+	Variables        map[string]string          `json:"variables,omitempty"`
+	Duration         time.Duration              `json:"duration"`
+	Status           string                     `json:"status,omitempty"`
+	DeferredUntil    *time.Time                 `json:"deferred_until,omitempty"`
+	IgnoreQuietHours bool                       `json:"ignore_quiet_hours,omitempty"`
+	Attempts         int                        `json:"attempts,omitempty"`
+	LastError        string                     `json:"last_error,omitempty"`
+	RetryPolicy      *RetryPolicy               `json:"retry_policy,omitempty"`
+	Channels         []string                   `json:"channels,omitempty"`
+	Deliveries       map[string]ChannelDelivery `json:"deliveries,omitempty"`
+	EscalationPolicy string                     `json:"escalation_policy,omitempty"`
+	EscalationLevel  int                        `json:"escalation_level,omitempty"`
+	Unacknowledged   int                        `json:"unacknowledged,omitempty"`
+	FirstNotifiedAt  *time.Time                 `json:"first_notified_at,omitempty"`
+	History          []HistoryEntry             `json:"history,omitempty"`
+	CreatedAt        time.Time                  `json:"created_at"`
+	ModifiedAt       time.Time                  `json:"modified_at"`
 }
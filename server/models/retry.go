@@ -0,0 +1,111 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type RetryPolicy struct {
+	MaxAttempts  int           `json:"max_attempts,omitempty"`
+	InitialDelay time.Duration `json:"initial_delay,omitempty"`
+	MaxDelay     time.Duration `json:"max_delay,omitempty"`
+	Multiplier   float64       `json:"multiplier,omitempty"`
+	Jitter       float64       `json:"jitter,omitempty"`
+}
+
+func (p RetryPolicy) Validate() error {
+	switch {
+	case p.MaxAttempts < 0:
+		return DataValidationError{Message: "retry max_attempts cannot be negative"}
+	case p.InitialDelay < 0 || p.MaxDelay < 0:
+		return DataValidationError{Message: "retry delays cannot be negative"}
+	case p.Multiplier != 0 && p.Multiplier < 1:
+		return DataValidationError{Message: "retry multiplier must be >= 1"}
+	case p.Jitter < 0 || p.Jitter > 1:
+		return DataValidationError{Message: "retry jitter must be between 0 and 1"}
+	}
+	return nil
+}
+
+func (p RetryPolicy) Merge(fallback RetryPolicy) RetryPolicy {
+	if p.MaxAttempts == 0 {
+		p.MaxAttempts = fallback.MaxAttempts
+	}
+	if p.InitialDelay == 0 {
+		p.InitialDelay = fallback.InitialDelay
+	}
+	if p.MaxDelay == 0 {
+		p.MaxDelay = fallback.MaxDelay
+	}
+	if p.Multiplier == 0 {
+		p.Multiplier = fallback.Multiplier
+	}
+	if p.Jitter == 0 {
+		p.Jitter = fallback.Jitter
+	}
+	return p
+}
+
+func (p RetryPolicy) Exhausted(attempts int) bool {
+	return p.MaxAttempts > 0 && attempts >= p.MaxAttempts
+}
+
+// Delay returns the backoff before the given attempt (1-based); random must
+// return a value in [0, 1) and spreads the delay by ±Jitter.
+func (p RetryPolicy) Delay(attempt int, random func() float64) time.Duration {
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+	if attempt < 1 {
+		attempt = 1
+	}
+	d := float64(p.InitialDelay) * math.Pow(multiplier, float64(attempt-1))
+	if p.MaxDelay > 0 && d > float64(p.MaxDelay) {
+		d = float64(p.MaxDelay)
+	}
+	if p.Jitter > 0 && random != nil {
+		d += d * p.Jitter * (2*random() - 1)
+	}
+	if p.MaxDelay > 0 && d > float64(p.MaxDelay) {
+		d = float64(p.MaxDelay)
+	}
+	return time.Duration(d)
+}
+
+// ParseRetryPolicy reads policies such as "attempts=5,initial=10s,max=10m,multiplier=2,jitter=0.2".
+func ParseRetryPolicy(s string) (RetryPolicy, error) {
+	var p RetryPolicy
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return RetryPolicy{}, fmt.Errorf("invalid retry policy field %q", part)
+		}
+		var err error
+		switch kv[0] {
+		case "attempts":
+			p.MaxAttempts, err = strconv.Atoi(kv[1])
+		case "initial":
+			p.InitialDelay, err = time.ParseDuration(kv[1])
+		case "max":
+			p.MaxDelay, err = time.ParseDuration(kv[1])
+		case "multiplier":
+			p.Multiplier, err = strconv.ParseFloat(kv[1], 64)
+		case "jitter":
+			p.Jitter, err = strconv.ParseFloat(kv[1], 64)
+		default:
+			return RetryPolicy{}, fmt.Errorf("unknown retry policy field %q", kv[0])
+		}
+		if err != nil {
+			return RetryPolicy{}, WrapError(fmt.Sprintf("invalid retry policy field %q", part), err)
+		}
+	}
+	return p, p.Validate()
+}
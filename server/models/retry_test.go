@@ -0,0 +1,81 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := RetryPolicy{InitialDelay: 10 * time.Second, MaxDelay: time.Minute, Multiplier: 2}
+	tests := []struct {
+		name    string
+		policy  RetryPolicy
+		attempt int
+		random  func() float64
+		want    time.Duration
+	}{
+		{"first attempt", policy, 1, nil, 10 * time.Second},
+		{"attempt before the first", policy, 0, nil, 10 * time.Second},
+		{"backs off", policy, 3, nil, 40 * time.Second},
+		{"capped", policy, 5, nil, time.Minute},
+		{"no multiplier", RetryPolicy{InitialDelay: 10 * time.Second}, 4, nil, 10 * time.Second},
+		{"jitter down", RetryPolicy{InitialDelay: 10 * time.Second, Jitter: 0.5}, 1, func() float64 { return 0 }, 5 * time.Second},
+		{"jitter up", RetryPolicy{InitialDelay: 10 * time.Second, Jitter: 0.5}, 1, func() float64 { return 0.75 }, 12500 * time.Millisecond},
+		{"jitter capped", RetryPolicy{InitialDelay: time.Minute, MaxDelay: time.Minute, Jitter: 0.5}, 1, func() float64 { return 0.99 }, time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.Delay(tt.attempt, tt.random); got != tt.want {
+				t.Errorf("Delay(%d) = %v, want %v", tt.attempt, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyExhausted(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   RetryPolicy
+		attempts int
+		want     bool
+	}{
+		{"unlimited", RetryPolicy{}, 100, false},
+		{"below the limit", RetryPolicy{MaxAttempts: 3}, 2, false},
+		{"at the limit", RetryPolicy{MaxAttempts: 3}, 3, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.Exhausted(tt.attempts); got != tt.want {
+				t.Errorf("Exhausted(%d) = %v, want %v", tt.attempts, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRetryPolicy(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    RetryPolicy
+		wantErr bool
+	}{
+		{"", RetryPolicy{}, false},
+		{"attempts=5, initial=10s,max=10m,multiplier=2,jitter=0.2", RetryPolicy{MaxAttempts: 5, InitialDelay: 10 * time.Second, MaxDelay: 10 * time.Minute, Multiplier: 2, Jitter: 0.2}, false},
+		{"attempts", RetryPolicy{}, true},
+		{"tries=5", RetryPolicy{}, true},
+		{"initial=soon", RetryPolicy{}, true},
+		{"attempts=-1", RetryPolicy{}, true},
+		{"multiplier=0.5", RetryPolicy{}, true},
+		{"jitter=2", RetryPolicy{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := ParseRetryPolicy(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
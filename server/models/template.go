@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+type Template struct {
+	Name       string    `json:"name"`
+	Owner      string    `json:"owner,omitempty"`
+	Admin      bool      `json:"admin,omitempty"`
+	Title      string    `json:"title"`
+	Message    string    `json:"message"`
+	Variables  []string  `json:"variables,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	ModifiedAt time.Time `json:"modified_at"`
+}
+
+// Shared reports whether every user can use the template, templates created
+// by admins or before templates had owners are shared.
+func (t Template) Shared() bool {
+	return t.Admin || t.Owner == ""
+}
+
+// UsableBy reports whether reminders of the owner can be rendered from the template.
+func (t Template) UsableBy(owner string) bool {
+	return t.Shared() || t.Owner == owner
+}
+
+// Key identifies the template in the repository, users only share the names
+// of shared templates so private templates are keyed by owner and name.
+func (t Template) Key() string {
+	return TemplateKey(t.Owner, t.Name, t.Shared())
+}
+
+func TemplateKey(owner, name string, shared bool) string {
+	if shared {
+		return name
+	}
+	return owner + "/" + name
+}
@@ -0,0 +1,16 @@
+package models
+
+type User struct {
+	Name       string      `json:"name"`
+	Notifier   string      `json:"notifier,omitempty"`
+	QuietHours *QuietHours `json:"quiet_hours,omitempty"`
+}
+
+type Principal struct {
+	User  string
+	Admin bool
+}
+
+func (p Principal) CanAccess(r Reminder) bool {
+	return p.Admin || r.Owner == p.User
+}
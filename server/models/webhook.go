@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+type Webhook struct {
+	ID        int       `json:"id"`
+	Owner     string    `json:"owner,omitempty"`
+	Admin     bool      `json:"admin,omitempty"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events,omitempty"`
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (w Webhook) Matches(e Event) bool {
+	p := Principal{User: w.Owner, Admin: w.Admin}
+	if !p.CanAccess(e.Reminder) {
+		return false
+	}
+	if len(w.Events) == 0 {
+		return true
+	}
+	for _, t := range w.Events {
+		if t == e.Type {
+			return true
+		}
+	}
+	return false
+}
+
+type WebhookDelivery struct {
+	ID         string        `json:"id"`
+	WebhookID  int           `json:"webhook_id"`
+	EventID    int64         `json:"event_id"`
+	Event      string        `json:"event"`
+	Attempt    int           `json:"attempt"`
+	StatusCode int           `json:"status_code,omitempty"`
+	Error      string        `json:"error,omitempty"`
+	Duration   time.Duration `json:"duration"`
+	At         time.Time     `json:"at"`
+}
+
+func (d WebhookDelivery) Succeeded() bool {
+	return d.Error == "" && d.StatusCode >= 200 && d.StatusCode < 300
+}
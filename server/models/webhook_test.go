@@ -0,0 +1,30 @@
+package models
+
+import "testing"
+
+func TestWebhookMatches(t *testing.T) {
+	fired := Event{Type: EventReminderFired, Reminder: Reminder{ID: 1, Owner: "alice"}}
+	tests := []struct {
+		name    string
+		webhook Webhook
+		event   Event
+		want    bool
+	}{
+		{"owner without filter", Webhook{Owner: "alice"}, fired, true},
+		{"owner with matching filter", Webhook{Owner: "alice", Events: []string{EventReminderCreated, EventReminderFired}}, fired, true},
+		{"owner with other events", Webhook{Owner: "alice", Events: []string{EventReminderCreated}}, fired, false},
+		{"other user", Webhook{Owner: "bob"}, fired, false},
+		{"other user with matching filter", Webhook{Owner: "bob", Events: []string{EventReminderFired}}, fired, false},
+		{"admin sees every owner", Webhook{Owner: "root", Admin: true}, fired, true},
+		{"admin with other events", Webhook{Owner: "root", Admin: true, Events: []string{EventReminderDeleted}}, fired, false},
+		{"unowned reminder without auth", Webhook{}, Event{Type: EventReminderFired}, true},
+		{"unowned webhook on owned reminder", Webhook{}, fired, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.webhook.Matches(tt.event); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package repositories
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"app-pointment/server/models"
+)
+
+type Attempts struct {
+	path    string
+	mu      sync.Mutex
+	loaded  bool
+	modTime time.Time
+	log     map[string][]models.DeliveryAttempt
+}
+
+func NewAttempts(path string) *Attempts {
+	return &Attempts{path: path}
+}
+
+func (r *Attempts) LogAttempt(a models.DeliveryAttempt) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.load(); err != nil {
+		return err
+	}
+	key := strconv.Itoa(a.ReminderID)
+	entries := append(r.log[key], a)
+	if len(entries) > maxDeliveryLog {
+		entries = entries[len(entries)-maxDeliveryLog:]
+	}
+	r.log[key] = entries
+	return r.save()
+}
+
+func (r *Attempts) Attempts(reminderID int) ([]models.DeliveryAttempt, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	return append([]models.DeliveryAttempt{}, r.log[strconv.Itoa(reminderID)]...), nil
+}
+
+func (r *Attempts) load() error {
+	info, err := os.Stat(r.path)
+	if errors.Is(err, os.ErrNotExist) {
+		if !r.loaded {
+			r.log = map[string][]models.DeliveryAttempt{}
+			r.loaded = true
+		}
+		return nil
+	}
+	if err != nil {
+		return models.WrapError("could not stat delivery log file", err)
+	}
+	if r.loaded && info.ModTime().Equal(r.modTime) {
+		return nil
+	}
+	bs, err := ioutil.ReadFile(r.path)
+	if err != nil {
+		return models.WrapError("could not read delivery log file", err)
+	}
+	log := map[string][]models.DeliveryAttempt{}
+	if len(bs) > 0 {
+		if err := json.Unmarshal(bs, &log); err != nil {
+			return models.WrapError("could not unmarshal delivery log file", err)
+		}
+	}
+	r.log = log
+	r.modTime = info.ModTime()
+	r.loaded = true
+	return nil
+}
+
+func (r *Attempts) save() error {
+	bs, err := json.MarshalIndent(r.log, "", "  ")
+	if err != nil {
+		return models.WrapError("could not marshal delivery log file", err)
+	}
+	bs = append(bs, '\n')
+	if err := ioutil.WriteFile(r.path, bs, 0600); err != nil {
+		return models.WrapError("could not write delivery log file", err)
+	}
+	info, err := os.Stat(r.path)
+	if err != nil {
+		return models.WrapError("could not stat delivery log file", err)
+	}
+	r.modTime = info.ModTime()
+	return nil
+}
@@ -10,6 +10,8 @@ import (
 	"io/ioutil"
 	"log"
 	"os"
+	"path/filepath"
+	"time"
 
 	"app-pointment/server/models"
 )
@@ -84,6 +86,7 @@ func (d *DB) Write(bs []byte) (int, error) {
 	}
 	d.cfg.Checksum = checksum
 
+	start := time.Now()
 	if err := d.writeDBCfg(); err != nil {
 		return 0, err
 	}
@@ -92,6 +95,10 @@ func (d *DB) Write(bs []byte) (int, error) {
 		return 0, err
 	}
 	d.db = bs
+	dbWriteDuration.ObserveSince(start)
+	dbWrites.Inc()
+	dbWriteBytes.Add(float64(n))
+	dbSizeBytes.Set(float64(n))
 
 	return n, nil
 }
@@ -127,6 +134,52 @@ func (d DB) Stop() error {
 	return nil
 }
 
+// CheckHealth reports whether the database file can still be written. It is
+// only called once Start succeeded, a database which cannot be loaded stops
+// the server before it accepts requests.
+func (d *DB) CheckHealth() models.ComponentHealth {
+	res := models.ComponentHealth{
+		Name:    "database",
+		Status:  models.HealthUp,
+		Details: map[string]interface{}{"path": d.dbPath},
+	}
+	if err := checkWritable(d.dbPath); err != nil {
+		res.Status = models.HealthDown
+		res.Message = fmt.Sprintf("database is not writable: %v", err)
+		return res
+	}
+	res.Details["writable"] = true
+	return res
+}
+
+// checkWritable tests write access to an existing file and to its directory
+// without creating or changing the file.
+func checkWritable(path string) error {
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("%s was removed", path)
+	}
+	if err != nil {
+		return err
+	}
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("%s is not a regular file", path)
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	probe, err := ioutil.TempFile(filepath.Dir(path), ".health-*")
+	if err != nil {
+		return fmt.Errorf("directory is not writable: %v", err)
+	}
+	probe.Close()
+	return os.Remove(probe.Name())
+}
+
 func genChecksum(r io.Reader) (string, error) {
 	hash := sha256.New()
 	if _, err := io.Copy(hash, r); err != nil {
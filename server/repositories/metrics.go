@@ -0,0 +1,25 @@
+package repositories
+
+import (
+	"app-pointment/server/metrics"
+)
+
+var (
+	dbWrites = metrics.NewCounter(
+		"app_pointment_db_writes_total",
+		"Total number of database file writes.",
+	)
+	dbWriteBytes = metrics.NewCounter(
+		"app_pointment_db_write_bytes_total",
+		"Total number of bytes written to the database file.",
+	)
+	dbSizeBytes = metrics.NewGauge(
+		"app_pointment_db_size_bytes",
+		"Size of the last written database file.",
+	)
+	dbWriteDuration = metrics.NewHistogram(
+		"app_pointment_db_write_duration_seconds",
+		"Time spent writing the database file.",
+		metrics.DefaultBuckets,
+	)
+)
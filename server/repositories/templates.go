@@ -0,0 +1,135 @@
+package repositories
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"app-pointment/server/models"
+)
+
+type Templates struct {
+	path      string
+	mu        sync.Mutex
+	loaded    bool
+	modTime   time.Time
+	templates map[string]models.Template
+}
+
+func NewTemplates(path string) *Templates {
+	return &Templates{path: path}
+}
+
+func (r *Templates) Save(t models.Template) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.load(); err != nil {
+		return err
+	}
+	r.templates[t.Key()] = t
+	return r.save()
+}
+
+func (r *Templates) Get(key string) (models.Template, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.load(); err != nil {
+		return models.Template{}, err
+	}
+	t, ok := r.templates[key]
+	if !ok {
+		return models.Template{}, models.NotFoundError{
+			Message: fmt.Sprintf("could not find template with key: %s", key),
+		}
+	}
+	return t, nil
+}
+
+func (r *Templates) List() ([]models.Template, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	templates := make([]models.Template, 0, len(r.templates))
+	for _, t := range r.templates {
+		templates = append(templates, t)
+	}
+	sort.Slice(templates, func(i, j int) bool {
+		return templates[i].Name < templates[j].Name
+	})
+	return templates, nil
+}
+
+func (r *Templates) Delete(key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.load(); err != nil {
+		return err
+	}
+	if _, ok := r.templates[key]; !ok {
+		return models.NotFoundError{
+			Message: fmt.Sprintf("could not find template with key: %s", key),
+		}
+	}
+	delete(r.templates, key)
+	return r.save()
+}
+
+func (r *Templates) load() error {
+	info, err := os.Stat(r.path)
+	if errors.Is(err, os.ErrNotExist) {
+		if !r.loaded {
+			r.templates = map[string]models.Template{}
+			r.loaded = true
+		}
+		return nil
+	}
+	if err != nil {
+		return models.WrapError("could not stat templates file", err)
+	}
+	if r.loaded && info.ModTime().Equal(r.modTime) {
+		return nil
+	}
+	bs, err := ioutil.ReadFile(r.path)
+	if err != nil {
+		return models.WrapError("could not read templates file", err)
+	}
+	stored := map[string]models.Template{}
+	if len(bs) > 0 {
+		if err := json.Unmarshal(bs, &stored); err != nil {
+			return models.WrapError("could not unmarshal templates file", err)
+		}
+	}
+	// files written before private templates had their own keys are keyed by name
+	templates := make(map[string]models.Template, len(stored))
+	for _, t := range stored {
+		templates[t.Key()] = t
+	}
+	r.templates = templates
+	r.modTime = info.ModTime()
+	r.loaded = true
+	return nil
+}
+
+func (r *Templates) save() error {
+	bs, err := json.MarshalIndent(r.templates, "", "  ")
+	if err != nil {
+		return models.WrapError("could not marshal templates file", err)
+	}
+	bs = append(bs, '\n')
+	if err := ioutil.WriteFile(r.path, bs, 0644); err != nil {
+		return models.WrapError("could not write templates file", err)
+	}
+	info, err := os.Stat(r.path)
+	if err != nil {
+		return models.WrapError("could not stat templates file", err)
+	}
+	r.modTime = info.ModTime()
+	return nil
+}
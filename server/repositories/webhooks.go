@@ -0,0 +1,169 @@
+package repositories
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"app-pointment/server/models"
+)
+
+const maxDeliveryLog = 100
+
+type webhooksFile struct {
+	NextID     int                                 `json:"next_id"`
+	Webhooks   []models.Webhook                    `json:"webhooks"`
+	Deliveries map[string][]models.WebhookDelivery `json:"deliveries,omitempty"`
+}
+
+type Webhooks struct {
+	path    string
+	mu      sync.Mutex
+	loaded  bool
+	modTime time.Time
+	file    webhooksFile
+}
+
+func NewWebhooks(path string) *Webhooks {
+	return &Webhooks{path: path}
+}
+
+func (r *Webhooks) Create(w models.Webhook) (models.Webhook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.load(); err != nil {
+		return models.Webhook{}, err
+	}
+	r.file.NextID++
+	w.ID = r.file.NextID
+	w.CreatedAt = time.Now()
+	r.file.Webhooks = append(r.file.Webhooks, w)
+	if err := r.save(); err != nil {
+		return models.Webhook{}, err
+	}
+	return w, nil
+}
+
+func (r *Webhooks) List() ([]models.Webhook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	return append([]models.Webhook(nil), r.file.Webhooks...), nil
+}
+
+func (r *Webhooks) Get(id int) (models.Webhook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.load(); err != nil {
+		return models.Webhook{}, err
+	}
+	for _, w := range r.file.Webhooks {
+		if w.ID == id {
+			return w, nil
+		}
+	}
+	return models.Webhook{}, notFoundWebhook(id)
+}
+
+func (r *Webhooks) Delete(id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.load(); err != nil {
+		return err
+	}
+	for i, w := range r.file.Webhooks {
+		if w.ID != id {
+			continue
+		}
+		r.file.Webhooks = append(r.file.Webhooks[:i], r.file.Webhooks[i+1:]...)
+		delete(r.file.Deliveries, strconv.Itoa(id))
+		return r.save()
+	}
+	return notFoundWebhook(id)
+}
+
+func (r *Webhooks) LogDelivery(d models.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.load(); err != nil {
+		return err
+	}
+	if r.file.Deliveries == nil {
+		r.file.Deliveries = map[string][]models.WebhookDelivery{}
+	}
+	key := strconv.Itoa(d.WebhookID)
+	entries := append(r.file.Deliveries[key], d)
+	if len(entries) > maxDeliveryLog {
+		entries = entries[len(entries)-maxDeliveryLog:]
+	}
+	r.file.Deliveries[key] = entries
+	return r.save()
+}
+
+func (r *Webhooks) Deliveries(id int) ([]models.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	return append([]models.WebhookDelivery{}, r.file.Deliveries[strconv.Itoa(id)]...), nil
+}
+
+func (r *Webhooks) load() error {
+	info, err := os.Stat(r.path)
+	if errors.Is(err, os.ErrNotExist) {
+		if !r.loaded {
+			r.file = webhooksFile{}
+			r.loaded = true
+		}
+		return nil
+	}
+	if err != nil {
+		return models.WrapError("could not stat webhooks file", err)
+	}
+	if r.loaded && info.ModTime().Equal(r.modTime) {
+		return nil
+	}
+	bs, err := ioutil.ReadFile(r.path)
+	if err != nil {
+		return models.WrapError("could not read webhooks file", err)
+	}
+	var file webhooksFile
+	if len(bs) > 0 {
+		if err := json.Unmarshal(bs, &file); err != nil {
+			return models.WrapError("could not unmarshal webhooks file", err)
+		}
+	}
+	r.file = file
+	r.modTime = info.ModTime()
+	r.loaded = true
+	return nil
+}
+
+func (r *Webhooks) save() error {
+	bs, err := json.MarshalIndent(r.file, "", "  ")
+	if err != nil {
+		return models.WrapError("could not marshal webhooks file", err)
+	}
+	bs = append(bs, '\n')
+	if err := ioutil.WriteFile(r.path, bs, 0600); err != nil {
+		return models.WrapError("could not write webhooks file", err)
+	}
+	info, err := os.Stat(r.path)
+	if err != nil {
+		return models.WrapError("could not stat webhooks file", err)
+	}
+	r.modTime = info.ModTime()
+	return nil
+}
+
+func notFoundWebhook(id int) error {
+	return models.NotFoundError{Message: fmt.Sprintf("could not find webhook with id: %d", id)}
+}
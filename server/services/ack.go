@@ -0,0 +1,137 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"app-pointment/server/models"
+)
+
+func (s Reminders) Ack(ctx context.Context, p models.Principal, id int, deliveryID string) (models.Reminder, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	index, reminder, err := s.awaiting(p, id)
+	if err != nil {
+		return models.Reminder{}, err
+	}
+	deliveries, resolved, err := s.resolveDeliveries(reminder, deliveryID, models.ChannelDelivery{
+		Status: models.DeliveryDelivered,
+		At:     time.Now(),
+	})
+	if err != nil {
+		return models.Reminder{}, err
+	}
+	if err := expired(ctx); err != nil {
+		return models.Reminder{}, err
+	}
+	reminder.Deliveries = deliveries
+	s.recordResolved(reminder, resolved, "")
+	if s.channels.Delivered(reminder) {
+		s.groom(reminder)
+		_, reminder = s.Snapshot.All.flatten(reminder.ID)
+		return reminder, nil
+	}
+	s.Snapshot.All[reminder.ID] = map[int]models.Reminder{index: reminder}
+	s.Snapshot.UnCompleted[reminder.ID] = map[int]models.Reminder{index: reminder}
+	return reminder, nil
+}
+
+func (s Reminders) Snooze(ctx context.Context, p models.Principal, id int, deliveryID string, d time.Duration) (models.Reminder, error) {
+	if d <= 0 {
+		return models.Reminder{}, models.DataValidationError{Message: "snooze duration must be > 0s"}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, reminder, err := s.awaiting(p, id)
+	if err != nil {
+		return models.Reminder{}, err
+	}
+	if err := expired(ctx); err != nil {
+		return models.Reminder{}, err
+	}
+	if deliveryID != "" || awaitingAck(reminder) {
+		deliveries, resolved, err := s.resolveDeliveries(reminder, deliveryID, models.ChannelDelivery{
+			Status: models.DeliverySnoozed,
+			Snooze: d,
+			At:     time.Now(),
+		})
+		if err != nil {
+			return models.Reminder{}, err
+		}
+		reminder.Deliveries = deliveries
+		s.recordResolved(reminder, resolved, d.String())
+	}
+	s.reschedule(reminder, d)
+	_, reminder = s.Snapshot.All.flatten(reminder.ID)
+	s.events.Publish(models.EventReminderSnoozed, reminder)
+	return reminder, nil
+}
+
+func (s Reminders) awaiting(p models.Principal, id int) (int, models.Reminder, error) {
+	index, reminder, err := s.find(p, id)
+	if err != nil {
+		return 0, models.Reminder{}, err
+	}
+	if _, ok := s.Snapshot.UnCompleted[id]; !ok {
+		err := models.DataValidationError{
+			Message: fmt.Sprintf("reminder with id %d is not pending", id),
+		}
+		return 0, models.Reminder{}, err
+	}
+	return index, reminder, nil
+}
+
+func awaitingAck(reminder models.Reminder) bool {
+	for _, d := range reminder.Deliveries {
+		if d.Status == models.DeliveryAwaiting {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveDeliveries applies the outcome to the channel which issued the given
+// delivery id, or to every channel still awaiting an acknowledgement. The
+// awaiting deliveries it replaced are returned by channel.
+func (s Reminders) resolveDeliveries(reminder models.Reminder, deliveryID string, outcome models.ChannelDelivery) (map[string]models.ChannelDelivery, map[string]models.ChannelDelivery, error) {
+	deliveries := make(map[string]models.ChannelDelivery, len(reminder.Deliveries))
+	resolved := map[string]models.ChannelDelivery{}
+	for name, d := range reminder.Deliveries {
+		if d.Status == models.DeliveryAwaiting && (deliveryID == "" || d.DeliveryID == deliveryID) {
+			resolved[name] = d
+			outcome.DeliveryID = d.DeliveryID
+			d = outcome
+		}
+		deliveries[name] = d
+	}
+	if len(resolved) == 0 {
+		if deliveryID == "" {
+			return nil, nil, models.DataValidationError{
+				Message: fmt.Sprintf("reminder with id %d is not awaiting an acknowledgement", reminder.ID),
+			}
+		}
+		return nil, nil, models.NotFoundError{
+			Message: fmt.Sprintf("could not find delivery %s of reminder %d", deliveryID, reminder.ID),
+		}
+	}
+	return deliveries, resolved, nil
+}
+
+// recordResolved logs the answer to awaiting deliveries as attempts, the
+// latency is the time the user took to answer since the notification.
+func (s Reminders) recordResolved(reminder models.Reminder, resolved map[string]models.ChannelDelivery, value string) {
+	for name, awaiting := range resolved {
+		d := reminder.Deliveries[name]
+		s.DeliveryLog.Record(models.DeliveryAttempt{
+			ReminderID:      reminder.ID,
+			Channel:         name,
+			Attempt:         reminder.Attempts + 1,
+			Outcome:         d.Status,
+			ActivationValue: value,
+			DeliveryID:      d.DeliveryID,
+			Latency:         d.At.Sub(awaiting.At),
+			At:              d.At,
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"app-pointment/server/models"
+)
+
+// newAwaitingReminders returns a reminder of alice awaiting acknowledgements
+// with the given deliveries.
+func newAwaitingReminders(t *testing.T, deliveries map[string]models.ChannelDelivery) *Reminders {
+	s, _ := newTestReminders(t)
+	createReminders(t, s, "a")
+	index, r := s.Snapshot.All.flatten(1)
+	r.Owner = "alice"
+	r.Deliveries = deliveries
+	s.Snapshot.All[1] = map[int]models.Reminder{index: r}
+	s.Snapshot.UnCompleted[1] = map[int]models.Reminder{index: r}
+	return s
+}
+
+func TestAck(t *testing.T) {
+	alice := models.Principal{User: "alice"}
+	awaiting := map[string]models.ChannelDelivery{
+		"desktop": {Status: models.DeliveryAwaiting, DeliveryID: "d1"},
+		"email":   {Status: models.DeliveryAwaiting, DeliveryID: "d2"},
+	}
+	partly := map[string]models.ChannelDelivery{
+		"desktop": {Status: models.DeliveryAwaiting, DeliveryID: "d1"},
+		"email":   {Status: models.DeliveryDelivered},
+	}
+	tests := []struct {
+		name       string
+		deliveries map[string]models.ChannelDelivery
+		principal  models.Principal
+		deliveryID string
+		status     string
+		awaiting   int
+		err        error
+	}{
+		{"every channel", awaiting, alice, "", models.StatusCompleted, 0, nil},
+		{"one of two channels", awaiting, alice, "d1", models.StatusPending, 1, nil},
+		{"last awaiting channel", partly, alice, "d1", models.StatusCompleted, 0, nil},
+		{"unknown delivery", awaiting, alice, "d9", "", 0, models.NotFoundError{}},
+		{"nothing awaiting", map[string]models.ChannelDelivery{"desktop": {Status: models.DeliveryFailed}}, alice, "", "", 0, models.DataValidationError{}},
+		{"reminder of another user", awaiting, models.Principal{User: "bob"}, "", "", 0, models.NotFoundError{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newAwaitingReminders(t, tt.deliveries)
+			r, err := s.Ack(context.Background(), tt.principal, 1, tt.deliveryID)
+			if tt.err != nil {
+				if err == nil || !sameErrorType(err, tt.err) {
+					t.Fatalf("got error %v, want %T", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r.Status != tt.status {
+				t.Errorf("reminder is %s, want %s", r.Status, tt.status)
+			}
+			if got := len(r.Deliveries) - countDelivered(r); got != tt.awaiting {
+				t.Errorf("%d delivery(ies) still awaiting, want %d", got, tt.awaiting)
+			}
+			_, stored := s.Snapshot.All.flatten(1)
+			if stored.Status != tt.status {
+				t.Errorf("stored reminder is %s, want %s", stored.Status, tt.status)
+			}
+		})
+	}
+}
+
+func TestAckCompletedReminder(t *testing.T) {
+	s := newAwaitingReminders(t, map[string]models.ChannelDelivery{"desktop": {Status: models.DeliveryAwaiting, DeliveryID: "d1"}})
+	p := models.Principal{User: "alice"}
+	if _, err := s.Ack(context.Background(), p, 1, "d1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Ack(context.Background(), p, 1, "d1"); !errors.As(err, &models.DataValidationError{}) {
+		t.Errorf("acknowledged a completed reminder: %v", err)
+	}
+}
+
+func TestSnooze(t *testing.T) {
+	alice := models.Principal{User: "alice"}
+	awaiting := map[string]models.ChannelDelivery{
+		"desktop": {Status: models.DeliveryAwaiting, DeliveryID: "d1"},
+		"email":   {Status: models.DeliveryAwaiting, DeliveryID: "d2"},
+	}
+	tests := []struct {
+		name       string
+		deliveries map[string]models.ChannelDelivery
+		deliveryID string
+		d          time.Duration
+		snoozed    int
+		err        error
+	}{
+		{"every awaiting channel", awaiting, "", 10 * time.Minute, 2, nil},
+		{"one delivery", awaiting, "d2", 10 * time.Minute, 1, nil},
+		{"nothing awaiting", nil, "", 10 * time.Minute, 0, nil},
+		{"unknown delivery", awaiting, "d9", 10 * time.Minute, 0, models.NotFoundError{}},
+		{"no duration", awaiting, "", 0, 0, models.DataValidationError{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newAwaitingReminders(t, tt.deliveries)
+			r, err := s.Snooze(context.Background(), alice, 1, tt.deliveryID, tt.d)
+			if tt.err != nil {
+				if err == nil || !sameErrorType(err, tt.err) {
+					t.Fatalf("got error %v, want %T", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r.Status != models.StatusPending || r.Duration != tt.d {
+				t.Errorf("reminder is %s and notified again after %v, want pending after %v", r.Status, r.Duration, tt.d)
+			}
+			snoozed := 0
+			for _, d := range r.Deliveries {
+				if d.Status == models.DeliverySnoozed {
+					snoozed++
+				}
+			}
+			if snoozed != tt.snoozed {
+				t.Errorf("%d delivery(ies) snoozed, want %d", snoozed, tt.snoozed)
+			}
+		})
+	}
+}
+
+func countDelivered(r models.Reminder) int {
+	n := 0
+	for _, d := range r.Deliveries {
+		if d.Status == models.DeliveryDelivered {
+			n++
+		}
+	}
+	return n
+}
+
+func sameErrorType(err, target error) bool {
+	switch target.(type) {
+	case models.NotFoundError:
+		return errors.As(err, &models.NotFoundError{})
+	case models.DataValidationError:
+		return errors.As(err, &models.DataValidationError{})
+	}
+	return false
+}
@@ -0,0 +1,50 @@
+package services
+
+import (
+	"log"
+
+	"app-pointment/server/models"
+)
+
+type AttemptRepository interface {
+	LogAttempt(a models.DeliveryAttempt) error
+	Attempts(reminderID int) ([]models.DeliveryAttempt, error)
+}
+
+// DeliveryLog keeps the notification attempts of every reminder, with the
+// acknowledgements and snoozes answering them and quiet hours deferrals.
+// Escalation steps are kept in the reminder history instead. A nil
+// DeliveryLog records nothing.
+type DeliveryLog struct {
+	repo AttemptRepository
+}
+
+func NewDeliveryLog(repo AttemptRepository) *DeliveryLog {
+	return &DeliveryLog{repo: repo}
+}
+
+func (l *DeliveryLog) Record(a models.DeliveryAttempt) {
+	if l == nil {
+		return
+	}
+	if err := l.repo.LogAttempt(a); err != nil {
+		log.Printf("could not record delivery attempt of reminder with id %d: %v\n", a.ReminderID, err)
+	}
+}
+
+func (l *DeliveryLog) List(reminderID int) ([]models.DeliveryAttempt, error) {
+	if l == nil {
+		return []models.DeliveryAttempt{}, nil
+	}
+	return l.repo.Attempts(reminderID)
+}
+
+func (s Reminders) Deliveries(p models.Principal, id int) ([]models.DeliveryAttempt, error) {
+	s.mu.RLock()
+	_, _, err := s.find(p, id)
+	s.mu.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+	return s.DeliveryLog.List(id)
+}
@@ -1,7 +1,11 @@
 package services
 
 import (
+	"errors"
+	"fmt"
 	"log"
+	"math/rand"
+	"sync"
 	"time"
 
 	"app-pointment/server/models"
@@ -14,6 +18,10 @@ type saver interface {
 type BackgroundSaver struct {
 	ticker  *time.Ticker
 	service saver
+
+	mu        sync.Mutex
+	lastSave  time.Time
+	lastError error
 }
 
 func NewSaver(service saver) *BackgroundSaver {
@@ -29,49 +37,96 @@ func (s *BackgroundSaver) Start() {
 	for {
 		select {
 		case <-s.ticker.C:
-			err := s.service.save()
-			if err != nil {
-				log.Printf("could not save records in background: %v", err)
-			}
+			s.save()
 		}
 	}
 }
 
-func (s *BackgroundSaver) Stop() error {
-	s.ticker.Stop()
+func (s *BackgroundSaver) save() error {
+	start := time.Now()
 	err := s.service.save()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastError = err
 	if err != nil {
+		saveErrors.Inc()
+		log.Printf("could not save records in background: %v", err)
 		return err
 	}
-	log.Println("background saver stopped")
+	s.lastSave = time.Now()
+	saveDuration.ObserveSince(start)
 	return nil
 }
 
-type HTTPNotifierClient interface {
-	Notify(reminder models.Reminder) (NotificationResponse, error)
+func (s *BackgroundSaver) CheckHealth() models.ComponentHealth {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	res := models.ComponentHealth{
+		Name:    "saver",
+		Status:  models.HealthUp,
+		Details: map[string]interface{}{},
+	}
+	if !s.lastSave.IsZero() {
+		res.Details["last_successful_save"] = s.lastSave
+	}
+	if s.lastError != nil {
+		res.Status = models.HealthDown
+		res.Message = fmt.Sprintf("last save failed: %v", s.lastError)
+	}
+	return res
+}
+
+func (s *BackgroundSaver) Stop() error {
+	s.ticker.Stop()
+	err := s.save()
+	if err != nil {
+		return err
+	}
+	log.Println("background saver stopped")
+	return nil
 }
 
 type snapshotManager interface {
 	snapshot() Snapshot
 	snapshotGrooming(notifiedReminders ...models.Reminder)
 	retry(reminder models.Reminder, duration time.Duration)
+	fail(reminder models.Reminder)
+	deferUntil(reminder models.Reminder, until time.Time)
 }
 
+const (
+	tickPeriod        = time.Second
+	maxTickDelay      = 5 * tickPeriod
+	defaultAckTimeout = 15 * time.Minute
+)
+
 type BackgroundNotifier struct {
 	ticker    *time.Ticker
 	service   snapshotManager
 	completed chan models.Reminder
-	Client    HTTPNotifierClient
+	Channels  *Channels
+	Events    *Events
+	Templates *Templates
+	Quiet     *QuietHours
+	Log       *DeliveryLog
+
+	AckTimeout time.Duration
+
+	mu        sync.Mutex
+	lastTick  time.Time
+	notifying map[int]bool
 }
 
-func NewNotifier(notifierURI string, service snapshotManager) *BackgroundNotifier {
-	ticker := time.NewTicker(1 * time.Second)
-	httpClient := NewHTTPClient(notifierURI)
+func NewNotifier(channels *Channels, service snapshotManager) *BackgroundNotifier {
+	ticker := time.NewTicker(tickPeriod)
 	return &BackgroundNotifier{
-		ticker:    ticker,
-		service:   service,
-		completed: make(chan models.Reminder),
-		Client:    httpClient,
+		lastTick:   time.Now(),
+		ticker:     ticker,
+		service:    service,
+		completed:  make(chan models.Reminder),
+		notifying:  map[int]bool{},
+		Channels:   channels,
+		AckTimeout: defaultAckTimeout,
 	}
 }
 
@@ -80,16 +135,27 @@ func (s *BackgroundNotifier) Start() {
 	for {
 		select {
 		case <-s.ticker.C:
+			s.mu.Lock()
+			s.lastTick = time.Now()
+			s.mu.Unlock()
 			snapshot := s.service.snapshot()
+			var retrying int
 			for id := range snapshot.UnCompleted {
 				_, reminder := snapshot.UnCompleted.flatten(id)
+				if reminder.Attempts > 0 {
+					retrying++
+				}
 				reminderTick := reminder.ModifiedAt.Add(reminder.Duration).UnixNano()
 				nowTick := time.Now().UnixNano()
 				deltaTick := time.Now().Add(time.Second).UnixNano()
 				if reminderTick > nowTick && reminderTick < deltaTick {
-					go s.notify(reminder)
+					s.dispatch(reminder, s.notify)
+				} else if s.escalationDue(reminder) {
+					s.dispatch(reminder, s.escalateOnTime)
 				}
 			}
+			pendingReminders.Set(float64(len(snapshot.UnCompleted)))
+			retryingReminders.Set(float64(retrying))
 		case r := <-s.completed:
 			log.Printf("reminder with with: %d was completed\n", r.ID)
 		}
@@ -97,17 +163,336 @@ func (s *BackgroundNotifier) Start() {
 }
 
 func (s *BackgroundNotifier) notify(r models.Reminder) {
-	res, err := s.Client.Notify(r)
-	if err != nil {
-		log.Printf("could not notify reminder with id %d\n", r.ID)
-		log.Printf("background http client error: %v\n", err)
+	targets, ok := s.targets(r)
+	if !ok {
+		return
+	}
+	if r.Status == models.StatusDeferred {
+		r.Status = models.StatusPending
+		r.DeferredUntil = nil
+	}
+	if r.FirstNotifiedAt == nil {
+		now := time.Now()
+		r.FirstNotifiedAt = &now
+	}
+	s.Events.Publish(models.EventReminderFired, r)
+	deliveries := make(map[string]models.ChannelDelivery, len(r.Deliveries))
+	for name, d := range r.Deliveries {
+		deliveries[name] = d
+	}
 
-	} else if res.completed {
+	rendered, err := s.Templates.Render(r)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, name := range targets {
+		if deliveries[name].Status == models.DeliveryDelivered {
+			continue
+		}
+		if err != nil {
+			notifications.With(name, "failure").Inc()
+			deliveries[name] = models.ChannelDelivery{
+				Status: models.DeliveryFailed,
+				Error:  err.Error(),
+				At:     time.Now(),
+			}
+			s.Log.Record(models.DeliveryAttempt{
+				ReminderID: r.ID,
+				Channel:    name,
+				Attempt:    r.Attempts + 1,
+				Outcome:    models.DeliveryFailed,
+				Error:      err.Error(),
+				At:         time.Now(),
+			})
+			continue
+		}
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			d := s.deliver(name, rendered)
+			mu.Lock()
+			deliveries[name] = d
+			mu.Unlock()
+		}(name)
+	}
+	wg.Wait()
+	r.Deliveries = deliveries
+
+	var failed []string
+	var snooze, blocked time.Duration
+	var awaiting, unanswered bool
+	for _, name := range targets {
+		d := deliveries[name]
+		switch d.Status {
+		case models.DeliveryFailed:
+			failed = append(failed, name)
+			r.LastError = fmt.Sprintf("%s: %s", name, d.Error)
+		case models.DeliveryCircuitOpen:
+			wait := d.Snooze
+			if wait < minRetryDelay {
+				wait = minRetryDelay
+			}
+			if blocked == 0 || wait < blocked {
+				blocked = wait
+			}
+			r.LastError = fmt.Sprintf("%s: %s", name, d.Error)
+		case models.DeliverySnoozed:
+			if snooze == 0 || d.Snooze < snooze {
+				snooze = d.Snooze
+			}
+		case models.DeliveryAwaiting:
+			awaiting = true
+		case models.DeliveryUnanswered:
+			unanswered = true
+		}
+	}
+	if snooze == 0 && (len(failed) > 0 || awaiting || unanswered) && s.escalate(&r) {
+		s.service.retry(r, minRetryDelay)
+		return
+	}
+	if len(failed) == 0 && snooze == 0 && blocked == 0 && !unanswered && awaiting {
+		log.Printf("reminder with id %d is awaiting acknowledgement\n", r.ID)
+		s.service.retry(r, s.AckTimeout)
+		return
+	}
+	if len(failed) == 0 && snooze == 0 && blocked == 0 && !unanswered {
+		r.LastError = ""
 		s.service.snapshotGrooming(r)
 		s.completed <- r
 		return
 	}
-	s.service.retry(r, res.duration)
+	if len(failed) > 0 {
+		r.Attempts++
+		policy := s.Channels.RetryPolicy(r, failed)
+		if policy.Exhausted(r.Attempts) {
+			deadLettered.Inc()
+			log.Printf("reminder with id %d failed after %d attempt(s)\n", r.ID, r.Attempts)
+			s.service.fail(r)
+			return
+		}
+		snooze = policy.Delay(r.Attempts, rand.Float64)
+		notificationRetries.Inc()
+	} else if blocked > 0 {
+		// Deliveries refused by an open breaker never reached the notifier,
+		// they wait for the cooldown instead of spending an attempt.
+		if snooze == 0 || blocked < snooze {
+			snooze = blocked
+		}
+	} else if snooze > 0 {
+		notificationSnoozes.Inc()
+		s.Events.Publish(models.EventReminderSnoozed, r)
+	} else {
+		// nobody answered, notify again without spending an attempt
+		log.Printf("reminder with id %d was not answered\n", r.ID)
+		notificationsUnanswered.Inc()
+		snooze = retryPeriod
+	}
+	s.service.retry(r, snooze)
+}
+
+// dispatch runs fn for the reminder in the background unless the reminder is
+// already being notified.
+func (s *BackgroundNotifier) dispatch(r models.Reminder, fn func(models.Reminder)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.notifying[r.ID] {
+		return
+	}
+	s.notifying[r.ID] = true
+	go func() {
+		defer func() {
+			s.mu.Lock()
+			delete(s.notifying, r.ID)
+			s.mu.Unlock()
+		}()
+		fn(r)
+	}()
+}
+
+// escalate counts an unacknowledged notification and moves the reminder to
+// the next step of its escalation policy when that step is due.
+func (s *BackgroundNotifier) escalate(r *models.Reminder) bool {
+	policy, step, ok := s.nextStep(*r)
+	if !ok {
+		return false
+	}
+	r.Unacknowledged++
+	if !step.Due(r.Unacknowledged, time.Since(*r.FirstNotifiedAt)) {
+		return false
+	}
+	s.advance(r, policy, step)
+	return true
+}
+
+// escalationDue reports whether a notified reminder reached the time trigger
+// of its next escalation step. Reminders awaiting an acknowledgement or held
+// back by quiet hours are not notified again before the step is due.
+func (s *BackgroundNotifier) escalationDue(r models.Reminder) bool {
+	if r.FirstNotifiedAt == nil {
+		return false
+	}
+	_, step, ok := s.nextStep(r)
+	return ok && step.After > 0 && time.Since(*r.FirstNotifiedAt) >= step.After
+}
+
+// escalateOnTime moves the reminder to its next escalation step and notifies
+// it through the escalated channels, deferred reminders keep waiting for the
+// end of quiet hours.
+func (s *BackgroundNotifier) escalateOnTime(r models.Reminder) {
+	policy, step, ok := s.nextStep(r)
+	if !ok {
+		return
+	}
+	s.advance(&r, policy, step)
+	if r.Status == models.StatusDeferred && r.DeferredUntil != nil {
+		s.service.retry(r, time.Until(*r.DeferredUntil))
+		return
+	}
+	s.notify(r)
+}
+
+func (s *BackgroundNotifier) nextStep(r models.Reminder) (models.EscalationPolicy, models.EscalationStep, bool) {
+	policy, ok := s.Channels.EscalationPolicy(r.EscalationPolicy)
+	if !ok || r.EscalationLevel >= len(policy.Steps) {
+		return models.EscalationPolicy{}, models.EscalationStep{}, false
+	}
+	return policy, policy.Steps[r.EscalationLevel], true
+}
+
+func (s *BackgroundNotifier) advance(r *models.Reminder, policy models.EscalationPolicy, step models.EscalationStep) {
+	r.EscalationLevel++
+	r.Unacknowledged = 0
+	r.Record(models.HistoryEntry{
+		At:       time.Now(),
+		Type:     models.HistoryEscalation,
+		Level:    r.EscalationLevel,
+		Channels: step.Channels,
+		Reason:   step.String(),
+	})
+	log.Printf("reminder with id %d escalated to level %d of %s: %v\n", r.ID, r.EscalationLevel, policy.Name, step.Channels)
+	escalations.With(policy.Name).Inc()
+	s.Events.Publish(models.EventReminderEscalated, *r)
+}
+
+// targets returns the channels the reminder is delivered to right now. During
+// quiet hours it is either deferred to the end of the window or delivered
+// only to the silent channel.
+func (s *BackgroundNotifier) targets(r models.Reminder) ([]string, bool) {
+	if r.IgnoreQuietHours {
+		return s.Channels.Targets(r), true
+	}
+	hours, ok := s.Quiet.For(r.Owner)
+	if !ok {
+		return s.Channels.Targets(r), true
+	}
+	until, quiet := hours.Until(time.Now())
+	if !quiet {
+		return s.Channels.Targets(r), true
+	}
+	if hours.Mode == models.QuietModeSilent && s.Channels.Has(hours.Channel) {
+		log.Printf("reminder with id %d is delivered silently through %s during quiet hours\n", r.ID, hours.Channel)
+		return []string{hours.Channel}, true
+	}
+	log.Printf("reminder with id %d is deferred until %v by quiet hours\n", r.ID, until)
+	quietDeferrals.Inc()
+	for _, name := range s.Channels.Targets(r) {
+		s.Log.Record(models.DeliveryAttempt{
+			ReminderID:    r.ID,
+			Channel:       name,
+			Attempt:       r.Attempts + 1,
+			Outcome:       models.DeliveryDeferred,
+			DeferredUntil: &until,
+			At:            time.Now(),
+		})
+	}
+	s.service.deferUntil(r, until)
+	return nil, false
+}
+
+// deliver notifies the reminder through a channel and records the attempt.
+func (s *BackgroundNotifier) deliver(name string, r models.Reminder) models.ChannelDelivery {
+	start := time.Now()
+	d, res := s.send(name, r)
+	s.Log.Record(models.DeliveryAttempt{
+		ReminderID:      r.ID,
+		Channel:         name,
+		Attempt:         r.Attempts + 1,
+		Outcome:         d.Status,
+		ActivationType:  res.activationType,
+		ActivationValue: res.activationValue,
+		DeliveryID:      d.DeliveryID,
+		Error:           d.Error,
+		Latency:         time.Since(start),
+		At:              start,
+	})
+	return d
+}
+
+func (s *BackgroundNotifier) send(name string, r models.Reminder) (models.ChannelDelivery, NotificationResponse) {
+	ch, ok := s.Channels.Get(name)
+	if !ok {
+		notifications.With(name, "failure").Inc()
+		return models.ChannelDelivery{
+			Status: models.DeliveryFailed,
+			Error:  fmt.Sprintf("unknown channel %q", name),
+			At:     time.Now(),
+		}, NotificationResponse{}
+	}
+	res, err := ch.Notify(r)
+	var open models.CircuitOpenError
+	if errors.As(err, &open) {
+		log.Printf("reminder with id %d is held back from %s: %v\n", r.ID, name, err)
+		return models.ChannelDelivery{
+			Status: models.DeliveryCircuitOpen,
+			Error:  err.Error(),
+			Snooze: open.RetryAfter,
+			At:     time.Now(),
+		}, res
+	}
+	if err != nil {
+		notifications.With(name, "failure").Inc()
+		log.Printf("could not notify reminder with id %d through %s\n", r.ID, name)
+		log.Printf("background channel error: %v\n", err)
+		return models.ChannelDelivery{
+			Status: models.DeliveryFailed,
+			Error:  err.Error(),
+			At:     time.Now(),
+		}, res
+	}
+	notifications.With(name, "success").Inc()
+	if res.completed {
+		return models.ChannelDelivery{Status: models.DeliveryDelivered, At: time.Now()}, res
+	}
+	if res.unanswered {
+		return models.ChannelDelivery{Status: models.DeliveryUnanswered, At: time.Now()}, res
+	}
+	if res.deliveryID != "" {
+		return models.ChannelDelivery{
+			Status:     models.DeliveryAwaiting,
+			DeliveryID: res.deliveryID,
+			At:         time.Now(),
+		}, res
+	}
+	return models.ChannelDelivery{
+		Status: models.DeliverySnoozed,
+		Snooze: res.duration,
+		At:     time.Now(),
+	}, res
+}
+
+func (s *BackgroundNotifier) CheckHealth() models.ComponentHealth {
+	s.mu.Lock()
+	lag := time.Since(s.lastTick)
+	s.mu.Unlock()
+	res := models.ComponentHealth{
+		Name:    "scheduler",
+		Status:  models.HealthUp,
+		Details: map[string]interface{}{"lag": lag.String()},
+	}
+	if lag > maxTickDelay {
+		res.Status = models.HealthDown
+		res.Message = fmt.Sprintf("scheduler has not ticked for %v", lag.Round(time.Second))
+	}
+	return res
 }
 
 func (s *BackgroundNotifier) Stop() error {
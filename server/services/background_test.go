@@ -0,0 +1,192 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"app-pointment/server/models"
+)
+
+// newTestNotifier runs a notifier answering every notification with status
+// and body, the reminder with id 1 is due.
+func newTestNotifier(t *testing.T, status int, body string) (*BackgroundNotifier, *Reminders) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		fmt.Fprint(w, body)
+	}))
+	t.Cleanup(srv.Close)
+
+	channels := NewChannels(models.RetryPolicy{MaxAttempts: 3, InitialDelay: time.Minute}, "desktop")
+	if err := channels.Register(NewHTTPClient("desktop", srv.URL, ChannelOptions{})); err != nil {
+		t.Fatal(err)
+	}
+	s, _ := newTestReminders(t)
+	createReminders(t, s, "a")
+	return NewNotifier(channels, s), s
+}
+
+func TestNotifyAnswers(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		delivery string
+		attempts int
+		retry    time.Duration
+	}{
+		{"unanswered", http.StatusOK, `{"activationType":"timeout","activationValue":""}`, models.DeliveryUnanswered, 0, retryPeriod},
+		{"empty reply", http.StatusOK, `{"activationType":"replied","activationValue":""}`, models.DeliveryUnanswered, 0, retryPeriod},
+		{"snoozed", http.StatusOK, `{"activationType":"replied","activationValue":"5m"}`, models.DeliverySnoozed, 0, 5 * time.Minute},
+		{"invalid reply", http.StatusOK, `{"activationType":"replied","activationValue":"soon"}`, models.DeliveryFailed, 1, time.Minute},
+		{"notifier error", http.StatusInternalServerError, ``, models.DeliveryFailed, 1, time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, s := newTestNotifier(t, tt.status, tt.body)
+			_, r := s.Snapshot.All.flatten(1)
+			n.notify(r)
+
+			_, r = s.Snapshot.All.flatten(1)
+			if got := r.Deliveries["desktop"].Status; got != tt.delivery {
+				t.Errorf("delivery status = %s, want %s", got, tt.delivery)
+			}
+			if r.Attempts != tt.attempts || r.Status != models.StatusPending {
+				t.Errorf("reminder is %s after %d attempts, want %d", r.Status, r.Attempts, tt.attempts)
+			}
+			if r.Duration != tt.retry {
+				t.Errorf("notified again after %v, want %v", r.Duration, tt.retry)
+			}
+			if _, ok := s.Snapshot.UnCompleted[1]; !ok {
+				t.Error("reminder is no longer pending")
+			}
+		})
+	}
+}
+
+func TestNotifyUnansweredKeepsAttempts(t *testing.T) {
+	n, s := newTestNotifier(t, http.StatusOK, `{"activationType":"timeout"}`)
+	for i := 0; i < 15; i++ {
+		_, r := s.Snapshot.All.flatten(1)
+		n.notify(r)
+	}
+	_, r := s.Snapshot.All.flatten(1)
+	if r.Status != models.StatusPending || r.Attempts != 0 {
+		t.Errorf("unanswered reminder is %s after %d attempts", r.Status, r.Attempts)
+	}
+}
+
+func TestEscalationOnTime(t *testing.T) {
+	tests := []struct {
+		name     string
+		notified time.Duration
+		deferred bool
+		due      bool
+		paged    int32
+	}{
+		{"awaiting past the step", 20 * time.Minute, false, true, 1},
+		{"awaiting before the step", 5 * time.Minute, false, false, 0},
+		{"deferred past the step", 20 * time.Minute, true, true, 0},
+		{"never notified", 0, false, false, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var paged int32
+			pager := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&paged, 1)
+				w.WriteHeader(http.StatusAccepted)
+				fmt.Fprint(w, `{"deliveryId":"p1"}`)
+			}))
+			t.Cleanup(pager.Close)
+			n, s := newTestNotifier(t, http.StatusAccepted, `{"deliveryId":"d1"}`)
+			if err := n.Channels.Register(NewHTTPClient("pager", pager.URL, ChannelOptions{})); err != nil {
+				t.Fatal(err)
+			}
+			policy := models.EscalationPolicy{Name: "oncall", Steps: []models.EscalationStep{{After: 10 * time.Minute, Channels: []string{"pager"}}}}
+			if err := n.Channels.SetEscalationPolicy(policy); err != nil {
+				t.Fatal(err)
+			}
+
+			index, r := s.Snapshot.All.flatten(1)
+			r.EscalationPolicy = "oncall"
+			r.Deliveries = map[string]models.ChannelDelivery{"desktop": {Status: models.DeliveryAwaiting, DeliveryID: "d1"}}
+			if tt.notified > 0 {
+				first := time.Now().Add(-tt.notified)
+				r.FirstNotifiedAt = &first
+			}
+			if tt.deferred {
+				until := time.Now().Add(time.Hour)
+				r.Status = models.StatusDeferred
+				r.DeferredUntil = &until
+			}
+			s.Snapshot.All[1] = map[int]models.Reminder{index: r}
+			s.Snapshot.UnCompleted[1] = map[int]models.Reminder{index: r}
+
+			if got := n.escalationDue(r); got != tt.due {
+				t.Fatalf("escalation due = %v, want %v", got, tt.due)
+			}
+			if tt.due {
+				n.escalateOnTime(r)
+			}
+			_, r = s.Snapshot.All.flatten(1)
+			level := 0
+			if tt.due {
+				level = 1
+			}
+			if r.EscalationLevel != level {
+				t.Errorf("escalation level = %d, want %d", r.EscalationLevel, level)
+			}
+			if got := atomic.LoadInt32(&paged); got != tt.paged {
+				t.Errorf("pager notified %d time(s), want %d", got, tt.paged)
+			}
+			if tt.deferred && r.Status != models.StatusDeferred {
+				t.Errorf("deferred reminder is %s", r.Status)
+			}
+		})
+	}
+}
+
+func TestFailedRemindersAreDeadLettered(t *testing.T) {
+	p := models.Principal{Admin: true}
+	n, s := newTestNotifier(t, http.StatusInternalServerError, ``)
+	var delays []time.Duration
+	for i := 0; i < 3; i++ {
+		_, r := s.Snapshot.All.flatten(1)
+		n.notify(r)
+		_, r = s.Snapshot.All.flatten(1)
+		delays = append(delays, r.Duration)
+	}
+	_, r := s.Snapshot.All.flatten(1)
+	if r.Status != models.StatusFailed || r.Attempts != 3 {
+		t.Fatalf("reminder is %s after %d attempts, want failed after 3", r.Status, r.Attempts)
+	}
+	if delays[0] != time.Minute || delays[1] != time.Minute {
+		t.Errorf("retried after %v, want the initial delay of the policy", delays[:2])
+	}
+	if _, ok := s.Snapshot.UnCompleted[1]; ok {
+		t.Error("failed reminder is still pending")
+	}
+	dead, err := s.DeadLetter(p)
+	if err != nil || len(dead) != 1 || dead[0].ID != 1 {
+		t.Fatalf("dead letter list is %v, %v", dead, err)
+	}
+
+	r, err = s.Requeue(context.Background(), p, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Status != models.StatusPending || r.Attempts != 0 {
+		t.Errorf("requeued reminder is %s after %d attempts", r.Status, r.Attempts)
+	}
+	if dead, _ := s.DeadLetter(p); len(dead) != 0 {
+		t.Errorf("requeued reminder is still dead-lettered")
+	}
+	if _, err := s.Requeue(context.Background(), p, 1); !errors.As(err, &models.DataValidationError{}) {
+		t.Errorf("requeued a pending reminder: %v", err)
+	}
+}
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"app-pointment/server/models"
+)
+
+type BatchOperation struct {
+	Op     string
+	ID     int
+	Create ReminderCreateBody
+	Edit   ReminderEditBody
+}
+
+type staged struct {
+	index    int
+	reminder models.Reminder
+}
+
+// Batch validates every operation before applying any of them. In atomic mode
+// a single invalid operation skips the whole batch, in best effort mode only
+// the invalid operations are skipped.
+func (s Reminders) Batch(ctx context.Context, p models.Principal, mode string, ops []BatchOperation) (models.BatchResponse, error) {
+	if mode == "" {
+		mode = models.BatchAtomic
+	}
+	if mode != models.BatchAtomic && mode != models.BatchBestEffort {
+		err := models.FormatValidationError{
+			Message: fmt.Sprintf("unknown batch mode %q, expected one of: %s", mode, strings.Join(models.BatchModes, ", ")),
+		}
+		return models.BatchResponse{}, err
+	}
+	if len(ops) == 0 {
+		return models.BatchResponse{}, models.FormatValidationError{Message: "batch must contain at least 1 operation"}
+	}
+	if len(ops) > models.MaxBatchOperations {
+		err := models.FormatValidationError{
+			Message: fmt.Sprintf("batch cannot contain more than %d operations", models.MaxBatchOperations),
+		}
+		return models.BatchResponse{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	res := models.BatchResponse{Mode: mode, Results: make([]models.BatchResult, len(ops))}
+	changes := make([]staged, len(ops))
+	seen := map[int]int{}
+	for i, op := range ops {
+		result := models.BatchResult{Index: i, Op: op.Op, ID: op.ID}
+		var err error
+		if prev, ok := seen[op.ID]; ok && op.Op != models.BatchCreate {
+			err = models.DataValidationError{
+				Message: fmt.Sprintf("reminder with id %d is already changed by operation %d", op.ID, prev),
+			}
+		} else {
+			changes[i], err = s.stage(p, op)
+		}
+		if op.Op != models.BatchCreate {
+			seen[op.ID] = i
+		}
+		if err != nil {
+			result.Status = models.BatchStatusFailed
+			result.Err = err
+			res.Failed++
+		}
+		res.Results[i] = result
+	}
+	if err := expired(ctx); err != nil {
+		return models.BatchResponse{}, err
+	}
+
+	for i, op := range ops {
+		result := &res.Results[i]
+		if result.Err != nil {
+			continue
+		}
+		if mode == models.BatchAtomic && res.Failed > 0 {
+			result.Status = models.BatchStatusSkipped
+			continue
+		}
+		change := changes[i]
+		switch op.Op {
+		case models.BatchCreate:
+			change.reminder = s.add(change.reminder)
+			result.ID = change.reminder.ID
+		case models.BatchEdit:
+			s.update(change.index, change.reminder)
+		case models.BatchDelete:
+			s.remove(change.reminder)
+		}
+		reminder := change.reminder
+		result.Reminder = &reminder
+		result.Status = models.BatchStatusOK
+		res.Succeeded++
+	}
+	return res, nil
+}
+
+func (s Reminders) stage(p models.Principal, op BatchOperation) (staged, error) {
+	var change staged
+	var err error
+	switch op.Op {
+	case models.BatchCreate:
+		change.reminder, err = s.newReminder(op.Create)
+	case models.BatchEdit:
+		body := op.Edit
+		body.Principal = p
+		body.ID = op.ID
+		change.index, change.reminder, err = s.edited(body)
+	case models.BatchDelete:
+		change.index, change.reminder, err = s.find(p, op.ID)
+	default:
+		err = models.FormatValidationError{
+			Message: fmt.Sprintf("unknown operation %q, expected one of: %s", op.Op, strings.Join(models.BatchOps, ", ")),
+		}
+	}
+	return change, err
+}
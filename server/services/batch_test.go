@@ -0,0 +1,92 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"app-pointment/server/models"
+)
+
+func TestBatch(t *testing.T) {
+	p := models.Principal{Admin: true}
+	create := BatchOperation{Op: models.BatchCreate, Create: ReminderCreateBody{Title: "new", Message: "m", Duration: time.Hour}}
+	invalid := BatchOperation{Op: models.BatchCreate, Create: ReminderCreateBody{Title: "new", Message: "m"}}
+	edit := BatchOperation{Op: models.BatchEdit, ID: 1, Edit: ReminderEditBody{Title: "renamed"}}
+	remove := BatchOperation{Op: models.BatchDelete, ID: 2}
+	missing := BatchOperation{Op: models.BatchDelete, ID: 9}
+	tests := []struct {
+		name      string
+		mode      string
+		ops       []BatchOperation
+		statuses  string
+		succeeded int
+		titles    string
+	}{
+		{"atomic", models.BatchAtomic, []BatchOperation{create, edit, remove}, "ok,ok,ok", 3, "renamed,new"},
+		{"default mode is atomic", "", []BatchOperation{edit, missing}, "skipped,failed", 0, "a,b"},
+		{"atomic with an invalid operation", models.BatchAtomic, []BatchOperation{create, edit, invalid}, "skipped,skipped,failed", 0, "a,b"},
+		{"best effort", models.BatchBestEffort, []BatchOperation{create, missing, remove}, "ok,failed,ok", 2, "a,new"},
+		{"same reminder twice", models.BatchBestEffort, []BatchOperation{edit, {Op: models.BatchDelete, ID: 1}}, "ok,failed", 1, "renamed,b"},
+		{"unknown operation", models.BatchBestEffort, []BatchOperation{{Op: "upsert", ID: 1}, remove}, "failed,ok", 1, "a"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := newTestReminders(t)
+			createReminders(t, s, "a", "b")
+			res, err := s.Batch(context.Background(), p, tt.mode, tt.ops)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var statuses []string
+			for i, r := range res.Results {
+				if r.Index != i {
+					t.Errorf("result %d has index %d", i, r.Index)
+				}
+				statuses = append(statuses, r.Status)
+			}
+			if got := strings.Join(statuses, ","); got != tt.statuses {
+				t.Errorf("statuses = %s, want %s", got, tt.statuses)
+			}
+			if res.Succeeded != tt.succeeded || res.Failed+res.Succeeded > len(tt.ops) {
+				t.Errorf("%d succeeded and %d failed, want %d succeeded", res.Succeeded, res.Failed, tt.succeeded)
+			}
+			all, err := s.ListAll(p, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var titles []string
+			for _, r := range all {
+				titles = append(titles, r.Title)
+			}
+			if got := strings.Join(titles, ","); got != tt.titles {
+				t.Errorf("reminders are %s, want %s", got, tt.titles)
+			}
+		})
+	}
+}
+
+func TestBatchRejected(t *testing.T) {
+	p := models.Principal{Admin: true}
+	tooMany := make([]BatchOperation, models.MaxBatchOperations+1)
+	tests := []struct {
+		name string
+		mode string
+		ops  []BatchOperation
+	}{
+		{"unknown mode", "eventually", []BatchOperation{{Op: models.BatchDelete, ID: 1}}},
+		{"no operations", models.BatchAtomic, nil},
+		{"too many operations", models.BatchAtomic, tooMany},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := newTestReminders(t)
+			createReminders(t, s, "a")
+			if _, err := s.Batch(context.Background(), p, tt.mode, tt.ops); !errors.As(err, &models.FormatValidationError{}) {
+				t.Errorf("got error %v, want a format validation error", err)
+			}
+		})
+	}
+}
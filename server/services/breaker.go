@@ -0,0 +1,166 @@
+package services
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"app-pointment/server/models"
+)
+
+const (
+	BreakerClosed   = "closed"
+	BreakerHalfOpen = "half-open"
+	BreakerOpen     = "open"
+)
+
+var breakerStateValues = map[string]float64{
+	BreakerClosed:   0,
+	BreakerHalfOpen: 1,
+	BreakerOpen:     2,
+}
+
+type BreakerConfig struct {
+	Threshold int
+	Cooldown  time.Duration
+}
+
+type Breaker struct {
+	mu       sync.Mutex
+	channel  string
+	target   string
+	cfg      BreakerConfig
+	state    string
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+func NewBreaker(channel, target string, cfg BreakerConfig) *Breaker {
+	b := &Breaker{
+		channel: channel,
+		target:  target,
+		cfg:     cfg,
+		state:   BreakerClosed,
+	}
+	b.report()
+	return b
+}
+
+func (b *Breaker) Allow() error {
+	if b.cfg.Threshold <= 0 {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case BreakerOpen:
+		if time.Since(b.openedAt) < b.cfg.Cooldown {
+			break
+		}
+		b.transition(BreakerHalfOpen)
+		b.probing = true
+		return nil
+	case BreakerHalfOpen:
+		if !b.probing {
+			b.probing = true
+			return nil
+		}
+	default:
+		return nil
+	}
+	breakerShortCircuits.With(b.channel).Inc()
+	return models.CircuitOpenError{
+		Message:    fmt.Sprintf("circuit breaker for %s is %s, retry after %v", b.target, b.state, b.retryIn()),
+		RetryAfter: b.retryIn(),
+	}
+}
+
+func (b *Breaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures = 0
+	b.probing = false
+	if b.state != BreakerClosed {
+		b.transition(BreakerClosed)
+	}
+}
+
+func (b *Breaker) Failure() {
+	if b.cfg.Threshold <= 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures++
+	b.probing = false
+	if b.state == BreakerHalfOpen || b.failures >= b.cfg.Threshold {
+		b.openedAt = time.Now()
+		if b.state != BreakerOpen {
+			b.transition(BreakerOpen)
+		}
+	}
+}
+
+func (b *Breaker) State() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+func (b *Breaker) Details() map[string]interface{} {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	details := map[string]interface{}{
+		"state":    b.state,
+		"failures": b.failures,
+	}
+	if b.state == BreakerOpen {
+		details["retry_in"] = b.retryIn().String()
+	}
+	return details
+}
+
+func (b *Breaker) retryIn() time.Duration {
+	d := b.cfg.Cooldown - time.Since(b.openedAt)
+	if d < 0 {
+		return 0
+	}
+	return d.Round(time.Second)
+}
+
+func (b *Breaker) transition(state string) {
+	b.state = state
+	breakerTransitions.With(b.channel, state).Inc()
+	b.report()
+}
+
+func (b *Breaker) report() {
+	breakerState.With(b.channel, b.target).Set(breakerStateValues[b.state])
+}
+
+type breakers struct {
+	mu       sync.Mutex
+	channel  string
+	cfg      BreakerConfig
+	breakers map[string]*Breaker
+}
+
+func newBreakers(channel string, cfg BreakerConfig) *breakers {
+	return &breakers{
+		channel:  channel,
+		cfg:      cfg,
+		breakers: map[string]*Breaker{},
+	}
+}
+
+func (b *breakers) get(target string) *Breaker {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	breaker, ok := b.breakers[target]
+	if !ok {
+		breaker = NewBreaker(b.channel, target, b.cfg)
+		b.breakers[target] = breaker
+	}
+	return breaker
+}
@@ -0,0 +1,98 @@
+package services
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"app-pointment/server/models"
+)
+
+func TestBreaker(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      BreakerConfig
+		steps    []string
+		state    string
+		rejected bool
+	}{
+		{"closed below the threshold", BreakerConfig{Threshold: 2, Cooldown: time.Hour}, []string{"fail"}, BreakerClosed, false},
+		{"opens at the threshold", BreakerConfig{Threshold: 2, Cooldown: time.Hour}, []string{"fail", "fail"}, BreakerOpen, true},
+		{"success resets failures", BreakerConfig{Threshold: 2, Cooldown: time.Hour}, []string{"fail", "ok", "fail"}, BreakerClosed, false},
+		{"probes after the cooldown", BreakerConfig{Threshold: 2}, []string{"fail", "fail", "allow"}, BreakerHalfOpen, true},
+		{"probe success closes", BreakerConfig{Threshold: 2}, []string{"fail", "fail", "allow", "ok"}, BreakerClosed, false},
+		{"disabled", BreakerConfig{}, []string{"fail", "fail", "fail"}, BreakerClosed, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewBreaker("desktop", "http://notifier", tt.cfg)
+			for _, step := range tt.steps {
+				switch step {
+				case "fail":
+					b.Failure()
+				case "ok":
+					b.Success()
+				case "allow":
+					if err := b.Allow(); err != nil {
+						t.Fatalf("probe was rejected: %v", err)
+					}
+				}
+			}
+			if got := b.State(); got != tt.state {
+				t.Errorf("state = %s, want %s", got, tt.state)
+			}
+			err := b.Allow()
+			if rejected := errors.As(err, &models.CircuitOpenError{}); rejected != tt.rejected {
+				t.Errorf("rejected = %v, want %v (%v)", rejected, tt.rejected, err)
+			}
+		})
+	}
+}
+
+func TestBreakerProbeFailureReopens(t *testing.T) {
+	b := NewBreaker("desktop", "http://notifier", BreakerConfig{Threshold: 2})
+	b.Failure()
+	b.Failure()
+	if err := b.Allow(); err != nil {
+		t.Fatalf("probe was rejected: %v", err)
+	}
+	b.Failure()
+	if got := b.State(); got != BreakerOpen {
+		t.Errorf("state = %s after a failed probe, want %s", got, BreakerOpen)
+	}
+}
+
+func TestOpenBreakerKeepsAttempts(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	t.Cleanup(srv.Close)
+	channels := NewChannels(models.RetryPolicy{MaxAttempts: 3, InitialDelay: time.Minute}, "desktop")
+	opts := ChannelOptions{Breaker: BreakerConfig{Threshold: 1, Cooldown: time.Hour}}
+	if err := channels.Register(NewHTTPClient("desktop", srv.URL, opts)); err != nil {
+		t.Fatal(err)
+	}
+	s, _ := newTestReminders(t)
+	createReminders(t, s, "a")
+	n := NewNotifier(channels, s)
+
+	for i := 0; i < 3; i++ {
+		_, r := s.Snapshot.All.flatten(1)
+		n.notify(r)
+	}
+	_, r := s.Snapshot.All.flatten(1)
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Errorf("notifier called %d time(s) through an open breaker, want 1", got)
+	}
+	if r.Status != models.StatusPending || r.Attempts != 1 {
+		t.Errorf("reminder is %s after %d attempts, want pending after 1", r.Status, r.Attempts)
+	}
+	if got := r.Deliveries["desktop"].Status; got != models.DeliveryCircuitOpen {
+		t.Errorf("delivery status = %s, want %s", got, models.DeliveryCircuitOpen)
+	}
+}
@@ -0,0 +1,257 @@
+package services
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"app-pointment/server/models"
+)
+
+const DesktopChannel = "desktop"
+
+type ChannelOptions struct {
+	Users       NotifierResolver
+	Breaker     BreakerConfig
+	CallbackURI string
+}
+
+type Channel interface {
+	Name() string
+	Notify(reminder models.Reminder) (NotificationResponse, error)
+}
+
+type Channels struct {
+	mu       sync.RWMutex
+	channels map[string]Channel
+	policies map[string]models.RetryPolicy
+	policy   models.RetryPolicy
+	defaults []string
+
+	escalations map[string]models.EscalationPolicy
+}
+
+func NewChannels(policy models.RetryPolicy, defaults ...string) *Channels {
+	return &Channels{
+		channels: map[string]Channel{},
+		policies: map[string]models.RetryPolicy{},
+		policy:   policy,
+		defaults: defaults,
+
+		escalations: map[string]models.EscalationPolicy{},
+	}
+}
+
+func (c *Channels) SetEscalationPolicy(policy models.EscalationPolicy) error {
+	for _, step := range policy.Steps {
+		if err := c.Validate(step.Channels); err != nil {
+			return fmt.Errorf("escalation policy %s: %v", policy.Name, err)
+		}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.escalations[policy.Name] = policy
+	return nil
+}
+
+func (c *Channels) EscalationPolicy(name string) (models.EscalationPolicy, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	policy, ok := c.escalations[name]
+	return policy, ok
+}
+
+func (c *Channels) ValidateEscalation(name string) error {
+	if _, ok := c.EscalationPolicy(name); name != "" && !ok {
+		c.mu.RLock()
+		defer c.mu.RUnlock()
+		names := make([]string, 0, len(c.escalations))
+		for n := range c.escalations {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return models.DataValidationError{
+			Message: fmt.Sprintf("unknown escalation policy %q, available: %v", name, names),
+		}
+	}
+	return nil
+}
+
+func (c *Channels) SetRetryPolicy(name string, policy models.RetryPolicy) error {
+	if !c.Has(name) {
+		return fmt.Errorf("cannot set retry policy of unknown channel %q", name)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.policies[name] = policy
+	return nil
+}
+
+// RetryPolicy resolves the policy for a failed delivery: the reminder's own
+// policy wins, then the policy of the first failed channel, then the default.
+func (c *Channels) RetryPolicy(reminder models.Reminder, failed []string) models.RetryPolicy {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	policy := c.policy
+	for _, name := range failed {
+		if p, ok := c.policies[name]; ok {
+			policy = p.Merge(c.policy)
+			break
+		}
+	}
+	if reminder.RetryPolicy != nil {
+		policy = reminder.RetryPolicy.Merge(policy)
+	}
+	return policy
+}
+
+func (c *Channels) Register(ch Channel) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.channels[ch.Name()]; ok {
+		return fmt.Errorf("channel %q is already registered", ch.Name())
+	}
+	c.channels[ch.Name()] = ch
+	return nil
+}
+
+func (c *Channels) Get(name string) (Channel, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	ch, ok := c.channels[name]
+	return ch, ok
+}
+
+func (c *Channels) Has(name string) bool {
+	_, ok := c.Get(name)
+	return ok
+}
+
+func (c *Channels) Names() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	names := make([]string, 0, len(c.channels))
+	for name := range c.channels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (c *Channels) Defaults() []string {
+	return c.defaults
+}
+
+// Targets returns the reminder channels followed by the channels added by
+// the escalation steps it went through.
+func (c *Channels) Targets(reminder models.Reminder) []string {
+	targets := c.defaults
+	if len(reminder.Channels) > 0 {
+		targets = reminder.Channels
+	}
+	if reminder.EscalationLevel == 0 {
+		return targets
+	}
+	policy, ok := c.EscalationPolicy(reminder.EscalationPolicy)
+	if !ok {
+		return targets
+	}
+	res := append([]string(nil), targets...)
+	for i := 0; i < reminder.EscalationLevel && i < len(policy.Steps); i++ {
+		for _, name := range policy.Steps[i].Channels {
+			if !containsString(res, name) {
+				res = append(res, name)
+			}
+		}
+	}
+	return res
+}
+
+func containsString(list []string, v string) bool {
+	for _, item := range list {
+		if item == v {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Channels) Delivered(reminder models.Reminder) bool {
+	for _, name := range c.Targets(reminder) {
+		if reminder.Deliveries[name].Status != models.DeliveryDelivered {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *Channels) Validate(names []string) error {
+	var unknown []string
+	for _, name := range names {
+		if !c.Has(name) {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		return models.DataValidationError{
+			Message: fmt.Sprintf("unknown channels %v, available: %v", unknown, c.Names()),
+		}
+	}
+	return nil
+}
+
+func (c *Channels) Checkers() []HealthChecker {
+	var res []HealthChecker
+	for _, name := range c.Names() {
+		ch, _ := c.Get(name)
+		if checker, ok := ch.(HealthChecker); ok {
+			res = append(res, checker)
+		}
+	}
+	return res
+}
+
+// ParseChannel builds a channel from a "name=kind:target" spec, for example
+// "ops=webhook:https://example.com/hook" or "log=file:/var/log/reminders.log".
+func ParseChannel(spec string, opts ChannelOptions) (Channel, error) {
+	parts := strings.SplitN(spec, "=", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return nil, fmt.Errorf("invalid channel %q, expected name=kind:target", spec)
+	}
+	name, def := parts[0], parts[1]
+	parts = strings.SplitN(def, ":", 2)
+	kind, target := parts[0], ""
+	if len(parts) == 2 {
+		target = parts[1]
+	}
+	switch kind {
+	case "desktop":
+		if target == "" {
+			return nil, fmt.Errorf("desktop channel %q requires a notifier URI", name)
+		}
+		return NewHTTPClient(name, target, opts), nil
+	case "webhook":
+		if _, err := url.ParseRequestURI(target); err != nil {
+			return nil, models.WrapError(fmt.Sprintf("invalid webhook channel %q", name), err)
+		}
+		return NewWebhookChannel(name, target), nil
+	case "smtp":
+		u, err := url.Parse(def)
+		if err != nil {
+			return nil, models.WrapError(fmt.Sprintf("invalid smtp channel %q", name), err)
+		}
+		return NewSMTPChannel(name, u)
+	case "file":
+		return NewFileChannel(name, target), nil
+	case "stdout":
+		return NewFileChannel(name, "-"), nil
+	case "unix":
+		if target == "" {
+			return nil, fmt.Errorf("unix channel %q requires a socket path", name)
+		}
+		return NewUnixChannel(name, target), nil
+	}
+	return nil, fmt.Errorf("unknown channel kind %q in %q", kind, spec)
+}
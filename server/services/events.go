@@ -0,0 +1,47 @@
+package services
+
+import (
+	"sync"
+	"time"
+
+	"app-pointment/server/models"
+)
+
+type Events struct {
+	mu          sync.Mutex
+	nextID      int64
+	subscribers []func(models.Event)
+}
+
+func NewEvents() *Events {
+	return &Events{}
+}
+
+// Subscribe registers fn for every published event. Subscribers are called
+// synchronously and must hand slow work off to their own goroutines.
+func (e *Events) Subscribe(fn func(models.Event)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.subscribers = append(e.subscribers, fn)
+}
+
+func (e *Events) Publish(eventType string, reminder models.Reminder) {
+	if e == nil {
+		return
+	}
+	e.mu.Lock()
+	e.nextID++
+	event := models.Event{
+		ID:       e.nextID,
+		Type:     eventType,
+		Reminder: reminder,
+		At:       time.Now(),
+	}
+	subscribers := make([]func(models.Event), len(e.subscribers))
+	copy(subscribers, e.subscribers)
+	e.mu.Unlock()
+	events.With(eventType).Inc()
+	for _, fn := range subscribers {
+		fn(event)
+	}
+}
@@ -0,0 +1,42 @@
+package services
+
+import (
+	"time"
+
+	"app-pointment/server/models"
+)
+
+type HealthChecker interface {
+	CheckHealth() models.ComponentHealth
+}
+
+type Health struct {
+	checkers []HealthChecker
+}
+
+func NewHealth(checkers ...HealthChecker) *Health {
+	return &Health{checkers: checkers}
+}
+
+func (h *Health) Live() models.Health {
+	return models.Health{
+		Status:    models.HealthUp,
+		CheckedAt: time.Now(),
+	}
+}
+
+func (h *Health) Ready() models.Health {
+	res := models.Health{
+		Status:     models.HealthUp,
+		CheckedAt:  time.Now(),
+		Components: make([]models.ComponentHealth, 0, len(h.checkers)),
+	}
+	for _, checker := range h.checkers {
+		c := checker.CheckHealth()
+		if c.Status != models.HealthUp {
+			res.Status = models.HealthDown
+		}
+		res.Components = append(res.Components, c)
+	}
+	return res
+}
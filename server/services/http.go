@@ -3,6 +3,7 @@ package services
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"github.com/pkg/errors"
 	"io"
 	"net/http"
@@ -11,64 +12,156 @@ import (
 	"app-pointment/server/models"
 )
 
+type NotifierResolver interface {
+	NotifierURI(user string) (string, bool)
+}
+
 type HTTPClient struct {
-	notifierURI string
-	client      *http.Client
+	name         string
+	notifierURI  string
+	users        NotifierResolver
+	callbackURI  string
+	breakers     *breakers
+	client       *http.Client
+	healthClient *http.Client
 }
 
-func NewHTTPClient(uri string) HTTPClient {
+func NewHTTPClient(name, uri string, opts ChannelOptions) HTTPClient {
 	return HTTPClient{
+		name:        name,
 		notifierURI: uri,
+		users:       opts.Users,
+		callbackURI: opts.CallbackURI,
+		breakers:    newBreakers(name, opts.Breaker),
 		client: &http.Client{
 			Timeout: 20 * time.Second,
 		},
+		healthClient: &http.Client{
+			Timeout: 2 * time.Second,
+		},
+	}
+}
+
+func (c HTTPClient) Name() string {
+	return c.name
+}
+
+func (c HTTPClient) CheckHealth() models.ComponentHealth {
+	res := models.ComponentHealth{
+		Name:    "channel:" + c.name,
+		Status:  models.HealthUp,
+		Details: map[string]interface{}{"uri": c.notifierURI},
+	}
+	res.Details["breaker"] = c.breakers.get(c.notifierURI).Details()
+	r, err := c.healthClient.Get(c.notifierURI + "/health")
+	if err != nil {
+		res.Status = models.HealthDown
+		res.Message = fmt.Sprintf("notifier is unreachable: %v", err)
+		return res
+	}
+	defer r.Body.Close()
+	if r.StatusCode != http.StatusOK {
+		res.Status = models.HealthDown
+		res.Message = fmt.Sprintf("notifier responded with status %d", r.StatusCode)
 	}
+	return res
 }
 
 type NotificationResponse struct {
-	completed bool
-	duration  time.Duration
+	completed  bool
+	unanswered bool
+	duration   time.Duration
+	deliveryID string
+
+	activationType  string
+	activationValue string
+}
+
+func (c HTTPClient) uriFor(owner string) string {
+	if c.users == nil || owner == "" {
+		return c.notifierURI
+	}
+	if uri, ok := c.users.NotifierURI(owner); ok {
+		return uri
+	}
+	return c.notifierURI
 }
 
 func (c HTTPClient) Notify(reminder models.Reminder) (NotificationResponse, error) {
 	var notifierResponse struct {
 		ActivationType  string `json:"activationType"`
 		ActivationValue string `json:"activationValue"`
+		DeliveryID      string `json:"deliveryId"`
+	}
+	payload := struct {
+		models.Reminder
+		Callback string `json:"callback,omitempty"`
+	}{Reminder: reminder}
+	if c.callbackURI != "" {
+		payload.Callback = fmt.Sprintf("%s/reminders/%d", c.callbackURI, reminder.ID)
 	}
-	bs, err := json.Marshal(reminder)
+	bs, err := json.Marshal(payload)
 	if err != nil {
 		e := models.WrapError("could not marshal json", err)
 		return NotificationResponse{}, e
 	}
 
+	uri := c.uriFor(reminder.Owner)
+	breaker := c.breakers.get(uri)
+	if err := breaker.Allow(); err != nil {
+		return NotificationResponse{}, err
+	}
 	res, err := c.client.Post(
-		c.notifierURI+"/notify",
+		uri+"/notify",
 		"application/json",
 		bytes.NewReader(bs),
 	)
 	if err != nil {
+		breaker.Failure()
 		e := models.WrapError("notifier service is not available", err)
 		return NotificationResponse{}, e
 	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		breaker.Failure()
+		return NotificationResponse{}, fmt.Errorf("notifier responded with status %d", res.StatusCode)
+	}
 	err = json.NewDecoder(res.Body).Decode(&notifierResponse)
 	if err != nil && err != io.EOF {
+		breaker.Failure()
 		e := models.WrapError("could not decode notifier response", err)
 		return NotificationResponse{}, e
 	}
+	breaker.Success()
+
+	if res.StatusCode == http.StatusAccepted {
+		if notifierResponse.DeliveryID == "" {
+			return NotificationResponse{}, errors.New("notifier accepted the reminder without a delivery id")
+		}
+		return NotificationResponse{deliveryID: notifierResponse.DeliveryID}, nil
+	}
 
 	t := notifierResponse.ActivationType
 	v := notifierResponse.ActivationValue
+	activation := NotificationResponse{activationType: t, activationValue: v}
 	if t == "closed" {
-		return NotificationResponse{completed: true}, nil
+		activation.completed = true
+		return activation, nil
+	}
+	if t == "timeout" || v == "" {
+		// the notification was shown but nobody answered it
+		activation.unanswered = true
+		return activation, nil
 	}
 
 	d, err := time.ParseDuration(v)
 	if err != nil && d != 0 {
 		e := models.WrapError("could not parse notifier duration", err)
-		return NotificationResponse{}, e
+		return activation, e
 	}
 	if d == 0 {
-		return NotificationResponse{}, errors.New("notification duration must be > 0s")
+		return activation, errors.New("notification duration must be > 0s")
 	}
-	return NotificationResponse{duration: d}, nil
+	activation.duration = d
+	return activation, nil
 }
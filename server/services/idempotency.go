@@ -0,0 +1,83 @@
+package services
+
+import (
+	"sync"
+	"time"
+
+	"app-pointment/server/models"
+)
+
+const sweepPeriod = time.Minute
+
+// Idempotency keeps the responses of requests sent with an idempotency key
+// for ttl, so that retried requests are answered without being executed again.
+type Idempotency struct {
+	ttl time.Duration
+
+	mu        sync.Mutex
+	responses map[string]*models.IdempotentResponse
+	lastSweep time.Time
+}
+
+func NewIdempotency(ttl time.Duration) *Idempotency {
+	return &Idempotency{
+		ttl:       ttl,
+		responses: map[string]*models.IdempotentResponse{},
+		lastSweep: time.Now(),
+	}
+}
+
+// Begin reserves the key for a request. It returns the stored response when
+// the request was already completed.
+func (s *Idempotency) Begin(key, fingerprint string) (models.IdempotentResponse, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sweep()
+	res, ok := s.responses[key]
+	if !ok || time.Now().After(res.ExpiresAt) {
+		s.responses[key] = &models.IdempotentResponse{
+			Fingerprint: fingerprint,
+			ExpiresAt:   time.Now().Add(s.ttl),
+		}
+		return models.IdempotentResponse{}, false, nil
+	}
+	if res.Fingerprint != fingerprint {
+		return models.IdempotentResponse{}, false, models.DataValidationError{
+			Message: "idempotency key was already used with a different request",
+		}
+	}
+	if res.StatusCode == 0 {
+		return models.IdempotentResponse{}, false, models.ConflictError{
+			Message: "a request with this idempotency key is still in progress",
+		}
+	}
+	idempotentReplays.Inc()
+	return *res, true, nil
+}
+
+func (s *Idempotency) Finish(key string, res models.IdempotentResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	res.ExpiresAt = time.Now().Add(s.ttl)
+	s.responses[key] = &res
+}
+
+// Abort releases the key of a request which failed, it can be retried.
+func (s *Idempotency) Abort(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.responses, key)
+}
+
+func (s *Idempotency) sweep() {
+	now := time.Now()
+	if now.Sub(s.lastSweep) < sweepPeriod {
+		return
+	}
+	s.lastSweep = now
+	for key, res := range s.responses {
+		if now.After(res.ExpiresAt) {
+			delete(s.responses, key)
+		}
+	}
+}
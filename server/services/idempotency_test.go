@@ -0,0 +1,62 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"app-pointment/server/models"
+)
+
+func TestIdempotencyStore(t *testing.T) {
+	done := models.IdempotentResponse{Fingerprint: "f1", StatusCode: 201, Body: []byte("{}")}
+	tests := []struct {
+		name        string
+		ttl         time.Duration
+		prepare     func(s *Idempotency)
+		fingerprint string
+		replayed    bool
+		conflict    bool
+		invalid     bool
+	}{
+		{"new key", time.Hour, func(s *Idempotency) {}, "f1", false, false, false},
+		{"finished", time.Hour, func(s *Idempotency) {
+			s.Begin("k", "f1")
+			s.Finish("k", done)
+		}, "f1", true, false, false},
+		{"in progress", time.Hour, func(s *Idempotency) {
+			s.Begin("k", "f1")
+		}, "f1", false, true, false},
+		{"other request", time.Hour, func(s *Idempotency) {
+			s.Begin("k", "f1")
+			s.Finish("k", done)
+		}, "f2", false, false, true},
+		{"aborted", time.Hour, func(s *Idempotency) {
+			s.Begin("k", "f1")
+			s.Abort("k")
+		}, "f1", false, false, false},
+		{"expired", -time.Second, func(s *Idempotency) {
+			s.Begin("k", "f1")
+			s.Finish("k", done)
+		}, "f1", false, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewIdempotency(tt.ttl)
+			tt.prepare(s)
+			res, replayed, err := s.Begin("k", tt.fingerprint)
+			if replayed != tt.replayed {
+				t.Errorf("replayed = %v, want %v", replayed, tt.replayed)
+			}
+			if tt.replayed && (res.StatusCode != done.StatusCode || string(res.Body) != string(done.Body)) {
+				t.Errorf("replayed %d %s, want %d %s", res.StatusCode, res.Body, done.StatusCode, done.Body)
+			}
+			if conflict := errors.As(err, &models.ConflictError{}); conflict != tt.conflict {
+				t.Errorf("conflict = %v, want %v (%v)", conflict, tt.conflict, err)
+			}
+			if invalid := errors.As(err, &models.DataValidationError{}); invalid != tt.invalid {
+				t.Errorf("invalid = %v, want %v (%v)", invalid, tt.invalid, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"app-pointment/server/metrics"
+)
+
+var (
+	pendingReminders = metrics.NewGauge(
+		"app_pointment_reminders_pending",
+		"Number of reminders waiting to be delivered.",
+	)
+	retryingReminders = metrics.NewGauge(
+		"app_pointment_reminders_retrying",
+		"Number of pending reminders whose last delivery attempt failed.",
+	)
+	notifications = metrics.NewCounterVec(
+		"app_pointment_notifications_total",
+		"Notification attempts by channel and result.",
+		"channel", "result",
+	)
+	notificationRetries = metrics.NewCounter(
+		"app_pointment_notification_retries_total",
+		"Total number of notifications rescheduled after a failed delivery.",
+	)
+	notificationSnoozes = metrics.NewCounter(
+		"app_pointment_notification_snoozes_total",
+		"Total number of notifications snoozed from the notifier.",
+	)
+	notificationsUnanswered = metrics.NewCounter(
+		"app_pointment_notifications_unanswered_total",
+		"Total number of notifications shown again because nobody answered them.",
+	)
+	deadLettered = metrics.NewCounter(
+		"app_pointment_notifications_dead_lettered_total",
+		"Total number of reminders moved to the dead-letter list after exhausting retries.",
+	)
+	breakerState = metrics.NewGaugeVec(
+		"app_pointment_circuit_breaker_state",
+		"Circuit breaker state per notifier target: 0 closed, 1 half-open, 2 open.",
+		"channel", "target",
+	)
+	breakerTransitions = metrics.NewCounterVec(
+		"app_pointment_circuit_breaker_transitions_total",
+		"Circuit breaker state transitions by channel and new state.",
+		"channel", "state",
+	)
+	breakerShortCircuits = metrics.NewCounterVec(
+		"app_pointment_circuit_breaker_short_circuits_total",
+		"Deliveries rejected while the circuit breaker was open.",
+		"channel",
+	)
+	escalations = metrics.NewCounterVec(
+		"app_pointment_escalations_total",
+		"Escalation steps taken by policy.",
+		"policy",
+	)
+	idempotentReplays = metrics.NewCounter(
+		"app_pointment_idempotent_replays_total",
+		"Total number of responses replayed for repeated idempotency keys.",
+	)
+	quietDeferrals = metrics.NewCounter(
+		"app_pointment_quiet_hours_deferrals_total",
+		"Total number of notifications deferred by quiet hours.",
+	)
+	saveDuration = metrics.NewHistogram(
+		"app_pointment_save_duration_seconds",
+		"Time spent persisting the reminders snapshot.",
+		metrics.DefaultBuckets,
+	)
+	saveErrors = metrics.NewCounter(
+		"app_pointment_save_errors_total",
+		"Total number of failed snapshot saves.",
+	)
+	events = metrics.NewCounterVec(
+		"app_pointment_events_total",
+		"Reminder lifecycle events by type.",
+		"type",
+	)
+	webhookDeliveries = metrics.NewCounterVec(
+		"app_pointment_webhook_deliveries_total",
+		"Webhook delivery attempts by result.",
+		"result",
+	)
+	streamSubscribers = metrics.NewGauge(
+		"app_pointment_event_stream_subscribers",
+		"Number of clients connected to the event stream.",
+	)
+	webhookDropped = metrics.NewCounter(
+		"app_pointment_webhook_dropped_total",
+		"Webhook deliveries dropped because the queue was full.",
+	)
+)
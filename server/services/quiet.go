@@ -0,0 +1,33 @@
+package services
+
+import "app-pointment/server/models"
+
+type QuietHoursResolver interface {
+	QuietHours(user string) (models.QuietHours, bool)
+}
+
+// QuietHours resolves the quiet hours applying to a reminder owner, a user's
+// own configuration takes precedence over the global one.
+type QuietHours struct {
+	global *models.QuietHours
+	users  QuietHoursResolver
+}
+
+func NewQuietHours(global *models.QuietHours, users QuietHoursResolver) *QuietHours {
+	return &QuietHours{global: global, users: users}
+}
+
+func (q *QuietHours) For(owner string) (models.QuietHours, bool) {
+	if q == nil {
+		return models.QuietHours{}, false
+	}
+	if q.users != nil && owner != "" {
+		if hours, ok := q.users.QuietHours(owner); ok {
+			return hours, true
+		}
+	}
+	if q.global != nil {
+		return *q.global, true
+	}
+	return models.QuietHours{}, false
+}
@@ -0,0 +1,80 @@
+package services
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"app-pointment/server/models"
+)
+
+// countingNotifier closes every notification and counts them.
+func countingNotifier(t *testing.T, calls *int32) string {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(calls, 1)
+		fmt.Fprint(w, `{"activationType":"closed"}`)
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+func TestNotifyDuringQuietHours(t *testing.T) {
+	// a window starting and ending at midnight lasts the whole day
+	always := []models.QuietWindow{{Start: "00:00", End: "00:00"}}
+	tests := []struct {
+		name    string
+		hours   models.QuietHours
+		ignore  bool
+		status  string
+		desktop int32
+		silent  int32
+	}{
+		{"deferred", models.QuietHours{TimeZone: "UTC", Windows: always}, false, models.StatusDeferred, 0, 0},
+		{"delivered silently", models.QuietHours{TimeZone: "UTC", Mode: models.QuietModeSilent, Channel: "silent", Windows: always}, false, models.StatusCompleted, 0, 1},
+		{"ignoring quiet hours", models.QuietHours{TimeZone: "UTC", Windows: always}, true, models.StatusCompleted, 1, 0},
+		{"outside quiet hours", models.QuietHours{TimeZone: "UTC"}, false, models.StatusCompleted, 1, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var desktop, silent int32
+			channels := NewChannels(models.RetryPolicy{}, "desktop")
+			if err := channels.Register(NewHTTPClient("desktop", countingNotifier(t, &desktop), ChannelOptions{})); err != nil {
+				t.Fatal(err)
+			}
+			if err := channels.Register(NewHTTPClient("silent", countingNotifier(t, &silent), ChannelOptions{})); err != nil {
+				t.Fatal(err)
+			}
+			s, _ := newTestReminders(t)
+			createReminders(t, s, "a")
+			index, r := s.Snapshot.All.flatten(1)
+			r.IgnoreQuietHours = tt.ignore
+			s.Snapshot.All[1] = map[int]models.Reminder{index: r}
+			s.Snapshot.UnCompleted[1] = map[int]models.Reminder{index: r}
+			n := NewNotifier(channels, s)
+			n.Quiet = NewQuietHours(&tt.hours, nil)
+			go func() {
+				for range n.completed {
+				}
+			}()
+			t.Cleanup(func() { close(n.completed) })
+
+			n.notify(r)
+			_, r = s.Snapshot.All.flatten(1)
+			if r.Status != tt.status {
+				t.Errorf("reminder is %s, want %s", r.Status, tt.status)
+			}
+			if tt.status == models.StatusDeferred && (r.DeferredUntil == nil || !r.DeferredUntil.After(time.Now())) {
+				t.Errorf("reminder is deferred until %v", r.DeferredUntil)
+			}
+			if got := atomic.LoadInt32(&desktop); got != tt.desktop {
+				t.Errorf("desktop notified %d time(s), want %d", got, tt.desktop)
+			}
+			if got := atomic.LoadInt32(&silent); got != tt.silent {
+				t.Errorf("silent channel notified %d time(s), want %d", got, tt.silent)
+			}
+		})
+	}
+}
@@ -1,16 +1,20 @@
 package services
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"app-pointment/server/models"
 )
 
 const (
-	retryPeriod = time.Minute
+	retryPeriod   = time.Minute
+	minRetryDelay = 2 * tickPeriod
 )
 
 type RemindersMap map[int]map[int]models.Reminder
@@ -25,6 +29,20 @@ func (rMap RemindersMap) flatten(id int) (int, models.Reminder) {
 	return index, reminder
 }
 
+// nextIndex returns an index no reminder uses, deleted reminders leave gaps
+// so the number of reminders may already be taken.
+func (rMap RemindersMap) nextIndex() int {
+	next := 0
+	for _, reminderMap := range rMap {
+		for i := range reminderMap {
+			if i >= next {
+				next = i + 1
+			}
+		}
+	}
+	return next
+}
+
 type ReminderRepository interface {
 	Save([]models.Reminder) (int, error)
 	Filter(filterFn func(reminder models.Reminder) bool) (RemindersMap, error)
@@ -36,14 +54,37 @@ type Snapshot struct {
 	UnCompleted RemindersMap
 }
 
+type channelRegistry interface {
+	Validate(names []string) error
+	ValidateEscalation(name string) error
+	Delivered(reminder models.Reminder) bool
+}
+
+type templateValidator interface {
+	Validate(owner, name string, variables map[string]string) error
+}
+
+// Reminders is shared by the HTTP handlers, the notifier and the saver, mu
+// guards the snapshot. Methods take the lock, the unexported helpers they
+// call expect it to be held.
 type Reminders struct {
-	repo     ReminderRepository
-	Snapshot Snapshot
+	mu        *sync.RWMutex
+	repo      ReminderRepository
+	channels  channelRegistry
+	templates templateValidator
+	events    *Events
+	Snapshot  Snapshot
+
+	DeliveryLog *DeliveryLog
 }
 
-func NewReminders(repo ReminderRepository) *Reminders {
+func NewReminders(repo ReminderRepository, channels channelRegistry, templates templateValidator, events *Events) *Reminders {
 	return &Reminders{
-		repo: repo,
+		mu:        &sync.RWMutex{},
+		repo:      repo,
+		channels:  channels,
+		templates: templates,
+		events:    events,
 		Snapshot: Snapshot{
 			All:         RemindersMap{},
 			UnCompleted: RemindersMap{},
@@ -57,31 +98,76 @@ func (s *Reminders) Populate() error {
 		return models.WrapError("could not get all reminders", err)
 	}
 	unCompleted, err := s.repo.Filter(func(r models.Reminder) bool {
+		switch r.Status {
+		case models.StatusFailed, models.StatusCompleted, models.StatusDismissed:
+			return false
+		}
 		return r.ModifiedAt.Add(r.Duration).UnixNano() > time.Now().UnixNano()
 	})
 	if err != nil {
 		return models.WrapError("could not get uncompleted reminders", err)
 	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	s.Snapshot.All = all
 	s.Snapshot.UnCompleted = unCompleted
 	return nil
 }
 
 type ReminderCreateBody struct {
-	Title    string
-	Message  string
-	Duration time.Duration
+	Owner       string
+	Title       string
+	Message     string
+	Template    string
+	Variables   map[string]string
+	Duration    time.Duration
+	Channels    []string
+	RetryPolicy *models.RetryPolicy
+
+	IgnoreQuietHours bool
+	EscalationPolicy string
 }
 
-func (s Reminders) Create(body ReminderCreateBody) (models.Reminder, error) {
-	nextID := s.repo.NextID()
-	if body.Title == "" {
+func (s Reminders) Create(ctx context.Context, body ReminderCreateBody) (models.Reminder, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	reminder, err := s.newReminder(body)
+	if err != nil {
+		return models.Reminder{}, err
+	}
+	if err := expired(ctx); err != nil {
+		return models.Reminder{}, err
+	}
+	return s.add(reminder), nil
+}
+
+// expired keeps a change out of the snapshot once its request timed out, the
+// client is told the change was not applied.
+func expired(ctx context.Context) error {
+	if ctx.Err() != nil {
+		return models.TimeoutError{Message: "request timed out before the change was applied"}
+	}
+	return nil
+}
+
+func (s Reminders) newReminder(body ReminderCreateBody) (models.Reminder, error) {
+	if body.Template != "" {
+		if err := s.templates.Validate(body.Owner, body.Template, body.Variables); err != nil {
+			return models.Reminder{}, err
+		}
+	} else if len(body.Variables) > 0 {
+		err := models.DataValidationError{
+			Message: "variables require a template",
+		}
+		return models.Reminder{}, err
+	}
+	if body.Title == "" && body.Template == "" {
 		err := models.DataValidationError{
 			Message: "title cannot be empty",
 		}
 		return models.Reminder{}, err
 	}
-	if body.Message == "" {
+	if body.Message == "" && body.Template == "" {
 		err := models.DataValidationError{
 			Message: "body cannot be empty",
 		}
@@ -93,37 +179,84 @@ func (s Reminders) Create(body ReminderCreateBody) (models.Reminder, error) {
 		}
 		return models.Reminder{}, err
 	}
+	if err := s.channels.Validate(body.Channels); err != nil {
+		return models.Reminder{}, err
+	}
+	if err := s.channels.ValidateEscalation(body.EscalationPolicy); err != nil {
+		return models.Reminder{}, err
+	}
+	if body.RetryPolicy != nil {
+		if err := body.RetryPolicy.Validate(); err != nil {
+			return models.Reminder{}, err
+		}
+	}
 	reminder := models.Reminder{
-		ID:         nextID,
-		Title:      body.Title,
-		Message:    body.Message,
-		Duration:   body.Duration,
-		CreatedAt:  time.Now(),
-		ModifiedAt: time.Now(),
-	}
-	index := len(s.Snapshot.All)
+		Owner:       body.Owner,
+		Title:       body.Title,
+		Message:     body.Message,
+		Template:    body.Template,
+		Variables:   body.Variables,
+		Duration:    body.Duration,
+		Status:      models.StatusPending,
+		Channels:    body.Channels,
+		RetryPolicy: body.RetryPolicy,
+		CreatedAt:   time.Now(),
+		ModifiedAt:  time.Now(),
+
+		IgnoreQuietHours: body.IgnoreQuietHours,
+		EscalationPolicy: body.EscalationPolicy,
+	}
+	return reminder, nil
+}
+
+func (s Reminders) add(reminder models.Reminder) models.Reminder {
+	reminder.ID = s.repo.NextID()
+	index := s.Snapshot.All.nextIndex()
 	s.Snapshot.All[reminder.ID] = map[int]models.Reminder{index: reminder}
 	s.Snapshot.UnCompleted[reminder.ID] = map[int]models.Reminder{index: reminder}
-	return reminder, nil
+	s.events.Publish(models.EventReminderCreated, reminder)
+	return reminder
 }
 
 type ReminderEditBody struct {
-	ID       int
-	Title    string
-	Message  string
-	Duration time.Duration
+	Principal   models.Principal
+	ID          int
+	Title       string
+	Message     string
+	Template    string
+	Variables   map[string]string
+	Duration    time.Duration
+	Channels    []string
+	RetryPolicy *models.RetryPolicy
+
+	IgnoreQuietHours *bool
+	EscalationPolicy string
+}
+
+func (s Reminders) Edit(ctx context.Context, reminderBody ReminderEditBody) (models.Reminder, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	index, reminder, err := s.edited(reminderBody)
+	if err != nil {
+		return models.Reminder{}, err
+	}
+	if err := expired(ctx); err != nil {
+		return models.Reminder{}, err
+	}
+	s.update(index, reminder)
+	return reminder, nil
 }
 
-func (s Reminders) Edit(reminderBody ReminderEditBody) (models.Reminder, error) {
+func (s Reminders) edited(reminderBody ReminderEditBody) (int, models.Reminder, error) {
 	_, ok := s.Snapshot.All[reminderBody.ID]
-	if !ok {
+	index, reminder := s.Snapshot.All.flatten(reminderBody.ID)
+	if !ok || !reminderBody.Principal.CanAccess(reminder) {
 		err := models.NotFoundError{
 			Message: fmt.Sprintf("could not find reminder with id: %d", reminderBody.ID),
 		}
-		return models.Reminder{}, err
+		return 0, models.Reminder{}, err
 	}
 	changed := false
-	index, reminder := s.Snapshot.All.flatten(reminderBody.ID)
 	if strings.TrimSpace(reminderBody.Title) != "" {
 		reminder.Title = reminderBody.Title
 		changed = true
@@ -132,36 +265,105 @@ func (s Reminders) Edit(reminderBody ReminderEditBody) (models.Reminder, error)
 		reminder.Message = reminderBody.Message
 		changed = true
 	}
+	if reminderBody.Template != "" || reminderBody.Variables != nil {
+		name, variables := reminder.Template, reminder.Variables
+		if reminderBody.Template != "" {
+			name = reminderBody.Template
+		}
+		if reminderBody.Variables != nil {
+			variables = reminderBody.Variables
+		}
+		if name == "" {
+			return 0, models.Reminder{}, models.DataValidationError{Message: "variables require a template"}
+		}
+		if err := s.templates.Validate(reminder.Owner, name, variables); err != nil {
+			return 0, models.Reminder{}, err
+		}
+		reminder.Template = name
+		reminder.Variables = variables
+		changed = true
+	}
 	if reminderBody.Duration != 0 {
 		reminder.Duration = reminderBody.Duration
 		changed = true
 	}
+	if len(reminderBody.Channels) > 0 {
+		if err := s.channels.Validate(reminderBody.Channels); err != nil {
+			return 0, models.Reminder{}, err
+		}
+		reminder.Channels = reminderBody.Channels
+		changed = true
+	}
+	if reminderBody.RetryPolicy != nil {
+		if err := reminderBody.RetryPolicy.Validate(); err != nil {
+			return 0, models.Reminder{}, err
+		}
+		reminder.RetryPolicy = reminderBody.RetryPolicy
+		changed = true
+	}
+	if reminderBody.IgnoreQuietHours != nil {
+		reminder.IgnoreQuietHours = *reminderBody.IgnoreQuietHours
+		changed = true
+	}
+	if reminderBody.EscalationPolicy != "" {
+		if err := s.channels.ValidateEscalation(reminderBody.EscalationPolicy); err != nil {
+			return 0, models.Reminder{}, err
+		}
+		reminder.EscalationPolicy = reminderBody.EscalationPolicy
+		changed = true
+	}
 	if !changed {
 		err := models.FormatValidationError{
-			Message: "body must contain at least 1 of: 'title', 'message', 'duration'",
+			Message: "body must contain at least 1 of: 'title', 'message', 'template', 'variables', 'duration', 'channels', 'retry_policy', 'ignore_quiet_hours', 'escalation_policy'",
 		}
-		return models.Reminder{}, err
+		return 0, models.Reminder{}, err
 	}
-	reminder.ModifiedAt = time.Now()
+	now := time.Now()
+	rescheduled := reminderBody.Duration != 0 || len(reminderBody.Channels) > 0
+	switch {
+	case reminder.Terminal():
+		// editing a finished reminder keeps it finished, reopen schedules it again
+	case rescheduled:
+		reminder.Status = models.StatusPending
+		reminder.DeferredUntil = nil
+		reminder.Attempts = 0
+		reminder.LastError = ""
+		reminder.Deliveries = nil
+		reminder.ResetEscalation()
+	default:
+		// content changes keep the delivery state and the next notification time
+		next := reminder.ModifiedAt.Add(reminder.Duration).Sub(now)
+		if next < minRetryDelay {
+			next = minRetryDelay
+		}
+		reminder.Duration = next
+	}
+	reminder.ModifiedAt = now
+	return index, reminder, nil
+}
+
+func (s Reminders) update(index int, reminder models.Reminder) {
 	s.Snapshot.All[reminder.ID] = map[int]models.Reminder{index: reminder}
-	if reminder.ModifiedAt.UnixNano() < time.Now().Add(reminderBody.Duration).UnixNano() {
+	if !reminder.Terminal() && reminder.Duration > 0 {
 		s.Snapshot.UnCompleted[reminder.ID] = map[int]models.Reminder{index: reminder}
 	} else {
 		delete(s.Snapshot.UnCompleted, reminder.ID)
 	}
-	return reminder, nil
+	s.events.Publish(models.EventReminderUpdated, reminder)
 }
 
-func (s Reminders) List(ids []int) ([]models.Reminder, error) {
+func (s Reminders) List(p models.Principal, ids []int) ([]models.Reminder, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 	reminders := make([]models.Reminder, 0)
 	var notFound []int
 	for _, id := range ids {
 		_, ok := s.Snapshot.All[id]
-		if !ok {
+		_, reminder := s.Snapshot.All.flatten(id)
+		if !ok || !p.CanAccess(reminder) {
 			notFound = append(notFound, id)
 			continue
 		}
-		_, reminder := s.Snapshot.All.flatten(id)
 		reminders = append(reminders, reminder)
 	}
 	if len(notFound) > 0 {
@@ -173,11 +375,179 @@ func (s Reminders) List(ids []int) ([]models.Reminder, error) {
 	return reminders, nil
 }
 
-func (s Reminders) Delete(ids []int) error {
+// ListAll returns the reminders the principal can access, only the ones with
+// one of the given statuses when any is given.
+func (s Reminders) ListAll(p models.Principal, statuses []string) ([]models.Reminder, error) {
+	wanted := map[string]bool{}
+	for _, status := range statuses {
+		if !models.ValidStatus(status) {
+			err := models.DataValidationError{
+				Message: fmt.Sprintf("invalid status %q, expected one of %v", status, models.Statuses),
+			}
+			return []models.Reminder{}, err
+		}
+		wanted[status] = true
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	reminders := make([]models.Reminder, 0)
+	for id := range s.Snapshot.All {
+		_, reminder := s.Snapshot.All.flatten(id)
+		if !p.CanAccess(reminder) || (len(wanted) > 0 && !wanted[reminder.Status]) {
+			continue
+		}
+		reminders = append(reminders, reminder)
+	}
+	sort.Slice(reminders, func(i, j int) bool {
+		return reminders[i].ID < reminders[j].ID
+	})
+	return reminders, nil
+}
+
+func (s Reminders) DeadLetter(p models.Principal) ([]models.Reminder, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	reminders := make([]models.Reminder, 0)
+	for id := range s.Snapshot.All {
+		_, reminder := s.Snapshot.All.flatten(id)
+		if reminder.Status == models.StatusFailed && p.CanAccess(reminder) {
+			reminders = append(reminders, reminder)
+		}
+	}
+	sort.Slice(reminders, func(i, j int) bool {
+		return reminders[i].ID < reminders[j].ID
+	})
+	return reminders, nil
+}
+
+// Referencing returns the pending reminders rendered from the template.
+func (s Reminders) Referencing(t models.Template) []models.Reminder {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var reminders []models.Reminder
+	for id := range s.Snapshot.UnCompleted {
+		_, reminder := s.Snapshot.UnCompleted.flatten(id)
+		if reminder.Template == t.Name && t.UsableBy(reminder.Owner) {
+			reminders = append(reminders, reminder)
+		}
+	}
+	return reminders
+}
+
+func (s Reminders) Requeue(ctx context.Context, p models.Principal, id int) (models.Reminder, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	index, reminder, err := s.find(p, id)
+	if err != nil {
+		return models.Reminder{}, err
+	}
+	if reminder.Status != models.StatusFailed {
+		err := models.DataValidationError{
+			Message: fmt.Sprintf("reminder with id %d is not in the dead-letter list", id),
+		}
+		return models.Reminder{}, err
+	}
+	if err := expired(ctx); err != nil {
+		return models.Reminder{}, err
+	}
+	reminder.Status = models.StatusPending
+	reminder.Attempts = 0
+	reminder.ResetEscalation()
+	reminder.ModifiedAt = time.Now()
+	reminder.Duration = minRetryDelay
+	s.Snapshot.All[reminder.ID] = map[int]models.Reminder{index: reminder}
+	s.Snapshot.UnCompleted[reminder.ID] = map[int]models.Reminder{index: reminder}
+	s.events.Publish(models.EventReminderReopened, reminder)
+	return reminder, nil
+}
+
+func (s Reminders) Complete(ctx context.Context, p models.Principal, id int) (models.Reminder, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, reminder, err := s.find(p, id)
+	if err != nil {
+		return models.Reminder{}, err
+	}
+	if reminder.Status == models.StatusCompleted {
+		err := models.DataValidationError{
+			Message: fmt.Sprintf("reminder with id %d is already completed", id),
+		}
+		return models.Reminder{}, err
+	}
+	if err := expired(ctx); err != nil {
+		return models.Reminder{}, err
+	}
+	reminder.ModifiedAt = time.Now()
+	s.groom(reminder)
+	_, reminder = s.Snapshot.All.flatten(id)
+	return reminder, nil
+}
+
+func (s Reminders) Dismiss(ctx context.Context, p models.Principal, id int) (models.Reminder, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	index, reminder, err := s.find(p, id)
+	if err != nil {
+		return models.Reminder{}, err
+	}
+	if reminder.Status == models.StatusCompleted || reminder.Status == models.StatusDismissed {
+		err := models.DataValidationError{
+			Message: fmt.Sprintf("reminder with id %d is already %s", id, reminder.Status),
+		}
+		return models.Reminder{}, err
+	}
+	if err := expired(ctx); err != nil {
+		return models.Reminder{}, err
+	}
+	reminder.Status = models.StatusDismissed
+	reminder.ModifiedAt = time.Now()
+	delete(s.Snapshot.UnCompleted, id)
+	s.Snapshot.All[id] = map[int]models.Reminder{index: reminder}
+	s.events.Publish(models.EventReminderDismissed, reminder)
+	return reminder, nil
+}
+
+func (s Reminders) Reopen(ctx context.Context, p models.Principal, id int, d time.Duration) (models.Reminder, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	index, reminder, err := s.find(p, id)
+	if err != nil {
+		return models.Reminder{}, err
+	}
+	if _, ok := s.Snapshot.UnCompleted[id]; ok {
+		err := models.DataValidationError{
+			Message: fmt.Sprintf("reminder with id %d is still pending", id),
+		}
+		return models.Reminder{}, err
+	}
+	if d < minRetryDelay {
+		d = minRetryDelay
+	}
+	if err := expired(ctx); err != nil {
+		return models.Reminder{}, err
+	}
+	reminder.Status = models.StatusPending
+	reminder.DeferredUntil = nil
+	reminder.Attempts = 0
+	reminder.LastError = ""
+	reminder.Deliveries = nil
+	reminder.ResetEscalation()
+	reminder.ModifiedAt = time.Now()
+	reminder.Duration = d
+	s.Snapshot.All[id] = map[int]models.Reminder{index: reminder}
+	s.Snapshot.UnCompleted[id] = map[int]models.Reminder{index: reminder}
+	s.events.Publish(models.EventReminderReopened, reminder)
+	return reminder, nil
+}
+
+func (s Reminders) Delete(ctx context.Context, p models.Principal, ids []int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	var notFound []int
 	for _, id := range ids {
 		_, ok := s.Snapshot.All[id]
-		if !ok {
+		_, reminder := s.Snapshot.All.flatten(id)
+		if !ok || !p.CanAccess(reminder) {
 			notFound = append(notFound, id)
 		}
 	}
@@ -186,21 +556,47 @@ func (s Reminders) Delete(ids []int) error {
 			Message: fmt.Sprintf("could not find reminders with ids: %v", notFound),
 		}
 	}
+	if err := expired(ctx); err != nil {
+		return err
+	}
 
 	for _, id := range ids {
-		delete(s.Snapshot.All, id)
-		delete(s.Snapshot.UnCompleted, id)
+		_, reminder := s.Snapshot.All.flatten(id)
+		s.remove(reminder)
 	}
 	return nil
 }
 
+func (s Reminders) remove(reminder models.Reminder) {
+	delete(s.Snapshot.All, reminder.ID)
+	delete(s.Snapshot.UnCompleted, reminder.ID)
+	s.events.Publish(models.EventReminderDeleted, reminder)
+}
+
+func (s Reminders) find(p models.Principal, id int) (int, models.Reminder, error) {
+	_, ok := s.Snapshot.All[id]
+	index, reminder := s.Snapshot.All.flatten(id)
+	if !ok || !p.CanAccess(reminder) {
+		err := models.NotFoundError{
+			Message: fmt.Sprintf("could not find reminder with id: %d", id),
+		}
+		return 0, models.Reminder{}, err
+	}
+	return index, reminder, nil
+}
+
 func (s Reminders) save() error {
-	reminders := make([]models.Reminder, len(s.Snapshot.All))
+	s.mu.RLock()
+	reminders := make([]models.Reminder, 0, len(s.Snapshot.All))
 	for _, reminderMap := range s.Snapshot.All {
-		for i, reminder := range reminderMap {
-			reminders[i] = reminder
+		for _, reminder := range reminderMap {
+			reminders = append(reminders, reminder)
 		}
 	}
+	s.mu.RUnlock()
+	sort.Slice(reminders, func(i, j int) bool {
+		return reminders[i].ID < reminders[j].ID
+	})
 
 	n, err := s.repo.Save(reminders)
 	if err != nil {
@@ -212,27 +608,79 @@ func (s Reminders) save() error {
 	return nil
 }
 
+// snapshot copies the snapshot maps for the notifier, the reminder maps they
+// hold are replaced on every change and never modified.
 func (s Reminders) snapshot() Snapshot {
-	return s.Snapshot
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	res := Snapshot{
+		All:         make(RemindersMap, len(s.Snapshot.All)),
+		UnCompleted: make(RemindersMap, len(s.Snapshot.UnCompleted)),
+	}
+	for id, reminderMap := range s.Snapshot.All {
+		res.All[id] = reminderMap
+	}
+	for id, reminderMap := range s.Snapshot.UnCompleted {
+		res.UnCompleted[id] = reminderMap
+	}
+	return res
+}
+
+// notified reports whether the outcome of a notification still applies to
+// the reminder, it does not once the reminder was deleted or changed while
+// it was notified.
+func (s Reminders) notified(reminder models.Reminder) bool {
+	_, ok := s.Snapshot.All[reminder.ID]
+	_, current := s.Snapshot.All.flatten(reminder.ID)
+	if !ok || !current.ModifiedAt.Equal(reminder.ModifiedAt) {
+		log.Printf("reminder with id %d changed while it was notified\n", reminder.ID)
+		return false
+	}
+	return true
 }
 
 func (s Reminders) snapshotGrooming(notifiedReminders ...models.Reminder) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var groomed []models.Reminder
+	for _, reminder := range notifiedReminders {
+		if s.notified(reminder) {
+			groomed = append(groomed, reminder)
+		}
+	}
+	s.groom(groomed...)
+}
+
+func (s Reminders) groom(notifiedReminders ...models.Reminder) {
 	if len(notifiedReminders) > 0 {
 		log.Printf("snapshot grooming: %d record(s)", len(notifiedReminders))
 	}
 	for _, reminder := range notifiedReminders {
 		delete(s.Snapshot.UnCompleted, reminder.ID)
+		reminder.Status = models.StatusCompleted
 		reminder.Duration = -time.Hour
 		index, _ := s.Snapshot.All.flatten(reminder.ID)
 		s.Snapshot.All[reminder.ID] = map[int]models.Reminder{index: reminder}
+		s.events.Publish(models.EventReminderCompleted, reminder)
 	}
 }
 
 func (s Reminders) retry(reminder models.Reminder, d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.notified(reminder) {
+		s.reschedule(reminder, d)
+	}
+}
+
+func (s Reminders) reschedule(reminder models.Reminder, d time.Duration) {
 	reminder.ModifiedAt = time.Now()
-	if d <= 0 {
+	switch {
+	case d <= 0:
 		reminder.Duration = retryPeriod
-	} else {
+	case d < minRetryDelay:
+		reminder.Duration = minRetryDelay
+	default:
 		reminder.Duration = d
 	}
 	log.Printf(
@@ -244,3 +692,36 @@ func (s Reminders) retry(reminder models.Reminder, d time.Duration) {
 	s.Snapshot.All[reminder.ID] = map[int]models.Reminder{index: reminder}
 	s.Snapshot.UnCompleted[reminder.ID] = map[int]models.Reminder{index: reminder}
 }
+
+func (s Reminders) deferUntil(reminder models.Reminder, until time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.notified(reminder) {
+		return
+	}
+	reminder.Status = models.StatusDeferred
+	reminder.DeferredUntil = &until
+	reminder.ModifiedAt = time.Now()
+	reminder.Duration = until.Sub(reminder.ModifiedAt)
+	if reminder.Duration < minRetryDelay {
+		reminder.Duration = minRetryDelay
+	}
+	index, _ := s.Snapshot.All.flatten(reminder.ID)
+	s.Snapshot.All[reminder.ID] = map[int]models.Reminder{index: reminder}
+	s.Snapshot.UnCompleted[reminder.ID] = map[int]models.Reminder{index: reminder}
+	s.events.Publish(models.EventReminderDeferred, reminder)
+}
+
+func (s Reminders) fail(reminder models.Reminder) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.notified(reminder) {
+		return
+	}
+	delete(s.Snapshot.UnCompleted, reminder.ID)
+	reminder.Status = models.StatusFailed
+	reminder.ModifiedAt = time.Now()
+	index, _ := s.Snapshot.All.flatten(reminder.ID)
+	s.Snapshot.All[reminder.ID] = map[int]models.Reminder{index: reminder}
+	s.events.Publish(models.EventReminderFailed, reminder)
+}
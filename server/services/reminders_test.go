@@ -0,0 +1,431 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"app-pointment/server/models"
+)
+
+type memoryReminders struct {
+	mu     sync.Mutex
+	saved  []models.Reminder
+	nextID int
+}
+
+func (m *memoryReminders) Save(reminders []models.Reminder) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.saved = append([]models.Reminder(nil), reminders...)
+	return len(reminders), nil
+}
+
+func (m *memoryReminders) Filter(filterFn func(reminder models.Reminder) bool) (RemindersMap, error) {
+	res := RemindersMap{}
+	for i, r := range m.saved {
+		if filterFn == nil || filterFn(r) {
+			res[r.ID] = map[int]models.Reminder{i: r}
+		}
+	}
+	return res, nil
+}
+
+func (m *memoryReminders) NextID() int {
+	m.nextID++
+	return m.nextID
+}
+
+type acceptAllChannels struct{}
+
+func (acceptAllChannels) Validate(names []string) error {
+	return nil
+}
+
+func (acceptAllChannels) ValidateEscalation(name string) error {
+	return nil
+}
+
+func (acceptAllChannels) Delivered(r models.Reminder) bool {
+	for _, d := range r.Deliveries {
+		if d.Status != models.DeliveryDelivered {
+			return false
+		}
+	}
+	return true
+}
+
+func newTestReminders(t *testing.T) (*Reminders, *memoryReminders) {
+	repo := &memoryReminders{}
+	return NewReminders(repo, acceptAllChannels{}, NewTemplates(memoryTemplates{}), NewEvents()), repo
+}
+
+func createReminders(t *testing.T, s *Reminders, titles ...string) {
+	for _, title := range titles {
+		_, err := s.Create(context.Background(), ReminderCreateBody{Title: title, Message: "message", Duration: time.Hour})
+		if err != nil {
+			t.Fatalf("could not create reminder %s: %v", title, err)
+		}
+	}
+}
+
+func savedTitles(t *testing.T, repo *memoryReminders) string {
+	var res string
+	for _, r := range repo.saved {
+		if r.ID == 0 || r.Title == "" {
+			t.Errorf("saved an empty reminder: %+v", r)
+		}
+		res += fmt.Sprintf("%d:%s ", r.ID, r.Title)
+	}
+	return res
+}
+
+func TestDeleteThenCreateSaves(t *testing.T) {
+	tests := []struct {
+		name    string
+		deleted []int
+		batch   bool
+		want    string
+	}{
+		{"first", []int{1}, false, "2:b 3:c 4:new "},
+		{"middle", []int{2}, false, "1:a 3:c 4:new "},
+		{"last", []int{3}, false, "1:a 2:b 4:new "},
+		{"two", []int{1, 2}, false, "3:c 4:new "},
+		{"batch first", []int{1}, true, "2:b 3:c 4:new "},
+		{"batch middle", []int{2}, true, "1:a 3:c 4:new "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, repo := newTestReminders(t)
+			createReminders(t, s, "a", "b", "c")
+			p := models.Principal{Admin: true}
+			if tt.batch {
+				var ops []BatchOperation
+				for _, id := range tt.deleted {
+					ops = append(ops, BatchOperation{Op: models.BatchDelete, ID: id})
+				}
+				ops = append(ops, BatchOperation{
+					Op:     models.BatchCreate,
+					Create: ReminderCreateBody{Title: "new", Message: "message", Duration: time.Hour},
+				})
+				res, err := s.Batch(context.Background(), p, models.BatchAtomic, ops)
+				if err != nil || res.Failed > 0 {
+					t.Fatalf("batch failed: %v %+v", err, res)
+				}
+			} else {
+				if err := s.Delete(context.Background(), p, tt.deleted); err != nil {
+					t.Fatal(err)
+				}
+				createReminders(t, s, "new")
+			}
+
+			if err := s.save(); err != nil {
+				t.Fatal(err)
+			}
+			if got := savedTitles(t, repo); got != tt.want {
+				t.Errorf("saved %q, want %q", got, tt.want)
+			}
+
+			// the saved file loads back with the same reminders
+			loaded := NewReminders(repo, acceptAllChannels{}, nil, NewEvents())
+			if err := loaded.Populate(); err != nil {
+				t.Fatal(err)
+			}
+			createReminders(t, loaded, "after")
+			if err := loaded.save(); err != nil {
+				t.Fatal(err)
+			}
+			if got := savedTitles(t, repo); got != tt.want+"5:after " {
+				t.Errorf("saved after reload %q", got)
+			}
+		})
+	}
+}
+
+func TestEditKeepsTerminalReminders(t *testing.T) {
+	p := models.Principal{Admin: true}
+	tests := []struct {
+		name   string
+		finish func(s *Reminders, id int) error
+		status string
+	}{
+		{"completed", func(s *Reminders, id int) error {
+			_, err := s.Complete(context.Background(), p, id)
+			return err
+		}, models.StatusCompleted},
+		{"dismissed", func(s *Reminders, id int) error {
+			_, err := s.Dismiss(context.Background(), p, id)
+			return err
+		}, models.StatusDismissed},
+		{"failed", func(s *Reminders, id int) error {
+			_, r := s.Snapshot.All.flatten(id)
+			s.fail(r)
+			return nil
+		}, models.StatusFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := newTestReminders(t)
+			createReminders(t, s, "a")
+			if err := tt.finish(s, 1); err != nil {
+				t.Fatal(err)
+			}
+
+			r, err := s.Edit(context.Background(), ReminderEditBody{Principal: p, ID: 1, Title: "renamed"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r.Status != tt.status || r.Title != "renamed" {
+				t.Errorf("edited reminder is %s %q", r.Status, r.Title)
+			}
+			if _, ok := s.Snapshot.UnCompleted[1]; ok {
+				t.Error("edited reminder is pending again")
+			}
+
+			r, err = s.Reopen(context.Background(), p, 1, time.Minute)
+			if err != nil {
+				t.Fatalf("could not reopen the edited reminder: %v", err)
+			}
+			if r.Status != models.StatusPending || r.Title != "renamed" {
+				t.Errorf("reopened reminder is %s %q", r.Status, r.Title)
+			}
+		})
+	}
+}
+
+func TestEditLiveReminder(t *testing.T) {
+	p := models.Principal{Admin: true}
+	s, _ := newTestReminders(t)
+	createReminders(t, s, "a")
+	index, r := s.Snapshot.All.flatten(1)
+	r.Attempts = 2
+	r.LastError = "desktop: unavailable"
+	r.ModifiedAt = time.Now().Add(-10 * time.Minute)
+	r.Duration = 30 * time.Minute
+	s.Snapshot.All[1] = map[int]models.Reminder{index: r}
+	s.Snapshot.UnCompleted[1] = map[int]models.Reminder{index: r}
+	due := r.ModifiedAt.Add(r.Duration)
+
+	r, err := s.Edit(context.Background(), ReminderEditBody{Principal: p, ID: 1, Message: "changed"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Attempts != 2 || r.LastError == "" {
+		t.Errorf("content edit reset the delivery state: %+v", r)
+	}
+	if d := r.ModifiedAt.Add(r.Duration).Sub(due); d < -time.Second || d > time.Second {
+		t.Errorf("content edit moved the notification by %v", d)
+	}
+
+	r, err = s.Edit(context.Background(), ReminderEditBody{Principal: p, ID: 1, Duration: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Attempts != 0 || r.LastError != "" || r.Duration != time.Hour || r.Status != models.StatusPending {
+		t.Errorf("rescheduling kept the delivery state: %+v", r)
+	}
+	if _, ok := s.Snapshot.UnCompleted[1]; !ok {
+		t.Error("rescheduled reminder is not pending")
+	}
+}
+
+// TestConcurrentAccess runs the HTTP handlers, the notifier and the saver
+// against the same service, go test -race reports unguarded snapshot access.
+func TestConcurrentAccess(t *testing.T) {
+	p := models.Principal{Admin: true}
+	tests := []struct {
+		name string
+		run  func(s *Reminders, i int)
+	}{
+		{"create", func(s *Reminders, i int) {
+			s.Create(context.Background(), ReminderCreateBody{Title: "t", Message: "m", Duration: time.Hour})
+		}},
+		{"edit", func(s *Reminders, i int) {
+			s.Edit(context.Background(), ReminderEditBody{Principal: p, ID: 1 + i%5, Title: fmt.Sprint("edit ", i)})
+		}},
+		{"list", func(s *Reminders, i int) {
+			s.List(p, []int{1 + i%5})
+		}},
+		{"dead letter", func(s *Reminders, i int) {
+			s.DeadLetter(p)
+		}},
+		{"delete", func(s *Reminders, i int) {
+			s.Delete(context.Background(), p, []int{6 + i})
+		}},
+		{"requeue", func(s *Reminders, i int) {
+			s.Requeue(context.Background(), p, 1+i%5)
+		}},
+		{"ack and snooze", func(s *Reminders, i int) {
+			s.Ack(context.Background(), p, 1+i%5, "")
+			s.Snooze(context.Background(), p, 1+i%5, "", time.Minute)
+		}},
+		{"lifecycle", func(s *Reminders, i int) {
+			s.Complete(context.Background(), p, 1+i%5)
+			s.Reopen(context.Background(), p, 1+i%5, time.Minute)
+			s.Dismiss(context.Background(), p, 1+i%5)
+		}},
+		{"template references", func(s *Reminders, i int) {
+			s.Referencing(models.Template{Name: "appt"})
+		}},
+		{"batch", func(s *Reminders, i int) {
+			s.Batch(context.Background(), p, models.BatchBestEffort, []BatchOperation{
+				{Op: models.BatchCreate, Create: ReminderCreateBody{Title: "t", Message: "m", Duration: time.Hour}},
+				{Op: models.BatchEdit, ID: 1 + i%5, Edit: ReminderEditBody{Message: fmt.Sprint("batch ", i)}},
+			})
+		}},
+		{"list all", func(s *Reminders, i int) {
+			s.ListAll(p, nil)
+		}},
+		{"deliveries", func(s *Reminders, i int) {
+			s.Deliveries(p, 1+i%5)
+		}},
+		{"notifier", func(s *Reminders, i int) {
+			snapshot := s.snapshot()
+			for id := range snapshot.UnCompleted {
+				_, r := snapshot.UnCompleted.flatten(id)
+				switch i % 3 {
+				case 0:
+					s.retry(r, time.Minute)
+				case 1:
+					s.deferUntil(r, time.Now().Add(time.Hour))
+				default:
+					s.fail(r)
+				}
+			}
+		}},
+		{"saver", func(s *Reminders, i int) {
+			s.save()
+		}},
+	}
+	s, _ := newTestReminders(t)
+	createReminders(t, s, "a", "b", "c", "d", "e")
+	var wg sync.WaitGroup
+	for _, tt := range tests {
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func(run func(s *Reminders, i int), i int) {
+				defer wg.Done()
+				run(s, i)
+			}(tt.run, i)
+		}
+	}
+	wg.Wait()
+}
+
+func TestNotifierKeepsChangedReminders(t *testing.T) {
+	p := models.Principal{Admin: true}
+	tests := []struct {
+		name   string
+		change func(s *Reminders) error
+		want   func(s *Reminders) error
+	}{
+		{"deleted", func(s *Reminders) error {
+			return s.Delete(context.Background(), p, []int{1})
+		}, func(s *Reminders) error {
+			if _, ok := s.Snapshot.All[1]; ok {
+				return fmt.Errorf("deleted reminder is back")
+			}
+			return nil
+		}},
+		{"edited", func(s *Reminders) error {
+			_, err := s.Edit(context.Background(), ReminderEditBody{Principal: p, ID: 1, Duration: 2 * time.Hour})
+			return err
+		}, func(s *Reminders) error {
+			if _, r := s.Snapshot.All.flatten(1); r.Duration != 2*time.Hour || r.Status != models.StatusPending {
+				return fmt.Errorf("edited reminder is %s in %v", r.Status, r.Duration)
+			}
+			return nil
+		}},
+	}
+	notifier := []struct {
+		name   string
+		finish func(s *Reminders, r models.Reminder)
+	}{
+		{"retry", func(s *Reminders, r models.Reminder) { s.retry(r, time.Minute) }},
+		{"defer", func(s *Reminders, r models.Reminder) { s.deferUntil(r, time.Now().Add(time.Minute)) }},
+		{"fail", func(s *Reminders, r models.Reminder) { s.fail(r) }},
+		{"complete", func(s *Reminders, r models.Reminder) { s.snapshotGrooming(r) }},
+	}
+	for _, tt := range tests {
+		for _, n := range notifier {
+			t.Run(tt.name+" then "+n.name, func(t *testing.T) {
+				s, _ := newTestReminders(t)
+				createReminders(t, s, "a")
+				_, r := s.snapshot().All.flatten(1)
+				// the user changes the reminder while the notifier waits for the channels
+				time.Sleep(time.Millisecond)
+				if err := tt.change(s); err != nil {
+					t.Fatal(err)
+				}
+				r.Attempts++
+				n.finish(s, r)
+				if err := tt.want(s); err != nil {
+					t.Error(err)
+				}
+			})
+		}
+	}
+}
+
+func TestExpiredRequestsChangeNothing(t *testing.T) {
+	p := models.Principal{Admin: true}
+	tests := []struct {
+		name   string
+		change func(ctx context.Context, s *Reminders) error
+	}{
+		{"create", func(ctx context.Context, s *Reminders) error {
+			_, err := s.Create(ctx, ReminderCreateBody{Title: "t", Message: "m", Duration: time.Hour})
+			return err
+		}},
+		{"edit", func(ctx context.Context, s *Reminders) error {
+			_, err := s.Edit(ctx, ReminderEditBody{Principal: p, ID: 1, Title: "renamed"})
+			return err
+		}},
+		{"delete", func(ctx context.Context, s *Reminders) error {
+			return s.Delete(ctx, p, []int{1})
+		}},
+		{"complete", func(ctx context.Context, s *Reminders) error {
+			_, err := s.Complete(ctx, p, 1)
+			return err
+		}},
+		{"dismiss", func(ctx context.Context, s *Reminders) error {
+			_, err := s.Dismiss(ctx, p, 1)
+			return err
+		}},
+		{"ack", func(ctx context.Context, s *Reminders) error {
+			_, err := s.Ack(ctx, p, 1, "")
+			return err
+		}},
+		{"snooze", func(ctx context.Context, s *Reminders) error {
+			_, err := s.Snooze(ctx, p, 1, "", time.Minute)
+			return err
+		}},
+		{"batch", func(ctx context.Context, s *Reminders) error {
+			_, err := s.Batch(ctx, p, models.BatchBestEffort, []BatchOperation{{Op: models.BatchDelete, ID: 1}})
+			return err
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := newTestReminders(t)
+			createReminders(t, s, "a")
+			index, r := s.Snapshot.All.flatten(1)
+			r.Deliveries = map[string]models.ChannelDelivery{"desktop": {Status: models.DeliveryAwaiting, DeliveryID: "d1"}}
+			s.Snapshot.All[1] = map[int]models.Reminder{index: r}
+			s.Snapshot.UnCompleted[1] = map[int]models.Reminder{index: r}
+			before := s.snapshot()
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			if err := tt.change(ctx, s); !errors.As(err, &models.TimeoutError{}) {
+				t.Fatalf("got error %v, want a timeout", err)
+			}
+			if after := s.snapshot(); !reflect.DeepEqual(after, before) {
+				t.Errorf("expired request changed the reminders")
+			}
+		})
+	}
+}
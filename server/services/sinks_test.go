@@ -0,0 +1,67 @@
+package services
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	"app-pointment/server/models"
+)
+
+func TestSMTPChannelAddresses(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		to    string
+		err   bool
+	}{
+		{"single", "from=bot@example.com&to=me@example.com", "me@example.com", false},
+		{"several", "from=bot@example.com&to=a@example.com,+b@example.com", "a@example.com b@example.com", false},
+		{"display name", "from=Bot+<bot@example.com>&to=Me+<me@example.com>", "me@example.com", false},
+		{"header in to", "from=bot@example.com&to=me@example.com%0D%0ABcc:+eve@example.com", "", true},
+		{"header in from", "from=bot@example.com%0ABcc:+eve@example.com&to=me@example.com", "", true},
+		{"not an address", "from=bot@example.com&to=me", "", true},
+		{"missing to", "from=bot@example.com", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, err := url.Parse("smtp://mail.example.com:587?" + tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			c, err := NewSMTPChannel("email", u)
+			if (err != nil) != tt.err {
+				t.Fatalf("NewSMTPChannel() err = %v, want error %v", err, tt.err)
+			}
+			if got := strings.Join(c.to, " "); got != tt.to {
+				t.Errorf("to = %q, want %q", got, tt.to)
+			}
+		})
+	}
+}
+
+func TestSMTPChannelSubject(t *testing.T) {
+	c := SMTPChannel{from: "bot@example.com", to: []string{"me@example.com"}}
+	tests := []struct {
+		name    string
+		title   string
+		subject string
+	}{
+		{"plain", "Standup", "Subject: Standup\r\n"},
+		{"header injection", "Standup\r\nBcc: eve@example.com", "Subject: Standup Bcc: eve@example.com\r\n"},
+		{"bare line feed", "Standup\nBcc: eve@example.com", "Subject: Standup Bcc: eve@example.com\r\n"},
+		{"utf-8", "Zahnärzt", "Subject: =?utf-8?q?Zahn=C3=A4rzt?=\r\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := string(c.message(models.Reminder{Title: tt.title, Message: "message"}))
+			headers := msg[:strings.Index(msg, "\r\n\r\n")+2]
+			if !strings.Contains(headers, tt.subject) {
+				t.Errorf("headers %q do not contain %q", headers, tt.subject)
+			}
+			if strings.Contains(headers, "\r\nBcc:") {
+				t.Errorf("title added a header: %q", headers)
+			}
+		})
+	}
+}
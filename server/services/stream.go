@@ -0,0 +1,74 @@
+package services
+
+import (
+	"sync"
+
+	"app-pointment/server/models"
+)
+
+const streamSubscriberBuffer = 64
+
+// EventStream keeps the most recent events in a bounded buffer so that
+// clients reconnecting with their last seen event id can resume.
+type EventStream struct {
+	mu          sync.Mutex
+	size        int
+	buffer      []models.Event
+	subscribers map[chan models.Event]struct{}
+}
+
+func NewEventStream(size int) *EventStream {
+	if size < 1 {
+		size = 1
+	}
+	return &EventStream{
+		size:        size,
+		subscribers: map[chan models.Event]struct{}{},
+	}
+}
+
+func (s *EventStream) Handle(e models.Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.buffer = append(s.buffer, e)
+	if len(s.buffer) > s.size {
+		s.buffer = s.buffer[len(s.buffer)-s.size:]
+	}
+	for ch := range s.subscribers {
+		select {
+		case ch <- e:
+		default:
+			// The subscriber is too slow, disconnect it so it resumes
+			// from the buffer with its last event id.
+			delete(s.subscribers, ch)
+			close(ch)
+		}
+	}
+	streamSubscribers.Set(float64(len(s.subscribers)))
+}
+
+// Subscribe returns the buffered events newer than lastID and a channel of
+// live events. The channel is closed when the subscriber falls behind.
+func (s *EventStream) Subscribe(lastID int64) ([]models.Event, <-chan models.Event, func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var backlog []models.Event
+	for _, e := range s.buffer {
+		if e.ID > lastID {
+			backlog = append(backlog, e)
+		}
+	}
+	ch := make(chan models.Event, streamSubscriberBuffer)
+	s.subscribers[ch] = struct{}{}
+	streamSubscribers.Set(float64(len(s.subscribers)))
+	cancel := func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if _, ok := s.subscribers[ch]; ok {
+			delete(s.subscribers, ch)
+			close(ch)
+		}
+		streamSubscribers.Set(float64(len(s.subscribers)))
+	}
+	return backlog, ch, cancel
+}
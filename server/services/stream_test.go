@@ -0,0 +1,85 @@
+package services
+
+import (
+	"testing"
+
+	"app-pointment/server/models"
+)
+
+func newTestStream(size, published int) *EventStream {
+	stream := NewEventStream(size)
+	events := NewEvents()
+	events.Subscribe(stream.Handle)
+	for i := 1; i <= published; i++ {
+		events.Publish(models.EventReminderCreated, models.Reminder{ID: i})
+	}
+	return stream
+}
+
+func TestEventStreamResume(t *testing.T) {
+	tests := []struct {
+		name   string
+		lastID int64
+		want   []int64
+	}{
+		{"new subscriber", 0, []int64{3, 4, 5}},
+		{"older than the buffer", 1, []int64{3, 4, 5}},
+		{"inside the buffer", 4, []int64{5}},
+		{"up to date", 5, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stream := newTestStream(3, 5)
+			backlog, _, cancel := stream.Subscribe(tt.lastID)
+			defer cancel()
+			var got []int64
+			for _, e := range backlog {
+				got = append(got, e.ID)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("backlog has events %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("backlog has events %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestEventStreamLive(t *testing.T) {
+	stream := newTestStream(3, 0)
+	_, live, cancel := stream.Subscribe(0)
+	stream.Handle(models.Event{ID: 1, Type: models.EventReminderFired})
+	if e := <-live; e.ID != 1 || e.Type != models.EventReminderFired {
+		t.Errorf("got event %d %s, want 1 %s", e.ID, e.Type, models.EventReminderFired)
+	}
+	cancel()
+	cancel()
+	if _, ok := <-live; ok {
+		t.Error("channel is still open after cancel")
+	}
+	stream.Handle(models.Event{ID: 2})
+}
+
+func TestEventStreamDropsSlowSubscribers(t *testing.T) {
+	stream := newTestStream(3, 0)
+	_, live, cancel := stream.Subscribe(0)
+	defer cancel()
+	for i := 1; i <= streamSubscriberBuffer+1; i++ {
+		stream.Handle(models.Event{ID: int64(i)})
+	}
+	received := 0
+	for range live {
+		received++
+	}
+	if received != streamSubscriberBuffer {
+		t.Errorf("received %d event(s) before the disconnect, want %d", received, streamSubscriberBuffer)
+	}
+	backlog, _, cancel := stream.Subscribe(int64(received))
+	defer cancel()
+	if len(backlog) != 1 || backlog[0].ID != int64(streamSubscriberBuffer+1) {
+		t.Errorf("resumed with %v, want the missed event", backlog)
+	}
+}
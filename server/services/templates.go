@@ -0,0 +1,362 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"time"
+
+	"app-pointment/server/models"
+)
+
+var templateNameRegexp = regexp.MustCompile(`^[a-zA-Z0-9_.-]+$`)
+
+// TemplateRepository stores templates by their models.Template Key.
+type TemplateRepository interface {
+	Save(t models.Template) error
+	Get(key string) (models.Template, error)
+	List() ([]models.Template, error)
+	Delete(key string) error
+}
+
+// TemplateReferences finds the pending reminders rendered from a template.
+type TemplateReferences interface {
+	Referencing(t models.Template) []models.Reminder
+}
+
+type Templates struct {
+	repo TemplateRepository
+
+	Reminders TemplateReferences
+}
+
+func NewTemplates(repo TemplateRepository) *Templates {
+	return &Templates{repo: repo}
+}
+
+type TemplateBody struct {
+	Principal models.Principal
+	Name      string
+	Title     string
+	Message   string
+}
+
+func (s *Templates) Create(body TemplateBody) (models.Template, error) {
+	if !templateNameRegexp.MatchString(body.Name) {
+		return models.Template{}, models.DataValidationError{
+			Message: fmt.Sprintf("invalid template name %q, expected letters, digits, '.', '_' or '-'", body.Name),
+		}
+	}
+	// private templates of other users do not conflict, the caller cannot see them
+	_, err := s.find(body.Principal, body.Name)
+	if err == nil || errors.As(err, &models.ConflictError{}) {
+		return models.Template{}, models.DataValidationError{
+			Message: fmt.Sprintf("template %s already exists", body.Name),
+		}
+	}
+	if !errors.As(err, &models.NotFoundError{}) {
+		return models.Template{}, err
+	}
+	t := models.Template{
+		Name:      body.Name,
+		Owner:     body.Principal.User,
+		Admin:     body.Principal.Admin,
+		CreatedAt: time.Now(),
+	}
+	t, err = build(t, body)
+	if err != nil {
+		return models.Template{}, err
+	}
+	return s.save(t)
+}
+
+// Update replaces the title and message of the template, it is rejected when
+// a pending reminder rendered from it would no longer render.
+func (s *Templates) Update(body TemplateBody) (models.Template, error) {
+	t, err := s.find(body.Principal, body.Name)
+	if err != nil {
+		return models.Template{}, err
+	}
+	if !canManageTemplate(body.Principal, t) {
+		return models.Template{}, models.ForbiddenError{
+			Message: fmt.Sprintf("template %s belongs to another user", t.Name),
+		}
+	}
+	t, err = build(t, body)
+	if err != nil {
+		return models.Template{}, err
+	}
+	for _, r := range s.referencing(t) {
+		if err := validate(t, r.Variables); err != nil {
+			return models.Template{}, models.DataValidationError{
+				Message: fmt.Sprintf("template %s is used by pending reminder %d: %v", t.Name, r.ID, err),
+			}
+		}
+	}
+	return s.save(t)
+}
+
+func (s *Templates) Get(p models.Principal, name string) (models.Template, error) {
+	return s.find(p, name)
+}
+
+func (s *Templates) List(p models.Principal) ([]models.Template, error) {
+	all, err := s.repo.List()
+	if err != nil {
+		return nil, err
+	}
+	templates := make([]models.Template, 0, len(all))
+	for _, t := range all {
+		if canSeeTemplate(p, t) {
+			templates = append(templates, t)
+		}
+	}
+	return templates, nil
+}
+
+// Delete removes the template unless pending reminders are still rendered from it.
+func (s *Templates) Delete(p models.Principal, name string) error {
+	t, err := s.find(p, name)
+	if err != nil {
+		return err
+	}
+	if !canManageTemplate(p, t) {
+		return models.ForbiddenError{
+			Message: fmt.Sprintf("template %s belongs to another user", t.Name),
+		}
+	}
+	if refs := s.referencing(t); len(refs) > 0 {
+		ids := make([]string, len(refs))
+		for i, r := range refs {
+			ids[i] = fmt.Sprint(r.ID)
+		}
+		return models.ConflictError{
+			Message: fmt.Sprintf("template %s is used by pending reminders: %s", t.Name, strings.Join(ids, ", ")),
+		}
+	}
+	return s.repo.Delete(t.Key())
+}
+
+// find returns the template the principal sees under the name, templates of
+// other users are not found. Admins see the private templates of every user.
+func (s *Templates) find(p models.Principal, name string) (models.Template, error) {
+	if !p.Admin {
+		return s.lookup(p.User, name)
+	}
+	t, err := s.lookup("", name)
+	if !errors.As(err, &models.NotFoundError{}) {
+		return t, err
+	}
+	all, err := s.repo.List()
+	if err != nil {
+		return models.Template{}, err
+	}
+	var found []models.Template
+	var owners []string
+	for _, t := range all {
+		if t.Name == name && !t.Shared() {
+			found = append(found, t)
+			owners = append(owners, t.Owner)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return models.Template{}, templateNotFound(name)
+	case 1:
+		return found[0], nil
+	default:
+		sort.Strings(owners)
+		return models.Template{}, models.ConflictError{
+			Message: fmt.Sprintf("template %s is owned by several users: %s", name, strings.Join(owners, ", ")),
+		}
+	}
+}
+
+// lookup returns the template reminders of the owner are rendered from, the
+// owner's own template or a shared one.
+func (s *Templates) lookup(owner, name string) (models.Template, error) {
+	keys := []string{name}
+	if owner != "" {
+		keys = []string{models.TemplateKey(owner, name, false), name}
+	}
+	for _, key := range keys {
+		t, err := s.repo.Get(key)
+		if err == nil {
+			return t, nil
+		}
+		if !errors.As(err, &models.NotFoundError{}) {
+			return models.Template{}, err
+		}
+	}
+	return models.Template{}, templateNotFound(name)
+}
+
+func templateNotFound(name string) error {
+	return models.NotFoundError{
+		Message: fmt.Sprintf("could not find template with name: %s", name),
+	}
+}
+
+func (s *Templates) referencing(t models.Template) []models.Reminder {
+	if s.Reminders == nil {
+		return nil
+	}
+	refs := s.Reminders.Referencing(t)
+	sort.Slice(refs, func(i, j int) bool {
+		return refs[i].ID < refs[j].ID
+	})
+	return refs
+}
+
+func canSeeTemplate(p models.Principal, t models.Template) bool {
+	return p.Admin || t.UsableBy(p.User)
+}
+
+func canManageTemplate(p models.Principal, t models.Template) bool {
+	return p.Admin || (!t.Shared() && t.Owner == p.User)
+}
+
+func build(t models.Template, body TemplateBody) (models.Template, error) {
+	if strings.TrimSpace(body.Title) == "" || strings.TrimSpace(body.Message) == "" {
+		return models.Template{}, models.DataValidationError{Message: "template title and message cannot be empty"}
+	}
+	vars := map[string]bool{}
+	for field, text := range map[string]string{"title": body.Title, "message": body.Message} {
+		tmpl, err := parseTemplate(t.Name+"."+field, text)
+		if err != nil {
+			return models.Template{}, models.DataValidationError{
+				Message: fmt.Sprintf("invalid template %s: %v", field, err),
+			}
+		}
+		collectVariables(tmpl.Tree.Root, vars)
+	}
+	t.Title = body.Title
+	t.Message = body.Message
+	t.Variables = nil
+	for v := range vars {
+		t.Variables = append(t.Variables, v)
+	}
+	sort.Strings(t.Variables)
+	t.ModifiedAt = time.Now()
+	return t, nil
+}
+
+func (s *Templates) save(t models.Template) (models.Template, error) {
+	if err := s.repo.Save(t); err != nil {
+		return models.Template{}, err
+	}
+	return t, nil
+}
+
+// Validate checks that the owner can use the template, that every variable
+// it uses is provided and that it renders.
+func (s *Templates) Validate(owner, name string, variables map[string]string) error {
+	t, err := s.lookup(owner, name)
+	if err != nil {
+		return models.DataValidationError{Message: fmt.Sprintf("unknown template %q", name)}
+	}
+	if err := validate(t, variables); err != nil {
+		return models.DataValidationError{Message: err.Error()}
+	}
+	return nil
+}
+
+func validate(t models.Template, variables map[string]string) error {
+	var missing []string
+	for _, v := range t.Variables {
+		if _, ok := variables[v]; !ok {
+			missing = append(missing, v)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("template %s requires variables: %s", t.Name, strings.Join(missing, ", "))
+	}
+	_, _, err := render(t, variables)
+	return err
+}
+
+// Render returns the reminder with its title and message rendered from its
+// template, reminders without a template are returned as they are.
+func (s *Templates) Render(r models.Reminder) (models.Reminder, error) {
+	if r.Template == "" {
+		return r, nil
+	}
+	if s == nil {
+		return r, fmt.Errorf("templates are not configured")
+	}
+	t, err := s.lookup(r.Owner, r.Template)
+	if err != nil {
+		return r, err
+	}
+	title, message, err := render(t, r.Variables)
+	if err != nil {
+		return r, err
+	}
+	r.Title = title
+	r.Message = message
+	return r, nil
+}
+
+func render(t models.Template, variables map[string]string) (string, string, error) {
+	var res [2]string
+	for i, text := range []string{t.Title, t.Message} {
+		tmpl, err := parseTemplate(t.Name, text)
+		if err != nil {
+			return "", "", err
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, variables); err != nil {
+			return "", "", fmt.Errorf("could not render template %s: %v", t.Name, err)
+		}
+		res[i] = buf.String()
+	}
+	return res[0], res[1], nil
+}
+
+func parseTemplate(name, text string) (*template.Template, error) {
+	return template.New(name).Option("missingkey=error").Parse(text)
+}
+
+// collectVariables records the top level fields, like .Name, referenced by
+// the template. Fields inside range and with blocks refer to another dot.
+func collectVariables(node parse.Node, vars map[string]bool) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, c := range n.Nodes {
+			collectVariables(c, vars)
+		}
+	case *parse.ActionNode:
+		collectVariables(n.Pipe, vars)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, cmd := range n.Cmds {
+			for _, arg := range cmd.Args {
+				collectVariables(arg, vars)
+			}
+		}
+	case *parse.FieldNode:
+		vars[n.Ident[0]] = true
+	case *parse.IfNode:
+		collectVariables(n.Pipe, vars)
+		collectVariables(n.List, vars)
+		collectVariables(n.ElseList, vars)
+	case *parse.RangeNode:
+		collectVariables(n.Pipe, vars)
+		collectVariables(n.ElseList, vars)
+	case *parse.WithNode:
+		collectVariables(n.Pipe, vars)
+		collectVariables(n.ElseList, vars)
+	case *parse.TemplateNode:
+		collectVariables(n.Pipe, vars)
+	}
+}
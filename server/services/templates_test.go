@@ -0,0 +1,200 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"app-pointment/server/models"
+)
+
+type memoryTemplates map[string]models.Template
+
+func (m memoryTemplates) Save(t models.Template) error {
+	m[t.Key()] = t
+	return nil
+}
+
+func (m memoryTemplates) Get(key string) (models.Template, error) {
+	t, ok := m[key]
+	if !ok {
+		return models.Template{}, models.NotFoundError{}
+	}
+	return t, nil
+}
+
+func (m memoryTemplates) List() ([]models.Template, error) {
+	var res []models.Template
+	for _, t := range m {
+		res = append(res, t)
+	}
+	return res, nil
+}
+
+func (m memoryTemplates) Delete(key string) error {
+	delete(m, key)
+	return nil
+}
+
+type templateUsers []models.Reminder
+
+func (p templateUsers) Referencing(t models.Template) []models.Reminder {
+	var res []models.Reminder
+	for _, r := range p {
+		if r.Template == t.Name && t.UsableBy(r.Owner) {
+			res = append(res, r)
+		}
+	}
+	return res
+}
+
+var (
+	alice = models.Principal{User: "alice"}
+	bob   = models.Principal{User: "bob"}
+	root  = models.Principal{User: "root", Admin: true}
+)
+
+func newTestTemplates(t *testing.T, pending ...models.Reminder) *Templates {
+	s := NewTemplates(memoryTemplates{})
+	s.Reminders = templateUsers(pending)
+	for _, body := range []TemplateBody{
+		{Principal: alice, Name: "appt", Title: "Appointment with {{.Name}}", Message: "At {{.Time}}"},
+		{Principal: root, Name: "shared", Title: "Shared", Message: "{{.Text}}"},
+	} {
+		if _, err := s.Create(body); err != nil {
+			t.Fatalf("could not create template %s: %v", body.Name, err)
+		}
+	}
+	return s
+}
+
+func TestTemplatesOwnerScoping(t *testing.T) {
+	s := newTestTemplates(t)
+
+	if _, err := s.Get(bob, "appt"); !errors.As(err, &models.NotFoundError{}) {
+		t.Errorf("bob got alice's template, err = %v", err)
+	}
+	if _, err := s.Get(bob, "shared"); err != nil {
+		t.Errorf("bob cannot see the shared template: %v", err)
+	}
+	if _, err := s.Get(root, "appt"); err != nil {
+		t.Errorf("admin cannot see alice's template: %v", err)
+	}
+	if list, _ := s.List(bob); len(list) != 1 || list[0].Name != "shared" {
+		t.Errorf("bob lists %v", list)
+	}
+	if list, _ := s.List(root); len(list) != 2 {
+		t.Errorf("admin lists %v", list)
+	}
+
+	if _, err := s.Update(TemplateBody{Principal: alice, Name: "shared", Title: "Shared", Message: "changed"}); !errors.As(err, &models.ForbiddenError{}) {
+		t.Errorf("alice updated the shared template, err = %v", err)
+	}
+	if err := s.Delete(bob, "appt"); !errors.As(err, &models.NotFoundError{}) {
+		t.Errorf("bob deleted alice's template, err = %v", err)
+	}
+	if err := s.Delete(root, "appt"); err != nil {
+		t.Errorf("admin cannot delete alice's template: %v", err)
+	}
+
+	if err := s.Validate("bob", "shared", map[string]string{"Text": "x"}); err != nil {
+		t.Errorf("bob cannot use the shared template: %v", err)
+	}
+	if err := s.Validate("bob", "appt", nil); err == nil {
+		t.Error("bob can use a template they cannot see")
+	}
+}
+
+func TestTemplatesReferencedByPendingReminders(t *testing.T) {
+	s := newTestTemplates(t,
+		models.Reminder{ID: 4, Owner: "alice", Template: "appt", Variables: map[string]string{"Name": "Bob", "Time": "10am"}},
+		models.Reminder{ID: 2, Owner: "alice", Template: "appt", Variables: map[string]string{"Name": "Eve", "Time": "noon", "Room": "B"}},
+	)
+
+	err := s.Delete(alice, "appt")
+	if !errors.As(err, &models.ConflictError{}) || err.Error() != "template appt is used by pending reminders: 2, 4" {
+		t.Errorf("delete of a referenced template: %v", err)
+	}
+	if _, err := s.Get(alice, "appt"); err != nil {
+		t.Errorf("referenced template was deleted: %v", err)
+	}
+
+	_, err = s.Update(TemplateBody{Principal: alice, Name: "appt", Title: "{{.Name}} in {{.Room}}", Message: "At {{.Time}}"})
+	if !errors.As(err, &models.DataValidationError{}) {
+		t.Errorf("update breaking reminder 4 was accepted: %v", err)
+	}
+	if tmpl, _ := s.Get(alice, "appt"); tmpl.Title != "Appointment with {{.Name}}" {
+		t.Errorf("rejected update was saved: %q", tmpl.Title)
+	}
+
+	tmpl, err := s.Update(TemplateBody{Principal: alice, Name: "appt", Title: "Meet {{.Name}}", Message: "{{.Time}}"})
+	if err != nil {
+		t.Fatalf("compatible update was rejected: %v", err)
+	}
+	if len(tmpl.Variables) != 2 {
+		t.Errorf("variables = %v", tmpl.Variables)
+	}
+
+	if err := s.Delete(alice, "shared"); !errors.As(err, &models.ForbiddenError{}) {
+		t.Errorf("alice deleted the shared template, err = %v", err)
+	}
+	if err := s.Delete(root, "shared"); err != nil {
+		t.Errorf("unreferenced template: %v", err)
+	}
+}
+
+func TestTemplatesPrivateNames(t *testing.T) {
+	s := newTestTemplates(t, models.Reminder{ID: 7, Owner: "bob", Template: "appt", Variables: map[string]string{"Who": "Eve"}})
+
+	// alice's template is invisible to bob, creating his own must not reveal it
+	if _, err := s.Create(TemplateBody{Principal: bob, Name: "appt", Title: "Call {{.Who}}", Message: "Now"}); err != nil {
+		t.Fatalf("bob cannot create a template named like alice's: %v", err)
+	}
+	tests := []struct {
+		name     string
+		p        models.Principal
+		title    string
+		notFound bool
+		conflict bool
+	}{
+		{"alice", alice, "Appointment with {{.Name}}", false, false},
+		{"bob", bob, "Call {{.Who}}", false, false},
+		{"eve", models.Principal{User: "eve"}, "", true, false},
+		{"admin with two owners", root, "", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := s.Get(tt.p, "appt")
+			if errors.As(err, &models.NotFoundError{}) != tt.notFound || errors.As(err, &models.ConflictError{}) != tt.conflict {
+				t.Fatalf("Get() err = %v", err)
+			}
+			if err == nil && tmpl.Title != tt.title {
+				t.Errorf("Get() = %q, %v, want %q", tmpl.Title, err, tt.title)
+			}
+		})
+	}
+
+	for _, body := range []TemplateBody{
+		{Principal: bob, Name: "appt", Title: "x", Message: "x"},
+		{Principal: bob, Name: "shared", Title: "x", Message: "x"},
+		{Principal: root, Name: "appt", Title: "x", Message: "x"},
+	} {
+		if _, err := s.Create(body); !errors.As(err, &models.DataValidationError{}) {
+			t.Errorf("%s created a second visible template %s, err = %v", body.Principal.User, body.Name, err)
+		}
+	}
+
+	r, err := s.Render(models.Reminder{Owner: "bob", Template: "appt", Variables: map[string]string{"Who": "Eve"}})
+	if err != nil || r.Title != "Call Eve" {
+		t.Errorf("bob's reminder rendered %q, %v", r.Title, err)
+	}
+	if err := s.Validate("eve", "appt", nil); err == nil {
+		t.Error("eve can use a private template")
+	}
+	// bob's pending reminder only references his own template
+	if err := s.Delete(alice, "appt"); err != nil {
+		t.Errorf("alice cannot delete her template: %v", err)
+	}
+	if err := s.Delete(bob, "appt"); !errors.As(err, &models.ConflictError{}) {
+		t.Errorf("bob deleted a referenced template, err = %v", err)
+	}
+}
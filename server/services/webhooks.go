@@ -0,0 +1,282 @@
+package services
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	mrand "math/rand"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"app-pointment/server/models"
+)
+
+const (
+	WebhookSignatureHeader = "X-App-Pointment-Signature"
+	WebhookEventHeader     = "X-App-Pointment-Event"
+	WebhookDeliveryHeader  = "X-App-Pointment-Delivery"
+
+	webhookQueueSize = 256
+)
+
+type WebhookRepository interface {
+	Create(w models.Webhook) (models.Webhook, error)
+	List() ([]models.Webhook, error)
+	Get(id int) (models.Webhook, error)
+	Delete(id int) error
+	LogDelivery(d models.WebhookDelivery) error
+	Deliveries(id int) ([]models.WebhookDelivery, error)
+}
+
+type webhookJob struct {
+	webhook models.Webhook
+	event   models.Event
+}
+
+type Webhooks struct {
+	repo   WebhookRepository
+	policy models.RetryPolicy
+	client *http.Client
+	queue  chan webhookJob
+	stop   chan struct{}
+	wg     sync.WaitGroup
+}
+
+func NewWebhooks(repo WebhookRepository, policy models.RetryPolicy) *Webhooks {
+	return &Webhooks{
+		repo:   repo,
+		policy: policy,
+		client: &http.Client{Timeout: 10 * time.Second},
+		queue:  make(chan webhookJob, webhookQueueSize),
+		stop:   make(chan struct{}),
+	}
+}
+
+type WebhookCreateBody struct {
+	Principal models.Principal
+	URL       string
+	Events    []string
+	Secret    string
+}
+
+func (s *Webhooks) Create(body WebhookCreateBody) (models.Webhook, error) {
+	u, err := url.Parse(body.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return models.Webhook{}, models.DataValidationError{
+			Message: fmt.Sprintf("invalid webhook url %q, expected an absolute http(s) url", body.URL),
+		}
+	}
+	for _, t := range body.Events {
+		if !models.ValidEventType(t) {
+			return models.Webhook{}, models.DataValidationError{
+				Message: fmt.Sprintf("invalid event %q, expected one of %v", t, models.EventTypes),
+			}
+		}
+	}
+	secret := body.Secret
+	if secret == "" {
+		if secret, err = randomHex(24); err != nil {
+			return models.Webhook{}, err
+		}
+	}
+	return s.repo.Create(models.Webhook{
+		Owner:  body.Principal.User,
+		Admin:  body.Principal.Admin,
+		URL:    body.URL,
+		Events: body.Events,
+		Secret: secret,
+	})
+}
+
+func (s *Webhooks) List(p models.Principal) ([]models.Webhook, error) {
+	all, err := s.repo.List()
+	if err != nil {
+		return nil, err
+	}
+	webhooks := make([]models.Webhook, 0, len(all))
+	for _, w := range all {
+		if !canManage(p, w) {
+			continue
+		}
+		w.Secret = ""
+		webhooks = append(webhooks, w)
+	}
+	sort.Slice(webhooks, func(i, j int) bool {
+		return webhooks[i].ID < webhooks[j].ID
+	})
+	return webhooks, nil
+}
+
+func (s *Webhooks) Delete(p models.Principal, id int) error {
+	if _, err := s.find(p, id); err != nil {
+		return err
+	}
+	return s.repo.Delete(id)
+}
+
+func (s *Webhooks) Deliveries(p models.Principal, id int) ([]models.WebhookDelivery, error) {
+	if _, err := s.find(p, id); err != nil {
+		return nil, err
+	}
+	return s.repo.Deliveries(id)
+}
+
+func (s *Webhooks) find(p models.Principal, id int) (models.Webhook, error) {
+	w, err := s.repo.Get(id)
+	if err != nil {
+		return models.Webhook{}, err
+	}
+	if !canManage(p, w) {
+		return models.Webhook{}, models.NotFoundError{
+			Message: fmt.Sprintf("could not find webhook with id: %d", id),
+		}
+	}
+	return w, nil
+}
+
+func canManage(p models.Principal, w models.Webhook) bool {
+	return p.Admin || (!w.Admin && w.Owner == p.User)
+}
+
+// Handle queues the event for every matching subscription without blocking
+// the publisher.
+func (s *Webhooks) Handle(e models.Event) {
+	webhooks, err := s.repo.List()
+	if err != nil {
+		log.Printf("could not list webhooks: %v", err)
+		return
+	}
+	for _, w := range webhooks {
+		if !w.Matches(e) {
+			continue
+		}
+		select {
+		case s.queue <- webhookJob{webhook: w, event: e}:
+		default:
+			webhookDropped.Inc()
+			log.Printf("webhook queue is full, dropping %s event %d for webhook %d", e.Type, e.ID, w.ID)
+		}
+	}
+}
+
+func (s *Webhooks) Start() {
+	log.Println("webhook dispatcher started")
+	for {
+		select {
+		case job := <-s.queue:
+			s.wg.Add(1)
+			go func() {
+				defer s.wg.Done()
+				s.deliver(job)
+			}()
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+func (s *Webhooks) Stop() error {
+	close(s.stop)
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		log.Println("webhook dispatcher stopped")
+		return nil
+	case <-time.After(2 * time.Second):
+		return fmt.Errorf("webhook deliveries still in flight after shutdown timeout")
+	}
+}
+
+func (s *Webhooks) deliver(job webhookJob) {
+	deliveryID, err := randomHex(12)
+	if err != nil {
+		log.Printf("could not deliver webhook %d: %v", job.webhook.ID, err)
+		return
+	}
+	body, err := json.Marshal(job.event)
+	if err != nil {
+		log.Printf("could not marshal %s event: %v", job.event.Type, err)
+		return
+	}
+	for attempt := 1; ; attempt++ {
+		d := s.post(job, deliveryID, body)
+		d.Attempt = attempt
+		if err := s.repo.LogDelivery(d); err != nil {
+			log.Printf("could not log webhook delivery: %v", err)
+		}
+		if d.Succeeded() {
+			webhookDeliveries.With("success").Inc()
+			return
+		}
+		webhookDeliveries.With("failure").Inc()
+		if s.policy.Exhausted(attempt) {
+			log.Printf("webhook %d gave up on %s event %d after %d attempt(s)", job.webhook.ID, job.event.Type, job.event.ID, attempt)
+			return
+		}
+		select {
+		case <-time.After(s.policy.Delay(attempt, mrand.Float64)):
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+func (s *Webhooks) post(job webhookJob, deliveryID string, body []byte) models.WebhookDelivery {
+	d := models.WebhookDelivery{
+		ID:        deliveryID,
+		WebhookID: job.webhook.ID,
+		EventID:   job.event.ID,
+		Event:     job.event.Type,
+		At:        time.Now(),
+	}
+	req, err := http.NewRequest(http.MethodPost, job.webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		d.Error = err.Error()
+		return d
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "app-pointment-webhooks")
+	req.Header.Set(WebhookEventHeader, job.event.Type)
+	req.Header.Set(WebhookDeliveryHeader, deliveryID)
+	req.Header.Set(WebhookSignatureHeader, Sign(job.webhook.Secret, body))
+	res, err := s.client.Do(req)
+	d.Duration = time.Since(d.At)
+	if err != nil {
+		d.Error = err.Error()
+		return d
+	}
+	defer res.Body.Close()
+	d.StatusCode = res.StatusCode
+	if !d.Succeeded() {
+		d.Error = fmt.Sprintf("unexpected status code: %d", res.StatusCode)
+	}
+	return d
+}
+
+// Sign returns the signature header value receivers use to verify that a
+// payload was sent by this server.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func randomHex(n int) (string, error) {
+	bs := make([]byte, n)
+	if _, err := rand.Read(bs); err != nil {
+		return "", models.WrapError("could not generate random bytes", err)
+	}
+	return hex.EncodeToString(bs), nil
+}
@@ -0,0 +1,221 @@
+package services
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"app-pointment/server/models"
+)
+
+type memoryWebhooks struct {
+	mu         sync.Mutex
+	webhooks   []models.Webhook
+	deliveries []models.WebhookDelivery
+}
+
+func (m *memoryWebhooks) Create(w models.Webhook) (models.Webhook, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	w.ID = len(m.webhooks) + 1
+	m.webhooks = append(m.webhooks, w)
+	return w, nil
+}
+
+func (m *memoryWebhooks) List() ([]models.Webhook, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]models.Webhook(nil), m.webhooks...), nil
+}
+
+func (m *memoryWebhooks) Get(id int) (models.Webhook, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, w := range m.webhooks {
+		if w.ID == id {
+			return w, nil
+		}
+	}
+	return models.Webhook{}, models.NotFoundError{}
+}
+
+func (m *memoryWebhooks) Delete(id int) error {
+	return nil
+}
+
+func (m *memoryWebhooks) LogDelivery(d models.WebhookDelivery) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.deliveries = append(m.deliveries, d)
+	return nil
+}
+
+func (m *memoryWebhooks) Deliveries(id int) ([]models.WebhookDelivery, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var res []models.WebhookDelivery
+	for _, d := range m.deliveries {
+		if d.WebhookID == id {
+			res = append(res, d)
+		}
+	}
+	return res, nil
+}
+
+type receivedWebhook struct {
+	header http.Header
+	body   []byte
+}
+
+// receiver answers webhook deliveries with the given status codes in order,
+// repeating the last one.
+func receiver(t *testing.T, codes ...int) (*httptest.Server, func() []receivedWebhook) {
+	var mu sync.Mutex
+	var received []receivedWebhook
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("could not read webhook body: %v", err)
+		}
+		mu.Lock()
+		received = append(received, receivedWebhook{header: r.Header, body: body})
+		code := codes[len(codes)-1]
+		if len(received) <= len(codes) {
+			code = codes[len(received)-1]
+		}
+		mu.Unlock()
+		w.WriteHeader(code)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, func() []receivedWebhook {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]receivedWebhook(nil), received...)
+	}
+}
+
+func newTestWebhooks(t *testing.T, url string, policy models.RetryPolicy) (*Webhooks, *memoryWebhooks, models.Webhook) {
+	repo := &memoryWebhooks{}
+	s := NewWebhooks(repo, policy)
+	w, err := s.Create(WebhookCreateBody{
+		Principal: models.Principal{User: "alice"},
+		URL:       url,
+		Secret:    "s3cr3t",
+	})
+	if err != nil {
+		t.Fatalf("could not create webhook: %v", err)
+	}
+	return s, repo, w
+}
+
+func testEvent() models.Event {
+	return models.Event{
+		ID:       7,
+		Type:     models.EventReminderFired,
+		Reminder: models.Reminder{ID: 3, Owner: "alice", Title: "standup"},
+		At:       time.Now(),
+	}
+}
+
+func TestWebhookDeliverySignature(t *testing.T) {
+	srv, received := receiver(t, http.StatusNoContent)
+	s, repo, w := newTestWebhooks(t, srv.URL, models.RetryPolicy{MaxAttempts: 3})
+
+	s.deliver(webhookJob{webhook: w, event: testEvent()})
+
+	got := received()
+	if len(got) != 1 {
+		t.Fatalf("got %d requests, want 1", len(got))
+	}
+	if want := Sign("s3cr3t", got[0].body); got[0].header.Get(WebhookSignatureHeader) != want {
+		t.Errorf("signature = %q, want %q", got[0].header.Get(WebhookSignatureHeader), want)
+	}
+	if Sign("other", got[0].body) == got[0].header.Get(WebhookSignatureHeader) {
+		t.Error("signature does not depend on the secret")
+	}
+	if got[0].header.Get(WebhookEventHeader) != models.EventReminderFired {
+		t.Errorf("event header = %q", got[0].header.Get(WebhookEventHeader))
+	}
+
+	log, _ := repo.Deliveries(w.ID)
+	if len(log) != 1 || !log[0].Succeeded() || log[0].Attempt != 1 || log[0].StatusCode != http.StatusNoContent {
+		t.Fatalf("unexpected delivery log: %+v", log)
+	}
+	if log[0].ID != got[0].header.Get(WebhookDeliveryHeader) {
+		t.Errorf("logged delivery id %q, sent %q", log[0].ID, got[0].header.Get(WebhookDeliveryHeader))
+	}
+	if log[0].EventID != 7 || log[0].Event != models.EventReminderFired {
+		t.Errorf("logged event %d %q", log[0].EventID, log[0].Event)
+	}
+}
+
+func TestWebhookDeliveryRetries(t *testing.T) {
+	policy := models.RetryPolicy{MaxAttempts: 3, InitialDelay: time.Millisecond}
+	tests := []struct {
+		name      string
+		codes     []int
+		attempts  int
+		succeeded bool
+	}{
+		{"gives up when exhausted", []int{http.StatusServiceUnavailable}, 3, false},
+		{"stops after recovering", []int{http.StatusInternalServerError, http.StatusBadGateway, http.StatusOK}, 3, true},
+		{"first success", []int{http.StatusOK}, 1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, received := receiver(t, tt.codes...)
+			s, repo, w := newTestWebhooks(t, srv.URL, policy)
+
+			s.deliver(webhookJob{webhook: w, event: testEvent()})
+
+			got := received()
+			if len(got) != tt.attempts {
+				t.Fatalf("got %d requests, want %d", len(got), tt.attempts)
+			}
+			log, _ := repo.Deliveries(w.ID)
+			if len(log) != tt.attempts {
+				t.Fatalf("got %d logged attempts, want %d", len(log), tt.attempts)
+			}
+			for i, d := range log {
+				if d.Attempt != i+1 {
+					t.Errorf("attempt %d logged as %d", i+1, d.Attempt)
+				}
+				if d.ID != log[0].ID || d.ID != got[i].header.Get(WebhookDeliveryHeader) {
+					t.Errorf("attempt %d has delivery id %q, want %q", i+1, d.ID, log[0].ID)
+				}
+				if string(got[i].body) != string(got[0].body) {
+					t.Errorf("attempt %d sent a different body", i+1)
+				}
+				last := i == len(log)-1
+				if d.Succeeded() != (last && tt.succeeded) {
+					t.Errorf("attempt %d succeeded = %v", i+1, d.Succeeded())
+				}
+				if !d.Succeeded() && d.Error == "" {
+					t.Errorf("failed attempt %d has no error", i+1)
+				}
+			}
+		})
+	}
+}
+
+func TestWebhookHandleQueuesMatchingSubscriptions(t *testing.T) {
+	repo := &memoryWebhooks{}
+	s := NewWebhooks(repo, models.RetryPolicy{})
+	repo.Create(models.Webhook{Owner: "alice", URL: "http://a"})
+	repo.Create(models.Webhook{Owner: "bob", URL: "http://b"})
+	repo.Create(models.Webhook{Owner: "root", Admin: true, URL: "http://c", Events: []string{models.EventReminderFired}})
+	repo.Create(models.Webhook{Owner: "root", Admin: true, URL: "http://d", Events: []string{models.EventReminderCreated}})
+
+	s.Handle(testEvent())
+
+	var urls []string
+	for len(s.queue) > 0 {
+		urls = append(urls, (<-s.queue).webhook.URL)
+	}
+	if len(urls) != 2 || urls[0] != "http://a" || urls[1] != "http://c" {
+		t.Errorf("queued %v, want [http://a http://c]", urls)
+	}
+}
@@ -13,6 +13,10 @@ const (
 	dataValidationErrType   = "data_validation_error"
 	formatValidationErrType = "format_validation_error"
 	invalidJSONErrType      = "invalid_json_error"
+	timeoutErrType          = "timeout_error"
+	unauthorizedErrType     = "unauthorized_error"
+	forbiddenErrType        = "forbidden_error"
+	conflictErrType         = "conflict_error"
 	serviceErrType          = "service_error"
 )
 
@@ -54,6 +58,18 @@ func toHTTPError(err error) models.HTTPError {
 	case models.InvalidJSONError:
 		resErr.Code = http.StatusBadRequest
 		resErr.Type = invalidJSONErrType
+	case models.UnauthorizedError:
+		resErr.Code = http.StatusUnauthorized
+		resErr.Type = unauthorizedErrType
+	case models.ForbiddenError:
+		resErr.Code = http.StatusForbidden
+		resErr.Type = forbiddenErrType
+	case models.ConflictError:
+		resErr.Code = http.StatusConflict
+		resErr.Type = conflictErrType
+	case models.TimeoutError:
+		resErr.Code = http.StatusServiceUnavailable
+		resErr.Type = timeoutErrType
 	default:
 		resErr.Code = http.StatusInternalServerError
 		resErr.Type = serviceErrType
@@ -62,3 +78,9 @@ func toHTTPError(err error) models.HTTPError {
 	log.Printf("error: %v", err)
 	return resErr
 }
+
+// ErrorBody is the body SendError would send for the error, used to report
+// errors of single items of a larger response.
+func ErrorBody(err error) models.HTTPError {
+	return toHTTPError(err)
+}